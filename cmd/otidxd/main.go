@@ -7,6 +7,7 @@ import (
 	"os"
 	"syscall"
 
+	"otterindex/internal/core/config"
 	"otterindex/internal/otidxd"
 )
 
@@ -14,6 +15,11 @@ func main() {
 	listen := flag.String("listen", "127.0.0.1:7337", "listen address (tcp)")
 	flag.Parse()
 
+	if err := config.LoadAndApply(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	s := otidxd.NewServer(otidxd.Options{Listen: *listen})
 	if err := s.Run(); err != nil {
 		if errors.Is(err, syscall.EADDRINUSE) {
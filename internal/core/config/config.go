@@ -0,0 +1,71 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"otterindex/internal/core/lang"
+)
+
+// Config is the user configuration, read from Path().
+type Config struct {
+	// Types defines file types for -t/--type as name -> globs. A name that
+	// already exists (e.g. "go") gets the globs added.
+	Types map[string][]string `json:"types,omitempty"`
+}
+
+// Path returns $OTIDX_CONFIG, or otidx/config.json under the user config
+// directory.
+func Path() string {
+	if p := strings.TrimSpace(os.Getenv("OTIDX_CONFIG")); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "otidx", "config.json")
+}
+
+// Load reads the config at path; a missing file is an empty config.
+func Load(path string) (Config, error) {
+	var c Config
+	if strings.TrimSpace(path) == "" {
+		return c, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("config %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Apply registers the config's types.
+func (c Config) Apply() error {
+	for name, globs := range c.Types {
+		if err := lang.AddType(name, globs...); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadAndApply loads the user config and applies it.
+func LoadAndApply() error {
+	c, err := Load(Path())
+	if err != nil {
+		return err
+	}
+	return c.Apply()
+}
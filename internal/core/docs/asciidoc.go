@@ -0,0 +1,95 @@
+package docs
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	adocHeadingRe = regexp.MustCompile(`^(={1,6})[ \t]+(\S.*?)[ \t]*$`)
+	adocAttrRe    = regexp.MustCompile(`^:(!?[\w][\w-]*!?):(?:[ \t]+(.*))?$`)
+	adocSourceRe  = regexp.MustCompile(`^\[source(?:[ \t]*,[ \t]*([^,\]]*))?.*\][ \t]*$`)
+	adocDelimRe   = regexp.MustCompile(`^(-{4,}|\.{4,}|/{4,}|` + "`{3,}" + `)(.*)$`)
+)
+
+// parseAsciiDoc finds section titles ("== Title"; the document title is
+// level 1), the attribute entries of the document header and source blocks:
+// "[source,lang]" listings and ``` fences.
+func parseAsciiDoc(d *doc) {
+	i := adocHeader(d)
+
+	source, sourceLine := "", -1
+	for ; i < len(d.lines); i++ {
+		line := d.lines[i]
+		if m := adocSourceRe.FindStringSubmatch(line); m != nil {
+			source, sourceLine = strings.TrimSpace(m[1]), i
+			continue
+		}
+		if m := adocDelimRe.FindStringSubmatch(line); m != nil && (m[1][0] == '`' || m[2] == "") {
+			delim := m[1]
+			end := len(d.lines) - 1
+			for j := i + 1; j < len(d.lines); j++ {
+				if strings.TrimRight(d.lines[j], " \t") == delim {
+					end = j
+					break
+				}
+			}
+			switch {
+			case delim[0] == '`':
+				d.blocks = append(d.blocks, codeBlock{sl: i + 1, el: end + 1, lang: codeLang(m[2]), sig: line})
+			case delim[0] == '-' && sourceLine == i-1:
+				d.blocks = append(d.blocks, codeBlock{sl: sourceLine + 1, el: end + 1, lang: codeLang(source), sig: d.lines[sourceLine]})
+			}
+			i, sourceLine = end, -1
+			continue
+		}
+		if sourceLine == i-1 && !blank(line) {
+			// [source] on a paragraph: the block ends at the next blank line.
+			end := i
+			for end+1 < len(d.lines) && !blank(d.lines[end+1]) {
+				end++
+			}
+			d.blocks = append(d.blocks, codeBlock{sl: sourceLine + 1, el: end + 1, lang: codeLang(source), sig: d.lines[sourceLine]})
+			i, sourceLine = end, -1
+			continue
+		}
+		if m := adocHeadingRe.FindStringSubmatch(line); m != nil {
+			d.headings = append(d.headings, heading{level: len(m[1]), sl: i + 1, el: i + 1, text: m[2]})
+		}
+	}
+}
+
+// adocHeader reads the document header: the "= Title" line and the lines
+// that follow it up to the first blank line, collecting attribute entries
+// (":toc: left"). Attribute entries above the title count too. It returns
+// the index of the first line it did not consume as header; the title
+// itself is left to the section scan.
+func adocHeader(d *doc) int {
+	i := 0
+	for i < len(d.lines) && (blank(d.lines[i]) || strings.HasPrefix(d.lines[i], "//") || adocAttrRe.MatchString(d.lines[i])) {
+		i++
+	}
+	title := i
+	if title >= len(d.lines) || !strings.HasPrefix(d.lines[title], "= ") {
+		title = -1
+	}
+	end := i
+	if title >= 0 {
+		for end = title + 1; end < len(d.lines) && !blank(d.lines[end]); end++ {
+		}
+	}
+	for j := 0; j < end; j++ {
+		if m := adocAttrRe.FindStringSubmatch(d.lines[j]); m != nil && j != title {
+			if len(d.front) == 0 {
+				d.frontSL = j + 1
+			}
+			d.front = append(d.front, field{line: j + 1, key: m[1], value: strings.TrimSpace(m[2])})
+			d.frontEL = j + 1
+		}
+	}
+	if title >= 0 {
+		d.headings = append(d.headings, heading{level: 1, sl: title + 1, el: title + 1, text: strings.TrimSpace(d.lines[title][2:])})
+		return end
+	}
+	return i
+}
@@ -0,0 +1,198 @@
+// Package docs extracts the structure of prose documents (Markdown,
+// reStructuredText and AsciiDoc) in pure Go, so it works without the
+// tree-sitter build: headings become nested "section" symbols, code blocks
+// "code" symbols tagged with their language, and front-matter fields "meta"
+// symbols.
+package docs
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"otterindex/internal/core/lang"
+	"otterindex/internal/index/store"
+)
+
+// Symbol kinds emitted by Extract.
+const (
+	KindSection     = "section"
+	KindCode        = "code"
+	KindFrontMatter = "frontmatter"
+	KindMeta        = "meta"
+)
+
+// Supported reports whether path is a document Extract understands.
+func Supported(path string) bool {
+	switch lang.FromPath(path) {
+	case "markdown", "rst", "asciidoc":
+		return true
+	}
+	return false
+}
+
+// Extract returns the symbols of a document, or nil when path is not one.
+// Sections span from their heading to the next heading of the same or a
+// higher level; their Container is the parent section and their Signature
+// the heading path ("Install > Linux").
+func Extract(path string, src []byte) []store.SymbolInput {
+	d := &doc{lang: lang.FromPath(path), lines: splitLines(string(src))}
+	switch d.lang {
+	case "markdown":
+		parseMarkdown(d)
+	case "rst":
+		parseRST(d)
+	case "asciidoc":
+		parseAsciiDoc(d)
+	default:
+		return nil
+	}
+	return d.symbols()
+}
+
+type heading struct {
+	level int
+	sl    int
+	el    int
+	text  string
+}
+
+type codeBlock struct {
+	sl   int
+	el   int
+	lang string
+	sig  string
+}
+
+type field struct {
+	line  int
+	key   string
+	value string
+}
+
+type doc struct {
+	lang     string
+	lines    []string
+	headings []heading
+	blocks   []codeBlock
+	// front is the front matter (or header attributes) and its line range.
+	front            []field
+	frontSL, frontEL int
+}
+
+func (d *doc) symbols() []store.SymbolInput {
+	var out []store.SymbolInput
+	if len(d.front) > 0 {
+		out = append(out, d.symbol(KindFrontMatter, "frontmatter", d.frontSL, d.frontEL, "", d.lang, ""))
+		for _, f := range d.front {
+			sig := f.key + ":"
+			if f.value != "" {
+				sig += " " + f.value
+			}
+			out = append(out, d.symbol(KindMeta, f.key, f.line, f.line, "frontmatter", d.lang, sig))
+		}
+	}
+
+	type section struct {
+		heading
+		end  int
+		path string
+	}
+	secs := make([]section, 0, len(d.headings))
+	var stack []int
+	for i, h := range d.headings {
+		end := len(d.lines)
+		for _, next := range d.headings[i+1:] {
+			if next.level <= h.level {
+				end = next.sl - 1
+				break
+			}
+		}
+		for end > h.el && strings.TrimSpace(d.lines[end-1]) == "" {
+			end--
+		}
+		for len(stack) > 0 && secs[stack[len(stack)-1]].level >= h.level {
+			stack = stack[:len(stack)-1]
+		}
+		container, path := "", h.text
+		if len(stack) > 0 {
+			p := secs[stack[len(stack)-1]]
+			container, path = p.text, p.path+" > "+h.text
+		}
+		secs = append(secs, section{heading: h, end: end, path: path})
+		stack = append(stack, len(secs)-1)
+		out = append(out, d.symbol(KindSection, h.text, h.sl, end, container, d.lang, path))
+	}
+
+	for _, b := range d.blocks {
+		container := ""
+		for _, s := range secs {
+			if s.sl <= b.sl && b.el <= s.end {
+				container = s.text
+			}
+		}
+		name, l := b.lang, b.lang
+		if name == "" {
+			name, l = "code", d.lang
+		}
+		out = append(out, d.symbol(KindCode, name, b.sl, b.el, container, l, b.sig))
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].SL != out[j].SL {
+			return out[i].SL < out[j].SL
+		}
+		return out[i].EL > out[j].EL
+	})
+	return out
+}
+
+func (d *doc) symbol(kind string, name string, sl int, el int, container string, l string, sig string) store.SymbolInput {
+	return store.SymbolInput{
+		Kind:      kind,
+		Name:      name,
+		SL:        sl,
+		SC:        1,
+		EL:        el,
+		EC:        len(d.lines[el-1]) + 1,
+		Container: container,
+		Lang:      l,
+		Signature: strings.TrimSpace(sig),
+	}
+}
+
+// codeLang normalizes a code block's language tag ("py", "{.python}") to a
+// language name where it is a known one.
+func codeLang(tag string) string {
+	tag = strings.ToLower(strings.Trim(strings.TrimSpace(tag), "{}."))
+	if l, ok := lang.Lookup(tag); ok {
+		return l.Name
+	}
+	return tag
+}
+
+// unquote strips one pair of matching quotes from a front-matter value.
+func unquote(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+func blank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func width(s string) int {
+	return utf8.RuneCountInString(strings.TrimSpace(s))
+}
+
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,129 @@
+package docs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// describe renders symbols as "SL-EL kind name [container] (lang) sig".
+func describe(path string, src string) string {
+	var out []string
+	for _, s := range Extract(path, []byte(src)) {
+		out = append(out, fmt.Sprintf("%d-%d %s %s [%s] (%s) %s", s.SL, s.EL, s.Kind, s.Name, s.Container, s.Lang, s.Signature))
+	}
+	return strings.Join(out, "\n")
+}
+
+func check(t *testing.T, path string, src string, want ...string) {
+	t.Helper()
+	if got := describe(path, src); got != strings.Join(want, "\n") {
+		t.Fatalf("%s:\n%s\nwant:\n%s", path, got, strings.Join(want, "\n"))
+	}
+}
+
+func TestExtract_Markdown(t *testing.T) {
+	src := strings.Join([]string{
+		"---",              // 1
+		"title: \"Guide\"", // 2
+		"tags:",            // 3
+		"  - a",            // 4
+		"---",              // 5
+		"# Guide",          // 6
+		"",                 // 7
+		"Intro.",           // 8
+		"",                 // 9
+		"## Install ##",    // 10
+		"",                 // 11
+		"```py",            // 12
+		"# not a heading",  // 13
+		"```",              // 14
+		"",                 // 15
+		"Linux",            // 16
+		"-----",            // 17
+		"",                 // 18
+		"- item",           // 19
+		"---",              // 20
+		"",                 // 21
+		"# Other",          // 22
+		"~~~",              // 23
+		"plain",            // 24
+	}, "\n") + "\n"
+	check(t, "docs/guide.md", src,
+		"1-5 frontmatter frontmatter [] (markdown) ",
+		"2-2 meta title [frontmatter] (markdown) title: Guide",
+		"3-3 meta tags [frontmatter] (markdown) tags:",
+		"6-20 section Guide [] (markdown) Guide",
+		"10-14 section Install [Guide] (markdown) Guide > Install",
+		"12-14 code python [Install] (python) ```py",
+		"16-20 section Linux [Guide] (markdown) Guide > Linux",
+		"22-24 section Other [] (markdown) Other",
+		"23-24 code code [Other] (markdown) ~~~",
+	)
+}
+
+func TestExtract_RST(t *testing.T) {
+	src := strings.Join([]string{
+		":author: Ann",         // 1
+		"",                     // 2
+		"=====",                // 3
+		"Title",                // 4
+		"=====",                // 5
+		"",                     // 6
+		"Usage",                // 7
+		"-----",                // 8
+		"",                     // 9
+		".. code-block:: bash", // 10
+		"",                     // 11
+		"   otidx q foo",       // 12
+		"",                     // 13
+		"Text.",                // 14
+		"",                     // 15
+		"Notes",                // 16
+		"-----",                // 17
+		"",                     // 18
+		"Details",              // 19
+		"~~~~~~~",              // 20
+	}, "\n")
+	check(t, "README.rst", src,
+		"1-1 frontmatter frontmatter [] (rst) ",
+		"1-1 meta author [frontmatter] (rst) author: Ann",
+		"3-20 section Title [] (rst) Title",
+		"7-14 section Usage [Title] (rst) Title > Usage",
+		"10-12 code bash [Usage] (bash) .. code-block:: bash",
+		"16-20 section Notes [Title] (rst) Title > Notes",
+		"19-20 section Details [Notes] (rst) Title > Notes > Details",
+	)
+}
+
+func TestExtract_AsciiDoc(t *testing.T) {
+	src := strings.Join([]string{
+		"= Manual",         // 1
+		"Ann Author",       // 2
+		":toc: left",       // 3
+		"",                 // 4
+		"== Setup",         // 5
+		"",                 // 6
+		"[source,go]",      // 7
+		"----",             // 8
+		"== not a heading", // 9
+		"----",             // 10
+		"",                 // 11
+		"=== Deep",         // 12
+		"",                 // 13
+		"== Usage",         // 14
+	}, "\n")
+	check(t, "manual.adoc", src,
+		"1-14 section Manual [] (asciidoc) Manual",
+		"3-3 frontmatter frontmatter [] (asciidoc) ",
+		"3-3 meta toc [frontmatter] (asciidoc) toc: left",
+		"5-12 section Setup [Manual] (asciidoc) Manual > Setup",
+		"7-10 code go [Setup] (go) [source,go]",
+		"12-12 section Deep [Setup] (asciidoc) Manual > Setup > Deep",
+		"14-14 section Usage [Manual] (asciidoc) Manual > Usage",
+	)
+
+	if got := Extract("main.go", []byte("# x\n")); got != nil {
+		t.Fatalf("expected no symbols for code, got %+v", got)
+	}
+}
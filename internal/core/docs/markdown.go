@@ -0,0 +1,100 @@
+package docs
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdATXRe    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdSetextRe = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdFenceRe  = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*(.*)$")
+	mdListRe   = regexp.MustCompile(`^ {0,3}(?:[-*+]|\d+[.)])(?:[ \t]|$)`)
+	// mdFieldRe is a top-level YAML or TOML key.
+	mdFieldRe = regexp.MustCompile(`^([A-Za-z_][\w.-]*)[ \t]*[:=][ \t]*(.*)$`)
+)
+
+func parseMarkdown(d *doc) {
+	i := markdownFrontMatter(d)
+
+	// para is the first line of the paragraph ending on the previous line,
+	// or 0: the text of a setext heading.
+	para := 0
+	for ; i < len(d.lines); i++ {
+		line := d.lines[i]
+		if m := mdFenceRe.FindStringSubmatch(line); m != nil && !(m[1][0] == '`' && strings.Contains(m[2], "`")) {
+			i = markdownFence(d, i, m[1], m[2])
+			para = 0
+			continue
+		}
+		if m := mdATXRe.FindStringSubmatch(line); m != nil {
+			d.headings = append(d.headings, heading{level: len(m[1]), sl: i + 1, el: i + 1, text: strings.TrimSpace(m[2])})
+			para = 0
+			continue
+		}
+		if m := mdSetextRe.FindStringSubmatch(line); m != nil && para > 0 {
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			var text []string
+			for _, l := range d.lines[para-1 : i] {
+				text = append(text, strings.TrimSpace(l))
+			}
+			d.headings = append(d.headings, heading{level: level, sl: para, el: i + 1, text: strings.Join(text, " ")})
+			para = 0
+			continue
+		}
+		switch {
+		case blank(line) || mdListRe.MatchString(line) || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"):
+			para = 0
+		case para == 0:
+			para = i + 1
+		}
+	}
+}
+
+// markdownFrontMatter reads a YAML (---) or TOML (+++) block at the top of
+// the file and returns the index of the first line after it.
+func markdownFrontMatter(d *doc) int {
+	if len(d.lines) == 0 {
+		return 0
+	}
+	open := strings.TrimSpace(d.lines[0])
+	if open != "---" && open != "+++" {
+		return 0
+	}
+	for i := 1; i < len(d.lines); i++ {
+		l := strings.TrimSpace(d.lines[i])
+		if l != open && !(open == "---" && l == "...") {
+			continue
+		}
+		for j := 1; j < i; j++ {
+			if m := mdFieldRe.FindStringSubmatch(d.lines[j]); m != nil {
+				d.front = append(d.front, field{line: j + 1, key: m[1], value: unquote(m[2])})
+			}
+		}
+		d.frontSL, d.frontEL = 1, i+1
+		return i + 1
+	}
+	return 0
+}
+
+// markdownFence records the code block opened on line i and returns the
+// index of its closing fence (or of the last line when it is never closed).
+func markdownFence(d *doc, i int, fence string, info string) int {
+	var tag string
+	if f := strings.Fields(info); len(f) > 0 {
+		tag = f[0]
+	}
+	end := len(d.lines) - 1
+	for j := i + 1; j < len(d.lines); j++ {
+		l := strings.TrimSpace(d.lines[j])
+		if len(l) >= len(fence) && strings.Trim(l, fence[:1]) == "" && !strings.HasPrefix(d.lines[j], "    ") {
+			end = j
+			break
+		}
+	}
+	d.blocks = append(d.blocks, codeBlock{sl: i + 1, el: end + 1, lang: codeLang(tag), sig: d.lines[i]})
+	return end
+}
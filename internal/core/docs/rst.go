@@ -0,0 +1,106 @@
+package docs
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	rstCodeRe  = regexp.MustCompile(`^([ \t]*)\.\.[ \t]+(?:code-block|code|sourcecode)::[ \t]*(\S*)`)
+	rstFieldRe = regexp.MustCompile(`^:([^:\s][^:]*):(?:[ \t]+(.*))?$`)
+)
+
+// rstAdornment is the punctuation that may underline (and overline) a
+// section title.
+const rstAdornment = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// parseRST finds section titles (levels follow the order in which the
+// adornment styles first appear, as in docutils), code-block directives and
+// a field list at the top of the file.
+func parseRST(d *doc) {
+	i := rstFields(d)
+
+	var styles []string
+	level := func(style string) int {
+		for n, s := range styles {
+			if s == style {
+				return n + 1
+			}
+		}
+		styles = append(styles, style)
+		return len(styles)
+	}
+
+	for ; i < len(d.lines); i++ {
+		line := d.lines[i]
+		if m := rstCodeRe.FindStringSubmatch(line); m != nil {
+			i = rstCode(d, i, len(m[1]), m[2])
+			continue
+		}
+		prevBlank := i == 0 || blank(d.lines[i-1])
+		// Overlined title: ===== / Title / =====
+		if c := adornment(line); c != 0 && prevBlank && i+2 < len(d.lines) && !blank(d.lines[i+1]) &&
+			adornment(d.lines[i+2]) == c && width(d.lines[i+2]) == width(line) && width(line) >= width(d.lines[i+1]) {
+			d.headings = append(d.headings, heading{level: level("o" + string(c)), sl: i + 1, el: i + 3, text: strings.TrimSpace(d.lines[i+1])})
+			i += 2
+			continue
+		}
+		// Underlined title: Title / =====
+		if !blank(line) && adornment(line) == 0 && prevBlank && line[0] != ' ' && line[0] != '\t' && i+1 < len(d.lines) {
+			if c := adornment(d.lines[i+1]); c != 0 && width(d.lines[i+1]) >= width(line) {
+				d.headings = append(d.headings, heading{level: level("u" + string(c)), sl: i + 1, el: i + 2, text: strings.TrimSpace(line)})
+				i++
+			}
+		}
+	}
+}
+
+// adornment returns the character a line of at least two repeated
+// punctuation characters is made of, or 0.
+func adornment(line string) byte {
+	l := strings.TrimRight(line, " \t")
+	if len(l) < 2 || !strings.ContainsRune(rstAdornment, rune(l[0])) || strings.Trim(l, l[:1]) != "" {
+		return 0
+	}
+	return l[0]
+}
+
+// rstFields reads the field list (":author: ...") that opens a document and
+// returns the index of the first line after it.
+func rstFields(d *doc) int {
+	i := 0
+	for i < len(d.lines) && blank(d.lines[i]) {
+		i++
+	}
+	start := i
+	for ; i < len(d.lines); i++ {
+		m := rstFieldRe.FindStringSubmatch(d.lines[i])
+		if m == nil {
+			break
+		}
+		d.front = append(d.front, field{line: i + 1, key: m[1], value: strings.TrimSpace(m[2])})
+	}
+	if len(d.front) > 0 {
+		d.frontSL, d.frontEL = start+1, i
+	}
+	return i
+}
+
+// rstCode records the code block of the directive on line i (its body is
+// the lines indented deeper than the directive) and returns the index of
+// its last line.
+func rstCode(d *doc, i int, indent int, tag string) int {
+	end := i
+	for j := i + 1; j < len(d.lines); j++ {
+		l := d.lines[j]
+		if blank(l) {
+			continue
+		}
+		if len(l)-len(strings.TrimLeft(l, " \t")) <= indent {
+			break
+		}
+		end = j
+	}
+	d.blocks = append(d.blocks, codeBlock{sl: i + 1, el: end + 1, lang: codeLang(tag), sig: d.lines[i]})
+	return end
+}
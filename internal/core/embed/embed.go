@@ -0,0 +1,103 @@
+// Package embed turns text into vectors for semantic search. Embedders are
+// looked up by name so an index remembers which one produced its vectors;
+// the built-in "hash" embedder is deterministic and runs offline.
+package embed
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Embedder maps text to a fixed-size vector. Vectors from the same embedder
+// are compared with Cosine.
+type Embedder interface {
+	// Name identifies the embedder and its settings; vectors with different
+	// names are never compared.
+	Name() string
+	Dim() int
+	Embed(text string) []float32
+}
+
+// Default is the name of the embedder used when none is given.
+const Default = "hash"
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Embedder{}
+)
+
+func init() {
+	Register(NewHash(hashDim))
+}
+
+// Register makes e available to Lookup under e.Name().
+func Register(e Embedder) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[e.Name()] = e
+}
+
+func Lookup(name string) (Embedder, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = Default
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	e, ok := registry[name]
+	return e, ok
+}
+
+// Names lists the registered embedders.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]string, 0, len(registry))
+	for name := range registry {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Cosine returns the cosine similarity of a and b, or 0 when their sizes
+// differ or either is zero.
+func Cosine(a []float32, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// Encode packs v as little-endian float32s for storage.
+func Encode(v []float32) []byte {
+	out := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(out[4*i:], math.Float32bits(f))
+	}
+	return out
+}
+
+func Decode(b []byte) ([]float32, error) {
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("invalid vector length %d", len(b))
+	}
+	out := make([]float32, len(b)/4)
+	for i := range out {
+		out[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return out, nil
+}
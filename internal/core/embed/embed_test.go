@@ -0,0 +1,48 @@
+package embed
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	got := Words("func retryFailedWrites(ctx) error { return do_retry(ctx, 3) }")
+	want := []string{"retry", "fail", "writ", "ctx", "error", "retry", "ctx"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Words = %q, want %q", got, want)
+	}
+}
+
+func TestHash_Similarity(t *testing.T) {
+	e, ok := Lookup("")
+	if !ok || e.Name() != Default {
+		t.Fatalf("default embedder missing")
+	}
+	q := e.Embed("where do we retry failed writes")
+	if !reflect.DeepEqual(q, e.Embed("where do we retry failed writes")) {
+		t.Fatalf("embedding is not deterministic")
+	}
+	if len(q) != e.Dim() {
+		t.Fatalf("len = %d, want %d", len(q), e.Dim())
+	}
+
+	related := e.Embed("func (w *Writer) writeWithRetry(b []byte) error {\n\tfor retries := 0; ; retries++ {\n\t\tif err := w.write(b); err == nil || retries == 3 {\n\t\t\treturn err\n\t\t}\n\t}\n}")
+	unrelated := e.Embed("func parseConfig(path string) (*Config, error) {\n\tb, err := os.ReadFile(path)\n\treturn decode(b), err\n}")
+	if rs, us := Cosine(q, related), Cosine(q, unrelated); rs <= us {
+		t.Fatalf("related %.3f <= unrelated %.3f", rs, us)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	v := []float32{0, 1.5, -2.25}
+	got, err := Decode(Encode(v))
+	if err != nil || !reflect.DeepEqual(got, v) {
+		t.Fatalf("Decode(Encode(%v)) = %v, %v", v, got, err)
+	}
+	if _, err := Decode([]byte{1, 2, 3}); err == nil {
+		t.Fatalf("expected error for short vector")
+	}
+	if c := Cosine(v, v); c < 0.999 {
+		t.Fatalf("Cosine(v, v) = %f", c)
+	}
+}
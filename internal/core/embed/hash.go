@@ -0,0 +1,151 @@
+package embed
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"otterindex/internal/core/fuzzy"
+)
+
+const hashDim = 256
+
+// Hash embeds text by feature hashing: identifiers are split into words
+// (camelCase, snake_case), lower-cased, stop words dropped and crudely
+// stemmed, then each word and its character trigrams are hashed into a
+// signed bucket. Words sharing a stem or most of their letters land close,
+// so "retry failed writes" finds retryWrite and onWriteFailure.
+type Hash struct {
+	dim int
+}
+
+func NewHash(dim int) *Hash {
+	if dim <= 0 {
+		dim = hashDim
+	}
+	return &Hash{dim: dim}
+}
+
+func (h *Hash) Name() string {
+	if h.dim == hashDim {
+		return Default
+	}
+	return "hash-" + strconv.Itoa(h.dim)
+}
+
+func (h *Hash) Dim() int { return h.dim }
+
+const trigramWeight = 0.25
+
+func (h *Hash) Embed(text string) []float32 {
+	counts := map[string]float64{}
+	for _, w := range Words(text) {
+		counts["w:"+w]++
+		padded := "<" + w + ">"
+		rs := []rune(padded)
+		for i := 0; i+3 <= len(rs); i++ {
+			counts["t:"+string(rs[i:i+3])]++
+		}
+	}
+
+	v := make([]float32, h.dim)
+	for f, c := range counts {
+		hf := fnv.New64a()
+		_, _ = hf.Write([]byte(f))
+		sum := hf.Sum64()
+		// Sub-linear term frequency keeps one repeated word from dominating.
+		w := float32(1 + math.Log(c))
+		if strings.HasPrefix(f, "t:") {
+			w *= trigramWeight
+		}
+		if sum>>63 == 1 {
+			w = -w
+		}
+		v[sum%uint64(h.dim)] += w
+	}
+
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	if norm > 0 {
+		n := float32(math.Sqrt(norm))
+		for i := range v {
+			v[i] /= n
+		}
+	}
+	return v
+}
+
+// Words returns the normalized words of text used as embedding features.
+func Words(text string) []string {
+	var out []string
+	for _, ident := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		for _, part := range fuzzy.Words(ident) {
+			w := strings.ToLower(part)
+			if len(w) < 2 || stopWords[w] || isNumber(w) {
+				continue
+			}
+			out = append(out, stem(w))
+		}
+	}
+	return out
+}
+
+// stem strips common English inflections so "writes", "writing" and "write"
+// agree. It is deliberately crude; trigrams absorb what it misses.
+func stem(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "ies"):
+		w = w[:len(w)-3] + "y"
+	case len(w) > 5 && strings.HasSuffix(w, "ing"):
+		w = undouble(w[:len(w)-3])
+	case len(w) > 4 && strings.HasSuffix(w, "ed"):
+		w = undouble(w[:len(w)-2])
+	case len(w) > 4 && (strings.HasSuffix(w, "sses") || strings.HasSuffix(w, "xes") || strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes")):
+		w = w[:len(w)-2]
+	case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		w = w[:len(w)-1]
+	}
+	if len(w) > 3 && strings.HasSuffix(w, "e") {
+		w = w[:len(w)-1]
+	}
+	return w
+}
+
+// undouble turns "stopp" (from "stopped") back into "stop".
+func undouble(w string) string {
+	n := len(w)
+	if n >= 3 && w[n-1] == w[n-2] && !strings.ContainsRune("aeioulsz", rune(w[n-1])) {
+		return w[:n-1]
+	}
+	return w
+}
+
+func isNumber(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		a an the of to in on at by for from with into about as and or not no is are
+		was were be been do does did we you it its this that these those where what
+		how when which who why there here then than can could will would should our
+		my your their his her us me them i
+		func function fn def return if else elif for while var let const nil null
+		none true false self new int string bool void public private protected
+		static final package import err`) {
+		stopWords[w] = true
+	}
+}
@@ -0,0 +1,162 @@
+// Package fuzzy matches short patterns against identifiers the way editors do
+// for "go to symbol": exact, prefix, camelCase abbreviation (NRC matches
+// NewRootCommand), substring and finally any in-order subsequence. MatchPath
+// scores file paths the way fzf does.
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Kind int
+
+const (
+	Exact Kind = iota
+	Prefix
+	CamelCase
+	Substring
+	Subsequence
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Exact:
+		return "exact"
+	case Prefix:
+		return "prefix"
+	case CamelCase:
+		return "camel"
+	case Substring:
+		return "substring"
+	case Subsequence:
+		return "fuzzy"
+	default:
+		return ""
+	}
+}
+
+type Result struct {
+	Kind Kind
+	// Score orders results across kinds; higher is better.
+	Score int
+}
+
+const kindWeight = 10000
+
+// Match reports how pattern matches s. Matching ignores case; an exact match
+// with the same case scores above one that differs only in case.
+func Match(pattern string, s string) (Result, bool) {
+	if pattern == "" || s == "" {
+		return Result{}, false
+	}
+	lp := strings.ToLower(pattern)
+	ls := strings.ToLower(s)
+
+	var kind Kind
+	switch {
+	case ls == lp:
+		kind = Exact
+	case strings.HasPrefix(ls, lp):
+		kind = Prefix
+	case matchWords(lp, lowerWords(s)):
+		kind = CamelCase
+	case strings.Contains(ls, lp):
+		kind = Substring
+	case isSubsequence(lp, ls):
+		kind = Subsequence
+	default:
+		return Result{}, false
+	}
+
+	score := (int(Subsequence)+1-int(kind))*kindWeight - utf8.RuneCountInString(s)
+	if kind == Exact && s == pattern {
+		score += kindWeight / 2
+	}
+	return Result{Kind: kind, Score: score}, true
+}
+
+// Words splits an identifier into its parts: "HTTPServerError2" gives
+// HTTP, Server, Error, 2 and "new_root_cmd" gives new, root, cmd.
+func Words(s string) []string {
+	var out []string
+	rs := []rune(s)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			out = append(out, string(rs[start:end]))
+		}
+		start = -1
+	}
+	for i, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := rs[i-1]
+		switch {
+		case unicode.IsDigit(r) != unicode.IsDigit(prev):
+			flush(i)
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush(i)
+		case unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(rs) && unicode.IsLower(rs[i+1]):
+			flush(i)
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	flush(len(rs))
+	return out
+}
+
+func lowerWords(s string) []string {
+	words := Words(s)
+	for i := range words {
+		words[i] = strings.ToLower(words[i])
+	}
+	return words
+}
+
+// matchWords reports whether p can be split into pieces that are each a
+// non-empty prefix of a word, in word order (words may be skipped).
+func matchWords(p string, words []string) bool {
+	if len(words) < 2 {
+		return false
+	}
+	var rec func(pi int, wi int) bool
+	rec = func(pi int, wi int) bool {
+		if pi == len(p) {
+			return true
+		}
+		for j := wi; j < len(words); j++ {
+			w := words[j]
+			n := 0
+			for n < len(w) && pi+n < len(p) && w[n] == p[pi+n] {
+				n++
+			}
+			for k := n; k >= 1; k-- {
+				if rec(pi+k, j+1) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return rec(0, 0)
+}
+
+func isSubsequence(p string, s string) bool {
+	i := 0
+	for j := 0; j < len(s) && i < len(p); j++ {
+		if s[j] == p[i] {
+			i++
+		}
+	}
+	return i == len(p)
+}
@@ -0,0 +1,108 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		s       string
+		kind    Kind
+		ok      bool
+	}{
+		{"NewRootCommand", "NewRootCommand", Exact, true},
+		{"newrootcommand", "NewRootCommand", Exact, true},
+		{"NewRoot", "NewRootCommand", Prefix, true},
+		{"NRC", "NewRootCommand", CamelCase, true},
+		{"NewRC", "NewRootCommand", CamelCase, true},
+		{"nerocom", "NewRootCommand", CamelCase, true},
+		{"RC", "NewRootCommand", CamelCase, true},
+		{"Command", "NewRootCommand", CamelCase, true},
+		{"nrc", "new_root_cmd", CamelCase, true},
+		{"ootCom", "NewRootCommand", Substring, true},
+		{"nwrt", "NewRootCommand", Subsequence, true},
+		{"xyz", "NewRootCommand", 0, false},
+		{"", "NewRootCommand", 0, false},
+	}
+	for _, tc := range cases {
+		got, ok := Match(tc.pattern, tc.s)
+		if ok != tc.ok || (ok && got.Kind != tc.kind) {
+			t.Fatalf("Match(%q, %q) = %v/%v, want %v/%v", tc.pattern, tc.s, got.Kind, ok, tc.kind, tc.ok)
+		}
+	}
+}
+
+func TestMatch_ScoreOrder(t *testing.T) {
+	exactCase, _ := Match("Open", "Open")
+	exactFold, _ := Match("Open", "open")
+	prefix, _ := Match("Open", "OpenFile")
+	camel, _ := Match("OF", "OpenFile")
+	sub, _ := Match("penFi", "OpenFile")
+	fuzzy, _ := Match("opfl", "OpenFile")
+
+	scores := []int{exactCase.Score, exactFold.Score, prefix.Score, camel.Score, sub.Score, fuzzy.Score}
+	for i := 1; i < len(scores); i++ {
+		if scores[i] >= scores[i-1] {
+			t.Fatalf("scores not strictly decreasing: %v", scores)
+		}
+	}
+
+	short, _ := Match("Open", "OpenFile")
+	long, _ := Match("Open", "OpenFileWithMode")
+	if short.Score <= long.Score {
+		t.Fatalf("shorter names should rank first: %d <= %d", short.Score, long.Score)
+	}
+}
+
+func TestWords(t *testing.T) {
+	cases := map[string][]string{
+		"NewRootCommand":   {"New", "Root", "Command"},
+		"HTTPServerError2": {"HTTP", "Server", "Error", "2"},
+		"new_root_cmd":     {"new", "root", "cmd"},
+		"parseURL":         {"parse", "URL"},
+		"x":                {"x"},
+	}
+	for in, want := range cases {
+		if got := Words(in); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Words(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	if _, ok := MatchPath("xyz", "internal/core/query/query.go"); ok {
+		t.Fatalf("unexpected match")
+	}
+	if _, ok := MatchPath("query test", "internal/core/query/query.go"); ok {
+		t.Fatalf("all terms must match")
+	}
+	if _, ok := MatchPath("query test", "internal/core/query/query_test.go"); !ok {
+		t.Fatalf("expected multi-term match")
+	}
+
+	// Better match first.
+	order := []struct {
+		pattern string
+		paths   []string
+	}{
+		{"query.go", []string{"internal/core/query/query.go", "internal/core/query/query_test.go", "internal/core/query/parse/parse.go"}},
+		{"rootgo", []string{"internal/otidxcli/root.go", "internal/otidxcli/root_test.go", "internal/otidxd/protocol.go"}},
+		{"icq", []string{"internal/core/query/rank.go", "internal/otidxcli/query_cmd.go"}},
+		{"render", []string{"render.go", "internal/otidxcli/render.go"}},
+	}
+	for _, o := range order {
+		prev := 1 << 30
+		for _, p := range o.paths {
+			score, ok := MatchPath(o.pattern, p)
+			if !ok {
+				t.Fatalf("MatchPath(%q, %q): no match", o.pattern, p)
+			}
+			if score >= prev {
+				t.Fatalf("MatchPath(%q): %q scored %d, not below the previous path (%d)", o.pattern, p, score, prev)
+			}
+			prev = score
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Path scoring follows fzf: every matched character scores, characters at
+// word boundaries (after '/', '_', '-', '.', a space or a lower→upper case
+// change) and runs of consecutive characters score extra, and gaps cost.
+const (
+	pathScoreMatch        = 16
+	pathGapStart          = -3
+	pathGapExtension      = -1
+	pathBonusBoundary     = 8
+	pathBonusSlash        = 10
+	pathBonusCamel        = 7
+	pathBonusConsecutive  = 4
+	pathBonusFirstCharMul = 2
+	// pathBonusBasename favours patterns that fit in the file name.
+	pathBonusBasename = 2 * pathScoreMatch
+)
+
+// MatchPath scores pattern against a slash-separated path, fzf style: the
+// pattern's characters must appear in order, ignoring case. Space-separated
+// terms must all match; their scores add up. Higher is better.
+func MatchPath(pattern string, p string) (int, bool) {
+	terms := strings.Fields(pattern)
+	if len(terms) == 0 || p == "" {
+		return 0, false
+	}
+	rs := []rune(p)
+	lower := make([]rune, len(rs))
+	for i, r := range rs {
+		lower[i] = unicode.ToLower(r)
+	}
+	base := strings.ToLower(p[strings.LastIndex(p, "/")+1:])
+
+	total := 0
+	for _, term := range terms {
+		lt := []rune(strings.ToLower(term))
+		score, ok := alignPath(lt, rs, lower)
+		if !ok {
+			return 0, false
+		}
+		if isSubsequence(string(lt), base) {
+			score += pathBonusBasename
+		}
+		total += score
+	}
+	return total - len(rs), true
+}
+
+// alignPath returns the best score of matching pattern (lowercase) against s
+// in order, choosing where each character matches.
+func alignPath(pattern []rune, s []rune, lower []rune) (int, bool) {
+	n, m := len(pattern), len(s)
+	if n == 0 || n > m {
+		return 0, false
+	}
+	const none = -1 << 30
+
+	// prev[j] is the best score with pattern[:i] matched and pattern[i-1]
+	// matched at s[j].
+	prev := make([]int, m)
+	cur := make([]int, m)
+	for j := range prev {
+		prev[j] = none
+	}
+	for i := 0; i < n; i++ {
+		// gapBest is the best prev[k] for k < j-1, less the gap to j.
+		gapBest := none
+		for j := 0; j < m; j++ {
+			if j >= 2 && prev[j-2] != none {
+				if c := prev[j-2] + pathGapStart; c > gapBest {
+					gapBest = c
+				}
+			}
+			cur[j] = none
+			if lower[j] == pattern[i] {
+				b := boundaryBonus(s, j)
+				switch {
+				case i == 0:
+					cur[j] = pathScoreMatch + b*pathBonusFirstCharMul
+				default:
+					best := none
+					if j >= 1 && prev[j-1] != none {
+						best = prev[j-1] + pathBonusConsecutive
+					}
+					if gapBest != none && gapBest > best {
+						best = gapBest
+					}
+					if best != none {
+						cur[j] = best + pathScoreMatch + b
+					}
+				}
+			}
+			if gapBest != none {
+				gapBest += pathGapExtension
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	best := none
+	for _, v := range prev {
+		if v > best {
+			best = v
+		}
+	}
+	return best, best != none
+}
+
+func boundaryBonus(s []rune, j int) int {
+	if j == 0 {
+		return pathBonusBoundary
+	}
+	prev, r := s[j-1], s[j]
+	switch {
+	case prev == '/':
+		return pathBonusSlash
+	case prev == '_' || prev == '-' || prev == '.' || prev == ' ':
+		return pathBonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(r):
+		return pathBonusCamel
+	case !unicode.IsDigit(prev) && unicode.IsDigit(r):
+		return pathBonusCamel
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+package indexer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+// Chunking strategies (Options.Chunking).
+const (
+	// ChunkingAuto aligns chunks to symbols where the file has some and
+	// falls back to line windows elsewhere.
+	ChunkingAuto = "auto"
+	// ChunkingLines cuts fixed windows of ChunkLines lines.
+	ChunkingLines = "lines"
+	// ChunkingSymbols only aligns chunks to symbols: files without symbols
+	// get no chunks, so their text is not searchable.
+	ChunkingSymbols = "symbols"
+)
+
+func resolveChunking(mode string) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "":
+		return ChunkingAuto, nil
+	case ChunkingAuto, ChunkingLines, ChunkingSymbols:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid chunking %q (expected: lines|symbols|auto)", mode)
+}
+
+// chunkFile cuts text into chunks with the given strategy.
+func chunkFile(text string, syms []store.SymbolInput, comms []store.CommentInput, mode string, chunkLines int, step int) []store.ChunkInput {
+	if mode != ChunkingLines && len(syms) > 0 {
+		if chunks := chunkBySymbols(text, syms, comms, chunkLines); len(chunks) > 0 {
+			return chunks
+		}
+	}
+	if mode == ChunkingSymbols {
+		return nil
+	}
+	return chunkByLines(text, chunkLines, step)
+}
+
+type symNode struct {
+	sym      store.SymbolInput
+	sl, el   int
+	children []*symNode
+}
+
+// chunkBySymbols emits one chunk per top-level symbol (with the doc comment
+// right above it), titled with its signature. A symbol longer than maxLines
+// is split at its nested symbols, or into windows when it has none. Lines
+// outside symbols are grouped into chunks of up to maxLines lines.
+func chunkBySymbols(text string, syms []store.SymbolInput, comms []store.CommentInput, maxLines int) []store.ChunkInput {
+	lines := splitLines(text)
+	if len(lines) == 0 {
+		return nil
+	}
+	c := symChunker{lines: lines, maxLines: maxLines, docStart: map[int]int{}}
+	for _, cm := range comms {
+		if sl, ok := c.docStart[cm.EL]; !ok || cm.SL < sl {
+			c.docStart[cm.EL] = cm.SL
+		}
+	}
+	c.region(1, len(lines), symbolTree(syms, len(lines)), "chunk", "")
+	return c.out
+}
+
+type symChunker struct {
+	lines    []string
+	maxLines int
+	// docStart maps the last line of a comment to its first line.
+	docStart map[int]int
+	out      []store.ChunkInput
+}
+
+// region chunks lines sl..el, which hold the symbols nodes; the lines between
+// them are titled like the enclosing symbol.
+func (c *symChunker) region(sl int, el int, nodes []*symNode, kind string, title string) {
+	cur := sl
+	for _, n := range nodes {
+		start := n.sl
+		for {
+			doc, ok := c.docStart[start-1]
+			if !ok || doc < cur {
+				break
+			}
+			start = doc
+		}
+		if start > cur {
+			c.windows(cur, start-1, kind, title)
+		}
+		c.symbol(n, start)
+		cur = n.el + 1
+	}
+	if cur <= el {
+		c.windows(cur, el, kind, title)
+	}
+}
+
+func (c *symChunker) symbol(n *symNode, start int) {
+	kind, title := n.sym.Kind, n.sym.Signature
+	if title == "" {
+		title = n.sym.Name
+	}
+	switch {
+	case n.el-start+1 <= c.maxLines:
+		c.add(start, n.el, kind, title)
+	case len(n.children) > 0:
+		c.region(start, n.el, n.children, kind, title)
+	default:
+		c.windows(start, n.el, kind, title)
+	}
+}
+
+// windows chunks lines sl..el without their blank edges, maxLines at a time.
+func (c *symChunker) windows(sl int, el int, kind string, title string) {
+	for sl <= el && strings.TrimSpace(c.lines[sl-1]) == "" {
+		sl++
+	}
+	for el >= sl && strings.TrimSpace(c.lines[el-1]) == "" {
+		el--
+	}
+	for s := sl; s <= el; s += c.maxLines {
+		c.add(s, min(s+c.maxLines-1, el), kind, title)
+	}
+}
+
+func (c *symChunker) add(sl int, el int, kind string, title string) {
+	c.out = append(c.out, store.ChunkInput{
+		SL:    sl,
+		EL:    el,
+		Kind:  kind,
+		Title: title,
+		Text:  strings.Join(c.lines[sl-1:el], "\n"),
+	})
+}
+
+// symbolTree nests symbols by their line ranges (clamped to n lines). A
+// symbol that overlaps a sibling without nesting in it is dropped.
+func symbolTree(syms []store.SymbolInput, n int) []*symNode {
+	nodes := make([]*symNode, 0, len(syms))
+	for _, s := range syms {
+		sl, el := max(s.SL, 1), min(s.EL, n)
+		if sl > el {
+			continue
+		}
+		nodes = append(nodes, &symNode{sym: s, sl: sl, el: el})
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].sl != nodes[j].sl {
+			return nodes[i].sl < nodes[j].sl
+		}
+		return nodes[i].el > nodes[j].el
+	})
+
+	var roots []*symNode
+	var stack []*symNode
+	for _, nd := range nodes {
+		for len(stack) > 0 && stack[len(stack)-1].el < nd.sl {
+			stack = stack[:len(stack)-1]
+		}
+		siblings := &roots
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			if nd.el > parent.el {
+				continue
+			}
+			siblings = &parent.children
+		}
+		*siblings = append(*siblings, nd)
+		stack = append(stack, nd)
+	}
+	return roots
+}
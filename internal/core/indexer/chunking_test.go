@@ -0,0 +1,68 @@
+package indexer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"otterindex/internal/index/store"
+)
+
+func TestChunkBySymbols(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("package a\n\nimport \"fmt\"\n\n")         // 1-4
+	b.WriteString("// Small says hi.\n")                     // 5
+	b.WriteString("func Small() {\n\tfmt.Println(1)\n}\n\n") // 6-8
+	b.WriteString("type Big struct{}\n\n")                   // 10
+	for i := 0; i < 3; i++ {                                 // methods at 12-15, 16-19, 20-23
+		fmt.Fprintf(&b, "func (Big) M%d() {\n\tx := %d\n\t_ = x\n}\n", i, i)
+	}
+	text := b.String()
+
+	syms := []store.SymbolInput{
+		{Name: "Small", Kind: "function", SL: 6, EL: 8, Signature: "func Small()"},
+		{Name: "Big", Kind: "class", SL: 10, EL: 23},
+		{Name: "M0", Kind: "method", SL: 12, EL: 15, Signature: "func (Big) M0()"},
+		{Name: "M1", Kind: "method", SL: 16, EL: 19, Signature: "func (Big) M1()"},
+		{Name: "M2", Kind: "method", SL: 20, EL: 23, Signature: "func (Big) M2()"},
+	}
+	comms := []store.CommentInput{{Kind: "line", Text: "// Small says hi.", SL: 5, EL: 5}}
+
+	got := chunkBySymbols(text, syms, comms, 8)
+	want := []string{
+		"1-3 chunk ",
+		"5-8 function func Small()",
+		"10-10 class Big",
+		"12-15 method func (Big) M0()",
+		"16-19 method func (Big) M1()",
+		"20-23 method func (Big) M2()",
+	}
+	var desc []string
+	for _, c := range got {
+		desc = append(desc, fmt.Sprintf("%d-%d %s %s", c.SL, c.EL, c.Kind, c.Title))
+	}
+	if strings.Join(desc, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("chunks:\n%s\nwant:\n%s", strings.Join(desc, "\n"), strings.Join(want, "\n"))
+	}
+	if !strings.HasPrefix(got[1].Text, "// Small says hi.\nfunc Small() {") {
+		t.Fatalf("doc comment not attached: %q", got[1].Text)
+	}
+
+	// Without symbols, or in lines mode, files are cut into windows as before.
+	if got := chunkFile(text, syms, comms, ChunkingLines, 8, 8); len(got) != 3 || got[0].Kind != "chunk" || got[1].SL != 9 {
+		t.Fatalf("lines mode: %+v", got)
+	}
+	if got := chunkFile(text, nil, nil, ChunkingAuto, 8, 8); len(got) != 3 || got[2].EL != 23 {
+		t.Fatalf("auto without symbols: %+v", got)
+	}
+	if got := chunkFile(text, nil, nil, ChunkingSymbols, 8, 8); len(got) != 0 {
+		t.Fatalf("symbols mode without symbols: %+v", got)
+	}
+	if got := chunkFile(text, syms, comms, ChunkingSymbols, 8, 8); len(got) != len(want) {
+		t.Fatalf("symbols mode: %+v", got)
+	}
+
+	if _, err := resolveChunking("bogus"); err == nil {
+		t.Fatalf("expected invalid chunking error")
+	}
+}
@@ -0,0 +1,101 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"otterindex/internal/index/backend"
+)
+
+type kvExplain map[string]any
+
+func (e kvExplain) KV(key string, value any) { e[key] = value }
+func (e kvExplain) Timer(string) func()      { return func() {} }
+
+func TestBuild_Incremental(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			write := func(name string, src string) {
+				_ = os.WriteFile(filepath.Join(root, name), []byte(src), 0o644)
+			}
+			write("a.go", "package a\n")
+			write("b.go", "package b\n")
+			write("c.go", "package c\n")
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, ".otidx", "index.db"))
+
+			build := func(full bool) kvExplain {
+				ex := kvExplain{}
+				if err := Build(root, dbPath, Options{Store: storeName, Full: full, Explain: ex}); err != nil {
+					t.Fatalf("build: %v", err)
+				}
+				return ex
+			}
+			check := func(ex kvExplain, added, changed, unchanged, removed int64) {
+				t.Helper()
+				got := [4]any{ex["files_added"], ex["files_changed"], ex["files_unchanged"], ex["files_removed"]}
+				if got != [4]any{added, changed, unchanged, removed} {
+					t.Fatalf("added/changed/unchanged/removed = %v, want %v", got, [4]int64{added, changed, unchanged, removed})
+				}
+			}
+
+			check(build(false), 3, 0, 0, 0)
+			check(build(false), 0, 0, 3, 0)
+
+			write("b.go", "package b // edited\n")
+			write("d.go", "package d\n")
+			_ = os.Remove(filepath.Join(root, "c.go"))
+			check(build(false), 1, 1, 1, 1)
+
+			st, err := backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			files, err := st.ListFilesMeta(root)
+			_ = st.Close()
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if _, ok := files["c.go"]; ok || len(files) != 3 {
+				t.Fatalf("files after prune: %v", files)
+			}
+
+			check(build(true), 0, 3, 0, 0)
+
+			// Same content with a new mtime: unchanged, and the mtime is
+			// recorded for the next build.
+			later := time.Now().Add(time.Hour)
+			_ = os.Chtimes(filepath.Join(root, "a.go"), later, later)
+			check(build(false), 0, 0, 3, 0)
+			st, err = backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			meta, _, err := st.GetFileMeta(root, "a.go")
+			if err == nil {
+				// An index written by another format is rebuilt.
+				err = st.SetSetting(root, settingFormat, "0/old")
+			}
+			_ = st.Close()
+			if err != nil {
+				t.Fatalf("meta: %v", err)
+			}
+			if meta.MTime != later.Unix() {
+				t.Fatalf("mtime not written back: %d, want %d", meta.MTime, later.Unix())
+			}
+			check(build(false), 0, 3, 0, 0)
+			check(build(false), 0, 0, 3, 0)
+
+			// So is one chunked another way.
+			ex := kvExplain{}
+			if err := Build(root, dbPath, Options{Store: storeName, Chunking: ChunkingLines, Explain: ex}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+			check(ex, 0, 3, 0, 0)
+			check(build(false), 0, 3, 0, 0)
+		})
+	}
+}
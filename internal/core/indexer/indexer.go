@@ -9,11 +9,14 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"otterindex/internal/core/docs"
+	"otterindex/internal/core/embed"
 	"otterindex/internal/core/explain"
 	"otterindex/internal/core/treesitter"
 	"otterindex/internal/core/walk"
@@ -21,6 +24,21 @@ import (
 	"otterindex/internal/index/store"
 )
 
+// formatVersion is bumped when Build writes different rows for the same
+// file (chunking, extractors), so that incremental builds redo every file.
+const formatVersion = 1
+
+const (
+	settingFormat   = "index_format"
+	settingChunking = "chunking"
+)
+
+// indexFormat identifies what wrote the rows: the format version and the
+// symbol extractor of this build.
+func indexFormat() string {
+	return fmt.Sprintf("%d/%s", formatVersion, treesitter.Extractor())
+}
+
 type Options struct {
 	Store        string
 	WorkspaceID  string
@@ -28,9 +46,24 @@ type Options struct {
 	ScanAll      bool
 	IncludeGlobs []string
 	ExcludeGlobs []string
+	// Types and TypesNot keep or drop files by type (lang.Types).
+	Types    []string
+	TypesNot []string
 
 	ChunkLines   int
 	ChunkOverlap int
+	// Chunking picks how files are cut into chunks: ChunkingAuto (default),
+	// ChunkingLines or ChunkingSymbols. ChunkOverlap applies to line windows.
+	Chunking string
+
+	// Embedder, when set, stores a vector per chunk and function-like symbol.
+	// When nil, Build keeps using the embedder of existing vectors, if any.
+	Embedder embed.Embedder
+
+	// Full makes Build re-read and rewrite every file. Otherwise it skips
+	// files whose size and mtime (or content hash) are unchanged since the
+	// last build. Either way, files that are gone are removed.
+	Full bool
 
 	Explain explain.Explain
 }
@@ -61,6 +94,12 @@ func Build(root string, dbPath string, opts Options) error {
 		ex.KV("scan_all", opts.ScanAll)
 		ex.KV("include_globs", opts.IncludeGlobs)
 		ex.KV("exclude_globs", opts.ExcludeGlobs)
+		if len(opts.Types) > 0 {
+			ex.KV("types", strings.Join(opts.Types, ","))
+		}
+		if len(opts.TypesNot) > 0 {
+			ex.KV("types_not", strings.Join(opts.TypesNot, ","))
+		}
 	}
 
 	rootAbs := root
@@ -94,10 +133,24 @@ func Build(root string, dbPath string, opts Options) error {
 	}
 
 	chunkLines, overlap, step := resolveChunkParams(opts)
+	chunking, err := resolveChunking(opts.Chunking)
+	if err != nil {
+		return err
+	}
 	if ex != nil {
+		ex.KV("chunking", chunking)
 		ex.KV("chunk_lines", chunkLines)
 		ex.KV("chunk_overlap", overlap)
 		ex.KV("chunk_step", step)
+		// Without tree-sitter, symbols come from line heuristics: ranges and
+		// kinds are best effort.
+		ex.KV("symbol_extractor", treesitter.Extractor())
+		if !treesitter.Enabled() {
+			ex.KV("symbol_confidence", "low")
+		}
+		if langs := treesitter.HeuristicLanguages(); len(langs) > 0 {
+			ex.KV("symbol_extractor_heuristic", strings.Join(langs, ","))
+		}
 	}
 
 	s, err := backend.Open(opts.Store, dbPath)
@@ -109,6 +162,40 @@ func Build(root string, dbPath string, opts Options) error {
 	if err := s.EnsureWorkspace(workspaceID, root); err != nil {
 		return err
 	}
+	emb, err := resolveEmbedder(s, workspaceID, opts.Embedder)
+	if err != nil {
+		return err
+	}
+	if ex != nil && emb != nil {
+		ex.KV("embed_model", emb.Name())
+	}
+	full := opts.Full
+	if !full && opts.Embedder != nil {
+		// Unchanged files would keep vectors of another model (or none).
+		if name, err := s.VectorModel(workspaceID); err != nil || name != opts.Embedder.Name() {
+			full = true
+		}
+	}
+	format := indexFormat()
+	chunkSetting := fmt.Sprintf("%s/%d/%d", chunking, chunkLines, overlap)
+	if !full {
+		// Unchanged files would keep rows of another format or extractor,
+		// or chunks cut another way.
+		if v, err := s.GetSetting(workspaceID, settingFormat); err != nil || v != format {
+			full = true
+		}
+		if v, err := s.GetSetting(workspaceID, settingChunking); err != nil || v != chunkSetting {
+			full = true
+		}
+	}
+	oldMeta, err := s.ListFilesMeta(workspaceID)
+	if err != nil {
+		return err
+	}
+	if ex != nil {
+		ex.KV("index_format", format)
+		ex.KV("full", full)
+	}
 	if applier, ok := s.(store.BuildPragmaApplier); ok {
 		if err := applier.ApplyBuildPragmas(); err != nil {
 			return err
@@ -137,6 +224,8 @@ func Build(root string, dbPath string, opts Options) error {
 	files, err := walk.ListFiles(root, walk.Options{
 		IncludeGlobs: opts.IncludeGlobs,
 		ExcludeGlobs: opts.ExcludeGlobs,
+		Types:        opts.Types,
+		TypesNot:     opts.TypesNot,
 		ScanAll:      opts.ScanAll,
 	})
 	stopWalk()
@@ -148,13 +237,18 @@ func Build(root string, dbPath string, opts Options) error {
 	}
 
 	type parsedFile struct {
-		rel      string
+		rel    string
+		delete bool
+		// touch only records the new mtime of unchanged content.
+		touch    bool
 		size     int64
 		mtime    int64
 		hash     string
 		chunks   []store.ChunkInput
 		symbols  []store.SymbolInput
 		comments []store.CommentInput
+		refs     []store.RefInput
+		vecs     []store.VectorInput
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -173,13 +267,21 @@ func Build(root string, dbPath string, opts Options) error {
 
 	var skippedDB int64
 	var skippedBinary int64
+	var filesAdded int64
+	var filesChanged int64
+	var filesUnchanged int64
+	var filesRemoved int64
 	var filesIndexed int64
 	var chunksWritten int64
 	var symbolsWritten int64
 	var commentsWritten int64
+	var refsWritten int64
+	var vectorsWritten int64
 	var treesitterDisabled int64
 	var treesitterUnsupported int64
 	var treesitterErrors int64
+	var heuristicFiles int64
+	var docFiles int64
 
 	var writerWG sync.WaitGroup
 	writerWG.Add(1)
@@ -218,12 +320,20 @@ func Build(root string, dbPath string, opts Options) error {
 			stopWrite()
 			for _, plan := range batch {
 				if plan.Delete {
+					atomic.AddInt64(&filesRemoved, 1)
 					continue
 				}
+				if _, ok := oldMeta[plan.Path]; ok {
+					atomic.AddInt64(&filesChanged, 1)
+				} else {
+					atomic.AddInt64(&filesAdded, 1)
+				}
 				atomic.AddInt64(&filesIndexed, 1)
 				atomic.AddInt64(&chunksWritten, int64(len(plan.Chunks)))
 				atomic.AddInt64(&symbolsWritten, int64(len(plan.Syms)))
 				atomic.AddInt64(&commentsWritten, int64(len(plan.Comms)))
+				atomic.AddInt64(&refsWritten, int64(len(plan.Refs)))
+				atomic.AddInt64(&vectorsWritten, int64(len(plan.Vecs)))
 			}
 			batch = batch[:0]
 			batchDocs = 0
@@ -238,6 +348,14 @@ func Build(root string, dbPath string, opts Options) error {
 					_ = flush()
 					return
 				}
+				if pf.touch {
+					if err := s.UpsertFile(workspaceID, filepath.ToSlash(pf.rel), pf.size, pf.mtime, pf.hash); err != nil {
+						sendErr(err, errCh)
+						cancel()
+						return
+					}
+					continue
+				}
 				plan := store.FilePlan{
 					Path:   filepath.ToSlash(pf.rel),
 					Size:   pf.size,
@@ -246,9 +364,12 @@ func Build(root string, dbPath string, opts Options) error {
 					Chunks: pf.chunks,
 					Syms:   pf.symbols,
 					Comms:  pf.comments,
+					Refs:   pf.refs,
+					Vecs:   pf.vecs,
+					Delete: pf.delete,
 				}
 				batch = append(batch, plan)
-				batchDocs += len(plan.Chunks) + len(plan.Syms) + len(plan.Comms)
+				batchDocs += len(plan.Chunks) + len(plan.Syms) + len(plan.Comms) + len(plan.Refs) + len(plan.Vecs)
 				if len(batch) >= batchSize || (docLimit > 0 && batchDocs >= docLimit) {
 					if !flush() {
 						return
@@ -278,16 +399,22 @@ func Build(root string, dbPath string, opts Options) error {
 					if ex != nil {
 						stopParse = ex.Timer("read_parse")
 					}
-					full := filepath.Join(root, filepath.FromSlash(rel))
-					st, err := os.Stat(full)
+					abs := filepath.Join(root, filepath.FromSlash(rel))
+					st, err := os.Stat(abs)
 					if err != nil {
 						sendErr(err, errCh)
 						cancel()
 						stopParse()
 						return
 					}
+					old, known := oldMeta[rel]
+					if !full && known && old.Size == st.Size() && old.MTime == st.ModTime().Unix() {
+						atomic.AddInt64(&filesUnchanged, 1)
+						stopParse()
+						continue
+					}
 
-					b, err := os.ReadFile(full)
+					b, err := os.ReadFile(abs)
 					if err != nil {
 						sendErr(err, errCh)
 						cancel()
@@ -301,20 +428,47 @@ func Build(root string, dbPath string, opts Options) error {
 					}
 
 					hash := hashText(b)
-					chunks := chunkByLines(string(b), chunkLines, step)
-
-					syms, comms, tsErr := ts.Extract(rel, b)
-					if tsErr != nil {
-						if errors.Is(tsErr, treesitter.ErrDisabled) {
-							atomic.AddInt64(&treesitterDisabled, 1)
-						} else if errors.Is(tsErr, treesitter.ErrUnsupported) {
-							atomic.AddInt64(&treesitterUnsupported, 1)
-						} else {
-							atomic.AddInt64(&treesitterErrors, 1)
+					if !full && known && old.Hash != "" && old.Hash == hash {
+						atomic.AddInt64(&filesUnchanged, 1)
+						stopParse()
+						// Keep the next build on the size/mtime fast path.
+						select {
+						case <-ctx.Done():
+							return
+						case parsed <- parsedFile{rel: rel, touch: true, size: st.Size(), mtime: st.ModTime().Unix(), hash: hash}:
 						}
-						syms = nil
-						comms = nil
+						continue
 					}
+
+					var syms []store.SymbolInput
+					var comms []store.CommentInput
+					var refs []store.RefInput
+					if docs.Supported(rel) {
+						syms = docs.Extract(rel, b)
+						atomic.AddInt64(&docFiles, 1)
+					} else {
+						var tsErr error
+						syms, comms, tsErr = ts.Extract(rel, b)
+						if tsErr != nil {
+							if errors.Is(tsErr, treesitter.ErrDisabled) {
+								atomic.AddInt64(&treesitterDisabled, 1)
+							} else if errors.Is(tsErr, treesitter.ErrUnsupported) {
+								atomic.AddInt64(&treesitterUnsupported, 1)
+							} else {
+								atomic.AddInt64(&treesitterErrors, 1)
+							}
+							syms = nil
+							comms = nil
+						}
+						if tsErr == nil {
+							refs, _ = ts.ExtractRefs(rel, b)
+							if treesitter.ExtractorFor(rel) != treesitter.Extractor() {
+								atomic.AddInt64(&heuristicFiles, 1)
+							}
+						}
+					}
+					chunks := chunkFile(string(b), syms, comms, chunking, chunkLines, step)
+					vecs := embedUnits(emb, string(b), chunks, syms)
 					stopParse()
 
 					select {
@@ -328,6 +482,8 @@ func Build(root string, dbPath string, opts Options) error {
 						chunks:   chunks,
 						symbols:  syms,
 						comments: comms,
+						refs:     refs,
+						vecs:     vecs,
 					}:
 					}
 				}
@@ -335,14 +491,36 @@ func Build(root string, dbPath string, opts Options) error {
 		}()
 	}
 
+	isDB := func(rel string) bool {
+		if dbRel == "" {
+			return false
+		}
+		switch rel {
+		case dbRel, dbRel + "-wal", dbRel + "-shm", dbRel + "-journal":
+			return true
+		}
+		return false
+	}
+
+	// Files indexed before but no longer listed (deleted, or now excluded)
+	// are removed.
+	walked := make(map[string]bool, len(files))
+	for _, rel := range files {
+		walked[rel] = true
+	}
+	var removed []string
+	for rel := range oldMeta {
+		if !walked[rel] {
+			removed = append(removed, rel)
+		}
+	}
+	sort.Strings(removed)
+
 feed:
 	for _, rel := range files {
-		if dbRel != "" {
-			switch rel {
-			case dbRel, dbRel + "-wal", dbRel + "-shm", dbRel + "-journal":
-				atomic.AddInt64(&skippedDB, 1)
-				continue
-			}
+		if isDB(rel) {
+			atomic.AddInt64(&skippedDB, 1)
+			continue
 		}
 
 		select {
@@ -353,8 +531,18 @@ feed:
 	}
 	close(jobs)
 
-	workersWG.Wait()
-	close(parsed)
+	go func() {
+		defer close(parsed)
+		workersWG.Wait()
+		for _, rel := range removed {
+			select {
+			case <-ctx.Done():
+				return
+			case parsed <- parsedFile{rel: rel, delete: true}:
+			}
+		}
+	}()
+
 	writerWG.Wait()
 
 	select {
@@ -363,6 +551,12 @@ feed:
 	default:
 	}
 
+	if err := s.SetSetting(workspaceID, settingFormat, format); err != nil {
+		return err
+	}
+	if err := s.SetSetting(workspaceID, settingChunking, chunkSetting); err != nil {
+		return err
+	}
 	if err := s.BumpVersion(workspaceID); err != nil {
 		return err
 	}
@@ -370,13 +564,23 @@ feed:
 	if ex != nil {
 		ex.KV("files_skipped_db", skippedDB)
 		ex.KV("files_skipped_binary", skippedBinary)
+		ex.KV("files_added", filesAdded)
+		ex.KV("files_changed", filesChanged)
+		ex.KV("files_unchanged", filesUnchanged)
+		ex.KV("files_removed", filesRemoved)
 		ex.KV("files_indexed", filesIndexed)
 		ex.KV("chunks_written", chunksWritten)
 		ex.KV("symbols_written", symbolsWritten)
 		ex.KV("comments_written", commentsWritten)
+		ex.KV("refs_written", refsWritten)
+		ex.KV("vectors_written", vectorsWritten)
 		ex.KV("treesitter_disabled", treesitterDisabled)
 		ex.KV("treesitter_unsupported", treesitterUnsupported)
 		ex.KV("treesitter_errors", treesitterErrors)
+		if len(treesitter.HeuristicLanguages()) > 0 {
+			ex.KV("heuristic_files", heuristicFiles)
+		}
+		ex.KV("doc_files", docFiles)
 		ex.KV("elapsed_ms_total", time.Since(startTotal).Milliseconds())
 	}
 
@@ -450,8 +654,14 @@ type UpdatePlan struct {
 	Chunks []store.ChunkInput
 	Syms   []store.SymbolInput
 	Comms  []store.CommentInput
+	Refs   []store.RefInput
 	Delete bool
 	Skip   bool
+	// Touch only records the new mtime of unchanged content.
+	Touch bool
+
+	// text is kept for embedding, which needs the store to pick the model.
+	text string
 }
 
 func PrepareUpdatePlan(root string, rel string, opts Options, old *store.File, oldOK bool) (UpdatePlan, error) {
@@ -467,6 +677,10 @@ func PrepareUpdatePlan(root string, rel string, opts Options, old *store.File, o
 	}
 
 	chunkLines, _, step := resolveChunkParams(opts)
+	chunking, err := resolveChunking(opts.Chunking)
+	if err != nil {
+		return UpdatePlan{}, err
+	}
 
 	full := filepath.Join(root, filepath.FromSlash(rel))
 	st, err := os.Stat(full)
@@ -494,12 +708,20 @@ func PrepareUpdatePlan(root string, rel string, opts Options, old *store.File, o
 
 	hash := hashText(b)
 	if oldOK && old != nil && old.Hash != "" && old.Hash == hash {
-		return UpdatePlan{Rel: rel, Skip: true}, nil
+		return UpdatePlan{Rel: rel, Size: size, MTime: mtime, Hash: hash, Touch: true}, nil
 	}
 
-	chunks := chunkByLines(string(b), chunkLines, step)
-	ts := treesitter.NewProvider()
-	syms, comms, _ := ts.Extract(rel, b)
+	var syms []store.SymbolInput
+	var comms []store.CommentInput
+	var refs []store.RefInput
+	if docs.Supported(rel) {
+		syms = docs.Extract(rel, b)
+	} else {
+		ts := treesitter.NewProvider()
+		syms, comms, _ = ts.Extract(rel, b)
+		refs, _ = ts.ExtractRefs(rel, b)
+	}
+	chunks := chunkFile(string(b), syms, comms, chunking, chunkLines, step)
 
 	return UpdatePlan{
 		Rel:    rel,
@@ -509,6 +731,8 @@ func PrepareUpdatePlan(root string, rel string, opts Options, old *store.File, o
 		Chunks: chunks,
 		Syms:   syms,
 		Comms:  comms,
+		Refs:   refs,
+		text:   string(b),
 	}, nil
 }
 
@@ -521,11 +745,29 @@ func ApplyUpdatePlansBatch(s store.Store, workspaceID string, plans []UpdatePlan
 		return nil
 	}
 
+	var emb embed.Embedder
+	for _, plan := range plans {
+		if !plan.Skip && !plan.Delete && !plan.Touch {
+			e, err := resolveEmbedder(s, workspaceID, nil)
+			if err != nil {
+				return err
+			}
+			emb = e
+			break
+		}
+	}
+
 	batch := make([]store.FilePlan, 0, len(plans))
 	for _, plan := range plans {
 		if plan.Skip {
 			continue
 		}
+		if plan.Touch {
+			if err := s.UpsertFile(workspaceID, plan.Rel, plan.Size, plan.MTime, plan.Hash); err != nil {
+				return err
+			}
+			continue
+		}
 		batch = append(batch, store.FilePlan{
 			Path:   plan.Rel,
 			Size:   plan.Size,
@@ -534,6 +776,8 @@ func ApplyUpdatePlansBatch(s store.Store, workspaceID string, plans []UpdatePlan
 			Chunks: plan.Chunks,
 			Syms:   plan.Syms,
 			Comms:  plan.Comms,
+			Refs:   plan.Refs,
+			Vecs:   embedUnits(emb, plan.text, plan.Chunks, plan.Syms),
 			Delete: plan.Delete,
 		})
 	}
@@ -0,0 +1,60 @@
+package indexer
+
+import (
+	"strings"
+
+	"otterindex/internal/core/embed"
+	"otterindex/internal/index/store"
+)
+
+// embedUnits embeds every chunk and every function-like symbol of a file.
+// Symbols are embedded with their name prepended so the identifier weighs in
+// even when the body is long.
+func embedUnits(e embed.Embedder, text string, chunks []store.ChunkInput, syms []store.SymbolInput) []store.VectorInput {
+	if e == nil {
+		return nil
+	}
+	model := e.Name()
+	out := make([]store.VectorInput, 0, len(chunks))
+	for _, c := range chunks {
+		out = append(out, store.VectorInput{Kind: "chunk", SL: c.SL, EL: c.EL, Model: model, Vec: e.Embed(c.Text)})
+	}
+	var lines []string
+	for _, sym := range syms {
+		switch sym.Kind {
+		case "function", "method", "constructor":
+		default:
+			continue
+		}
+		if lines == nil {
+			lines = splitLines(text)
+		}
+		sl, el := sym.SL, sym.EL
+		if sl < 1 {
+			sl = 1
+		}
+		if el > len(lines) {
+			el = len(lines)
+		}
+		if el < sl {
+			continue
+		}
+		body := sym.Name + "\n" + strings.Join(lines[sl-1:el], "\n")
+		out = append(out, store.VectorInput{Kind: "symbol", Title: sym.Name, SL: sym.SL, EL: sym.EL, Model: model, Vec: e.Embed(body)})
+	}
+	return out
+}
+
+// resolveEmbedder returns e, or the embedder that produced the workspace's
+// existing vectors so rebuilds and updates keep them current.
+func resolveEmbedder(s store.Store, workspaceID string, e embed.Embedder) (embed.Embedder, error) {
+	if e != nil {
+		return e, nil
+	}
+	name, err := s.VectorModel(workspaceID)
+	if err != nil || name == "" {
+		return nil, err
+	}
+	e, _ = embed.Lookup(name)
+	return e, nil
+}
@@ -0,0 +1,90 @@
+package lang
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type Language struct {
+	Name       string
+	Aliases    []string
+	Extensions []string
+}
+
+var builtin = []Language{
+	{Name: "go", Extensions: []string{".go"}},
+	{Name: "java", Extensions: []string{".java"}},
+	{Name: "python", Aliases: []string{"py"}, Extensions: []string{".py"}},
+	{Name: "javascript", Aliases: []string{"js"}, Extensions: []string{".js", ".jsx", ".mjs", ".cjs"}},
+	{Name: "typescript", Aliases: []string{"ts"}, Extensions: []string{".ts"}},
+	{Name: "tsx", Extensions: []string{".tsx"}},
+	{Name: "php", Extensions: []string{".php"}},
+	{Name: "csharp", Aliases: []string{"cs", "c#"}, Extensions: []string{".cs", ".csx"}},
+	{Name: "json", Extensions: []string{".json", ".jsonc"}},
+	{Name: "bash", Aliases: []string{"sh", "shell"}, Extensions: []string{".sh", ".bash"}},
+	{Name: "c", Extensions: []string{".c"}},
+	// Headers are parsed as C++; it can usually parse C too.
+	{Name: "cpp", Aliases: []string{"c++", "cxx"}, Extensions: []string{".cc", ".cpp", ".cxx", ".hpp", ".hh", ".hxx", ".h"}},
+	{Name: "rust", Aliases: []string{"rs"}, Extensions: []string{".rs"}},
+	{Name: "ruby", Aliases: []string{"rb"}, Extensions: []string{".rb"}},
+	{Name: "kotlin", Aliases: []string{"kt"}, Extensions: []string{".kt", ".kts"}},
+	{Name: "swift", Extensions: []string{".swift"}},
+	{Name: "lua", Extensions: []string{".lua"}},
+	{Name: "yaml", Aliases: []string{"yml"}, Extensions: []string{".yaml", ".yml"}},
+	{Name: "toml", Extensions: []string{".toml"}},
+	{Name: "html", Extensions: []string{".html", ".htm"}},
+	{Name: "css", Extensions: []string{".css"}},
+	{Name: "sql", Extensions: []string{".sql"}},
+	{Name: "markdown", Aliases: []string{"md"}, Extensions: []string{".md", ".markdown"}},
+	{Name: "rst", Aliases: []string{"restructuredtext"}, Extensions: []string{".rst"}},
+	{Name: "asciidoc", Aliases: []string{"adoc"}, Extensions: []string{".adoc", ".asciidoc"}},
+}
+
+var (
+	byName = map[string]Language{}
+	byExt  = map[string]string{}
+)
+
+func init() {
+	for _, l := range builtin {
+		byName[l.Name] = l
+		for _, a := range l.Aliases {
+			byName[a] = l
+		}
+		for _, ext := range l.Extensions {
+			byExt[ext] = l.Name
+		}
+	}
+}
+
+func Lookup(name string) (Language, bool) {
+	l, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+	return l, ok
+}
+
+func FromPath(path string) string {
+	return byExt[strings.ToLower(filepath.Ext(strings.TrimSpace(path)))]
+}
+
+// Globs returns basename patterns matching the language's files.
+func (l Language) Globs() []string {
+	out := make([]string, 0, len(l.Extensions))
+	for _, ext := range l.Extensions {
+		out = append(out, "*"+ext)
+	}
+	return out
+}
+
+// PathRegexp returns an unanchored RE2 expression matching a whole
+// slash-separated path of the languages' files. Like FromPath, it compares
+// extensions case-insensitively.
+func PathRegexp(langs ...Language) string {
+	var exts []string
+	for _, l := range langs {
+		for _, ext := range l.Extensions {
+			exts = append(exts, regexp.QuoteMeta(strings.TrimPrefix(ext, ".")))
+		}
+	}
+	return `(?:.*/)?[^/]*\.(?i:` + strings.Join(exts, "|") + `)`
+}
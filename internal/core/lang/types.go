@@ -0,0 +1,152 @@
+package lang
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Type is a named set of file globs for -t/--type filters, like ripgrep's
+// file types. Globs without a '/' match the basename.
+type Type struct {
+	Name    string
+	Aliases []string
+	Globs   []string
+}
+
+// typeExtras are files that belong to a language's type without being parsed
+// as that language.
+var typeExtras = map[string][]string{
+	"go": {"go.mod", "go.sum", "go.work"},
+}
+
+// builtinTypes are types that are not languages.
+var builtinTypes = []Type{
+	{Name: "docker", Globs: []string{"Dockerfile", "*.dockerfile"}},
+	{Name: "make", Globs: []string{"Makefile", "makefile", "GNUmakefile", "*.mk"}},
+	{Name: "proto", Globs: []string{"*.proto"}},
+}
+
+var typeNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_+-]*$`)
+
+var (
+	typesMu   sync.RWMutex
+	userTypes = map[string][]string{}
+)
+
+// AddType registers globs under name. Adding to an existing type (built-in
+// or not) extends it.
+func AddType(name string, globs ...string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !typeNameRe.MatchString(name) {
+		return fmt.Errorf("invalid type name %q", name)
+	}
+	var clean []string
+	for _, g := range globs {
+		g = strings.TrimSpace(strings.ReplaceAll(g, "\\", "/"))
+		if g == "" {
+			continue
+		}
+		if _, err := path.Match(g, ""); err != nil {
+			return fmt.Errorf("invalid glob %q for type %s", g, name)
+		}
+		clean = append(clean, g)
+	}
+	if len(clean) == 0 {
+		return fmt.Errorf("type %s has no globs", name)
+	}
+
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	userTypes[name] = appendNew(userTypes[name], clean...)
+	return nil
+}
+
+// ParseTypeDef parses a --type-add definition: "name:glob[,glob...]".
+func ParseTypeDef(def string) (string, []string, error) {
+	name, globs, ok := strings.Cut(def, ":")
+	if !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(globs) == "" {
+		return "", nil, fmt.Errorf("invalid type definition %q (expected: name:glob[,glob])", def)
+	}
+	return strings.TrimSpace(name), strings.Split(globs, ","), nil
+}
+
+// Types returns every type, languages included, sorted by name.
+func Types() []Type {
+	all := map[string][]string{}
+	for _, l := range builtin {
+		all[l.Name] = appendNew(l.Globs(), typeExtras[l.Name]...)
+	}
+	for _, t := range builtinTypes {
+		all[t.Name] = appendNew(all[t.Name], t.Globs...)
+	}
+	typesMu.RLock()
+	for name, globs := range userTypes {
+		key := name
+		if l, ok := Lookup(name); ok {
+			key = l.Name
+		}
+		all[key] = appendNew(all[key], globs...)
+	}
+	typesMu.RUnlock()
+
+	out := make([]Type, 0, len(all))
+	for name, globs := range all {
+		t := Type{Name: name, Globs: globs}
+		if l, ok := byName[name]; ok {
+			t.Aliases = l.Aliases
+		}
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// LookupType finds a type by name or language alias.
+func LookupType(name string) (Type, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if l, ok := Lookup(name); ok {
+		name = l.Name
+	}
+	for _, t := range Types() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// TypeGlobs returns the globs of the named types.
+func TypeGlobs(names []string) ([]string, error) {
+	var out []string
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		t, ok := LookupType(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q (see otidx types)", name)
+		}
+		out = appendNew(out, t.Globs...)
+	}
+	return out, nil
+}
+
+func appendNew(dst []string, vals ...string) []string {
+	for _, v := range vals {
+		dup := false
+		for _, d := range dst {
+			if d == v {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,64 @@
+package lang
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTypeDef(t *testing.T) {
+	name, globs, err := ParseTypeDef("web:*.html,*.css")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if name != "web" || !reflect.DeepEqual(globs, []string{"*.html", "*.css"}) {
+		t.Fatalf("got name=%q globs=%v", name, globs)
+	}
+	for _, bad := range []string{"web", "web:", ":*.html"} {
+		if _, _, err := ParseTypeDef(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestTypeGlobs(t *testing.T) {
+	globs, err := TypeGlobs([]string{"go"})
+	if err != nil {
+		t.Fatalf("type globs: %v", err)
+	}
+	if !reflect.DeepEqual(globs, []string{"*.go", "go.mod", "go.sum", "go.work"}) {
+		t.Fatalf("go globs: %v", globs)
+	}
+	if _, err := TypeGlobs([]string{"nope"}); err == nil {
+		t.Fatalf("expected unknown type error")
+	}
+}
+
+func TestAddType(t *testing.T) {
+	t.Cleanup(func() {
+		typesMu.Lock()
+		userTypes = map[string][]string{}
+		typesMu.Unlock()
+	})
+
+	if err := AddType("Bad Name", "*.x"); err == nil {
+		t.Fatalf("expected invalid name error")
+	}
+	if err := AddType("web", " ", ""); err == nil {
+		t.Fatalf("expected no globs error")
+	}
+	if err := AddType("web", "*.html", "*.css"); err != nil {
+		t.Fatalf("add web: %v", err)
+	}
+	if err := AddType("py", "*.pyi"); err != nil {
+		t.Fatalf("extend python: %v", err)
+	}
+
+	web, ok := LookupType("web")
+	if !ok || !reflect.DeepEqual(web.Globs, []string{"*.html", "*.css"}) {
+		t.Fatalf("web: %+v ok=%v", web, ok)
+	}
+	py, ok := LookupType("python")
+	if !ok || !reflect.DeepEqual(py.Globs, []string{"*.py", "*.pyi"}) {
+		t.Fatalf("python not extended: %+v", py)
+	}
+}
@@ -0,0 +1,117 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"otterindex/internal/core/lang"
+	"otterindex/internal/core/treesitter"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/model"
+)
+
+type ASTOptions struct {
+	Store        string
+	Lang         string
+	IncludeGlobs []string
+	ExcludeGlobs []string
+	Limit        int
+}
+
+// AST runs a tree-sitter S-expression query over the indexed files of one
+// language and returns the matched nodes with their exact ranges. Files are
+// parsed from the workspace as they are on disk.
+func AST(dbPath string, workspaceID string, pattern string, opts ASTOptions) ([]model.ResultItem, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(pattern) == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	langName := strings.ToLower(strings.TrimSpace(opts.Lang))
+	if langName == "" {
+		return nil, fmt.Errorf("lang is required")
+	}
+	if l, ok := lang.Lookup(langName); ok {
+		langName = l.Name
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 100
+	}
+	aq, err := treesitter.CompileASTQuery(langName, pattern)
+	if errors.Is(err, treesitter.ErrDisabled) {
+		return nil, fmt.Errorf("ast queries need a tree-sitter build (-tags treesitter, CGO enabled)")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer aq.Close()
+
+	filter, err := newPathFilter(pathSpec{Include: opts.IncludeGlobs, Exclude: opts.ExcludeGlobs, Lang: langName})
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	ws, err := s.GetWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.ListFilesMeta(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		if filter.Match(p) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var out []model.ResultItem
+	for _, p := range paths {
+		src, err := os.ReadFile(filepath.Join(ws.Root, filepath.FromSlash(p)))
+		if err != nil {
+			// Deleted since the last build.
+			continue
+		}
+		matches, err := aq.Match(src)
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.Split(string(src), "\n")
+		for _, m := range matches {
+			line := ""
+			if m.SL <= len(lines) {
+				line = strings.TrimRight(lines[m.SL-1], "\r")
+			}
+			first, _, _ := strings.Cut(m.Text, "\n")
+			out = append(out, model.ResultItem{
+				Kind:    "ast",
+				Path:    p,
+				Range:   model.Range{SL: m.SL, SC: m.SC, EL: m.EL, EC: m.EC},
+				Title:   m.Kind,
+				Snippet: strings.TrimSpace(line),
+				Matches: []model.Match{{Line: m.SL, Col: m.SC, Len: len(strings.TrimRight(first, "\r")), Text: line}},
+			})
+			if len(out) >= opts.Limit {
+				return out, nil
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/core/treesitter"
+	"otterindex/internal/index/backend"
+)
+
+func TestAST(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			_ = os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n\nfunc A() {\n\tif x == nil {\n\t\treturn\n\t}\n}\n"), 0o644)
+			_ = os.WriteFile(filepath.Join(root, "b.py"), []byte("x = None\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			if _, err := AST(dbPath, root, "(nil)", ASTOptions{Store: storeName}); err == nil {
+				t.Fatalf("expected lang required error")
+			}
+
+			items, err := AST(dbPath, root, "(if_statement) @if", ASTOptions{Store: storeName, Lang: "go"})
+			if len(treesitter.ASTLanguages()) == 0 {
+				if err == nil {
+					t.Fatalf("expected error without tree-sitter")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ast: %v", err)
+			}
+			if len(items) != 1 {
+				t.Fatalf("expected 1 item, got %+v", items)
+			}
+			it := items[0]
+			if it.Path != "a.go" || it.Title != "if_statement" || it.Range.SL != 4 || it.Range.SC != 2 || it.Range.EL != 6 || it.Range.EC != 3 {
+				t.Fatalf("unexpected item: %+v", it)
+			}
+			if len(it.Matches) != 1 || it.Matches[0].Col != 2 || it.Matches[0].Len != len("if x == nil {") {
+				t.Fatalf("unexpected matches: %+v", it.Matches)
+			}
+
+			items, err = AST(dbPath, root, "(if_statement)", ASTOptions{Store: storeName, Lang: "go", ExcludeGlobs: []string{"a.go"}})
+			if err != nil {
+				t.Fatalf("ast: %v", err)
+			}
+			if len(items) != 0 {
+				t.Fatalf("expected no items, got %+v", items)
+			}
+		})
+	}
+}
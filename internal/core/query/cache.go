@@ -60,6 +60,35 @@ func makeCacheKey(workspaceID string, version int64, q string, opts Options) str
 	_, _ = fmt.Fprintf(&b, "|unit=%s|i=%t", opts.Unit, opts.CaseInsensitive)
 	_, _ = fmt.Fprintf(&b, "|limit=%d|offset=%d", opts.Limit, opts.Offset)
 	_, _ = fmt.Fprintf(&b, "|ctx=%d", opts.ContextLines)
+	if opts.Regex {
+		b.WriteString("|re=1")
+	}
+	_, _ = fmt.Fprintf(&b, "|sort=%s", normalizeSort(opts.Sort))
+	_, _ = fmt.Fprintf(&b, "|in=%s", normalizeIn(opts.In))
+	if opts.Boundary != "" {
+		_, _ = fmt.Fprintf(&b, "|b=%s", opts.Boundary)
+	}
+	if opts.AllTerms {
+		b.WriteString("|all=1")
+	}
+	if key := fusionKey(opts); key != "" {
+		b.WriteString(key)
+	}
+	if len(opts.Types) > 0 {
+		_, _ = fmt.Fprintf(&b, "|t=%s", strings.Join(opts.Types, ","))
+	}
+	if len(opts.TypesNot) > 0 {
+		_, _ = fmt.Fprintf(&b, "|T=%s", strings.Join(opts.TypesNot, ","))
+	}
+	if len(opts.Paths) > 0 {
+		_, _ = fmt.Fprintf(&b, "|p=%s", strings.Join(opts.Paths, ","))
+	}
+	if opts.Lang != "" {
+		_, _ = fmt.Fprintf(&b, "|lang=%s", opts.Lang)
+	}
+	if opts.MaxPerFile != 0 {
+		_, _ = fmt.Fprintf(&b, "|mpf=%d", opts.MaxPerFile)
+	}
 	if len(opts.IncludeGlobs) > 0 {
 		_, _ = fmt.Fprintf(&b, "|inc=%s", strings.Join(opts.IncludeGlobs, ","))
 	}
@@ -0,0 +1,264 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"otterindex/internal/core/lang"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+type CallGraphOptions struct {
+	Store string
+	Lang  string
+	// Depth is how many call levels to follow; 1 lists direct callers or
+	// callees only.
+	Depth int
+	// Limit caps the number of edges returned.
+	Limit int
+}
+
+// Callers lists the call sites of a function and, up to opts.Depth levels,
+// the call sites of the functions containing them. Calls are matched by the
+// called name only, so "Options.Prepare" also finds calls to other types'
+// Prepare methods.
+func Callers(dbPath string, workspaceID string, name string, opts CallGraphOptions) ([]model.CallEdge, error) {
+	g, err := openCallGraph(dbPath, workspaceID, name, &opts)
+	if err != nil {
+		return nil, err
+	}
+	defer g.close()
+
+	first := refKey(strings.TrimSpace(name))
+	frontier := []string{first}
+	seen := map[string]bool{first: true}
+	var out []model.CallEdge
+	for depth := 1; depth <= opts.Depth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, callee := range frontier {
+			refs, err := g.s.FindRefs(g.workspaceID, callee, store.RefSearchOptions{
+				Kind:  "call",
+				Lang:  g.lang,
+				Limit: opts.Limit - len(out),
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range refs {
+				caller := g.enclosingFunc(r.Path, r.Range)
+				out = append(out, g.edge(caller, r, depth))
+				if len(out) >= opts.Limit {
+					return out, nil
+				}
+				if caller != nil && !seen[caller.Name] {
+					seen[caller.Name] = true
+					next = append(next, caller.Name)
+				}
+			}
+		}
+		frontier = next
+	}
+	return out, nil
+}
+
+// Callees lists the calls made inside a function and, up to opts.Depth
+// levels, the calls made by the indexed functions it calls.
+func Callees(dbPath string, workspaceID string, name string, opts CallGraphOptions) ([]model.CallEdge, error) {
+	g, err := openCallGraph(dbPath, workspaceID, name, &opts)
+	if err != nil {
+		return nil, err
+	}
+	defer g.close()
+
+	frontier, err := g.definitions(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(frontier) == 0 {
+		return nil, fmt.Errorf("function %q not found", strings.TrimSpace(name))
+	}
+	visited := map[string]bool{}
+	for _, def := range frontier {
+		visited[defKey(def)] = true
+	}
+	expanded := map[string]bool{}
+
+	var out []model.CallEdge
+	for depth := 1; depth <= opts.Depth && len(frontier) > 0; depth++ {
+		var next []model.SymbolItem
+		for i := range frontier {
+			def := frontier[i]
+			refs, err := g.refsIn(def.Path)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range refs {
+				if r.Kind != "call" || !rangeContains(def.Range, r.Range) {
+					continue
+				}
+				if g.lang != "" && r.Lang != g.lang {
+					continue
+				}
+				// Calls inside a nested function belong to that function.
+				if inner := g.enclosingFunc(def.Path, r.Range); inner == nil || inner.Range != def.Range {
+					continue
+				}
+				out = append(out, g.edge(&def, r, depth))
+				if len(out) >= opts.Limit {
+					return out, nil
+				}
+				if depth == opts.Depth || expanded[r.Name] {
+					continue
+				}
+				expanded[r.Name] = true
+				defs, err := g.definitions(r.Name)
+				if err != nil {
+					return nil, err
+				}
+				for _, d := range defs {
+					if !visited[defKey(d)] {
+						visited[defKey(d)] = true
+						next = append(next, d)
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+	return out, nil
+}
+
+type callGraph struct {
+	s           store.Store
+	workspaceID string
+	lang        string
+	lines       *sourceLines
+	syms        map[string][]model.SymbolItem
+	refs        map[string][]model.RefItem
+}
+
+func openCallGraph(dbPath string, workspaceID string, name string, opts *CallGraphOptions) (*callGraph, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if opts.Depth <= 0 {
+		opts.Depth = 1
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 100
+	}
+	langName := strings.ToLower(strings.TrimSpace(opts.Lang))
+	if l, ok := lang.Lookup(langName); ok {
+		langName = l.Name
+	}
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	ws, _ := s.GetWorkspace(workspaceID)
+	return &callGraph{
+		s:           s,
+		workspaceID: workspaceID,
+		lang:        langName,
+		lines:       newSourceLines(ws.Root),
+		syms:        map[string][]model.SymbolItem{},
+		refs:        map[string][]model.RefItem{},
+	}, nil
+}
+
+func (g *callGraph) close() {
+	_ = g.s.Close()
+}
+
+func (g *callGraph) edge(caller *model.SymbolItem, r model.RefItem, depth int) model.CallEdge {
+	return model.CallEdge{
+		Caller: caller,
+		Callee: r.Name,
+		Path:   r.Path,
+		Range:  r.Range,
+		Text:   g.lines.line(r.Path, r.Range.SL),
+		Depth:  depth,
+	}
+}
+
+func (g *callGraph) symbolsIn(path string) []model.SymbolItem {
+	syms, ok := g.syms[path]
+	if !ok {
+		syms, _ = g.s.ListSymbols(g.workspaceID, path)
+		g.syms[path] = syms
+	}
+	return syms
+}
+
+func (g *callGraph) refsIn(path string) ([]model.RefItem, error) {
+	if refs, ok := g.refs[path]; ok {
+		return refs, nil
+	}
+	refs, err := g.s.ListRefs(g.workspaceID, path)
+	if err != nil {
+		return nil, err
+	}
+	g.refs[path] = refs
+	return refs, nil
+}
+
+// enclosingFunc returns the innermost function, method or constructor
+// containing r.
+func (g *callGraph) enclosingFunc(path string, r model.Range) *model.SymbolItem {
+	var funcs []model.SymbolItem
+	for _, sym := range g.symbolsIn(path) {
+		if isCallable(sym.Kind) {
+			funcs = append(funcs, sym)
+		}
+	}
+	return enclosingSymbol(funcs, r)
+}
+
+// definitions returns the indexed functions named name. "Type.method" (or
+// "Type::method") also requires the container to match.
+func (g *callGraph) definitions(name string) ([]model.SymbolItem, error) {
+	name = strings.ReplaceAll(strings.TrimSpace(name), "::", ".")
+	key := refKey(name)
+	container := ""
+	if i := strings.LastIndex(name, "."); i > 0 && !strings.Contains(name, "/") {
+		container = containerKey(name[:i])
+	}
+
+	cands, err := g.s.SearchSymbols(g.workspaceID, key, store.SymbolSearchOptions{Lang: g.lang, Limit: 1000})
+	if err != nil {
+		return nil, err
+	}
+	var out []model.SymbolItem
+	for _, sym := range cands {
+		if sym.Name != key || !isCallable(sym.Kind) {
+			continue
+		}
+		if container != "" && containerKey(sym.Container) != container {
+			continue
+		}
+		out = append(out, sym)
+	}
+	return out, nil
+}
+
+func isCallable(kind string) bool {
+	switch kind {
+	case "function", "method", "constructor":
+		return true
+	}
+	return false
+}
+
+func defKey(sym model.SymbolItem) string {
+	return fmt.Sprintf("%s:%d:%d", sym.Path, sym.Range.SL, sym.Range.SC)
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+func edgeShape(edges []model.CallEdge) string {
+	var parts []string
+	for _, e := range edges {
+		caller := "-"
+		if e.Caller != nil {
+			caller = e.Caller.Name
+		}
+		parts = append(parts, strings.Repeat(">", e.Depth)+caller+"->"+e.Callee)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestCallGraph(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			_ = os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			// main calls hello and S.Run; Run calls hello; hello calls greet.
+			st, err := backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			if err := st.ReplaceSymbolsBatch(root, "main.go", []store.SymbolInput{
+				{Kind: "type", Name: "S", SL: 3, SC: 1, EL: 3, EC: 20, Lang: "go"},
+				{Kind: "method", Name: "Run", Container: "*S", SL: 5, SC: 1, EL: 5, EC: 30, Lang: "go"},
+				{Kind: "function", Name: "hello", SL: 7, SC: 1, EL: 9, EC: 2, Lang: "go"},
+				{Kind: "function", Name: "greet", SL: 11, SC: 1, EL: 11, EC: 16, Lang: "go"},
+				{Kind: "function", Name: "main", SL: 13, SC: 1, EL: 16, EC: 2, Lang: "go"},
+			}); err != nil {
+				t.Fatalf("replace symbols: %v", err)
+			}
+			if err := st.ReplaceRefsBatch(root, "main.go", []store.RefInput{
+				{Kind: "call", Name: "hello", SL: 5, SC: 21, EL: 5, EC: 26, Lang: "go"},
+				{Kind: "call", Name: "greet", SL: 8, SC: 2, EL: 8, EC: 7, Lang: "go"},
+				{Kind: "ref", Name: "greet", SL: 12, SC: 2, EL: 12, EC: 7, Lang: "go"},
+				{Kind: "call", Name: "hello", SL: 14, SC: 2, EL: 14, EC: 7, Lang: "go"},
+				{Kind: "call", Name: "Run", SL: 15, SC: 9, EL: 15, EC: 12, Lang: "go"},
+			}); err != nil {
+				t.Fatalf("replace refs: %v", err)
+			}
+			_ = st.Close()
+
+			opts := CallGraphOptions{Store: storeName}
+			edges, err := Callers(dbPath, root, "greet", opts)
+			if err != nil {
+				t.Fatalf("callers: %v", err)
+			}
+			if got := edgeShape(edges); got != ">hello->greet" {
+				t.Fatalf("callers: %s", got)
+			}
+
+			opts.Depth = 3
+			edges, err = Callers(dbPath, root, "greet", opts)
+			if err != nil {
+				t.Fatalf("callers: %v", err)
+			}
+			if got := edgeShape(edges); got != ">hello->greet >>Run->hello >>main->hello >>>main->Run" {
+				t.Fatalf("callers depth 3: %s", got)
+			}
+
+			edges, err = Callees(dbPath, root, "main", opts)
+			if err != nil {
+				t.Fatalf("callees: %v", err)
+			}
+			if got := edgeShape(edges); got != ">main->hello >main->Run >>hello->greet >>Run->hello" {
+				t.Fatalf("callees depth 3: %s", got)
+			}
+
+			edges, err = Callees(dbPath, root, "S.Run", CallGraphOptions{Store: storeName})
+			if err != nil {
+				t.Fatalf("callees: %v", err)
+			}
+			if got := edgeShape(edges); got != ">Run->hello" {
+				t.Fatalf("callees S.Run: %s", got)
+			}
+
+			if _, err := Callees(dbPath, root, "T.Run", CallGraphOptions{Store: storeName}); err == nil {
+				t.Fatalf("expected error for unknown function")
+			}
+		})
+	}
+}
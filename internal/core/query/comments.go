@@ -0,0 +1,194 @@
+package query
+
+import (
+	"strings"
+
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+const (
+	inAll      = "all"
+	inCode     = "code"
+	inComments = "comments"
+)
+
+func normalizeIn(v string) string {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" {
+		return inAll
+	}
+	return v
+}
+
+// commentIndex lazily loads the comments (and, for attribution, the symbols)
+// of the files a query touches.
+type commentIndex struct {
+	s           store.Store
+	workspaceID string
+	comments    map[string][]model.CommentItem
+	symbols     map[string][]model.SymbolItem
+}
+
+func newCommentIndex(s store.Store, workspaceID string) *commentIndex {
+	return &commentIndex{
+		s:           s,
+		workspaceID: workspaceID,
+		comments:    map[string][]model.CommentItem{},
+		symbols:     map[string][]model.SymbolItem{},
+	}
+}
+
+func (ci *commentIndex) forPath(path string) []model.CommentItem {
+	comms, ok := ci.comments[path]
+	if !ok {
+		comms, _ = ci.s.ListComments(ci.workspaceID, path)
+		ci.comments[path] = comms
+	}
+	return comms
+}
+
+func (ci *commentIndex) symbolsFor(path string) []model.SymbolItem {
+	syms, ok := ci.symbols[path]
+	if !ok {
+		syms, _ = ci.s.ListSymbols(ci.workspaceID, path)
+		ci.symbols[path] = syms
+	}
+	return syms
+}
+
+// mask blanks out the comments in text (a chunk starting at line sl), or
+// with keepComments everything but the comments, so matching only sees the
+// wanted parts. Columns are preserved.
+func (ci *commentIndex) mask(path string, sl int, text string, keepComments bool) string {
+	comms := ci.forPath(path)
+	if len(comms) == 0 && !keepComments {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		inComment := make([]bool, len(line))
+		for _, c := range comms {
+			lineNo := sl + i
+			if lineNo < c.Range.SL || lineNo > c.Range.EL {
+				continue
+			}
+			from, to := 0, len(line)
+			if lineNo == c.Range.SL && c.Range.SC > 1 {
+				from = min(c.Range.SC-1, len(line))
+			}
+			if lineNo == c.Range.EL && c.Range.EC > 1 {
+				to = min(c.Range.EC-1, len(line))
+			}
+			for col := from; col < to; col++ {
+				inComment[col] = true
+			}
+		}
+		b := []byte(line)
+		for col := range b {
+			if inComment[col] != keepComments && b[col] != '\t' {
+				b[col] = ' '
+			}
+		}
+		lines[i] = string(b)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type commentHit struct {
+	comment model.CommentItem
+	symbol  *model.SymbolItem
+	matches []model.Match
+}
+
+// commentHits groups the matches that fall inside comments by comment,
+// attributing each comment to the symbol it documents.
+func (ci *commentIndex) commentHits(path string, matches []model.Match) []commentHit {
+	comms := ci.forPath(path)
+	if len(comms) == 0 {
+		return nil
+	}
+	var hits []commentHit
+	byComment := map[int]int{}
+	for _, m := range matches {
+		idx := commentAt(comms, m)
+		if idx < 0 {
+			continue
+		}
+		h, ok := byComment[idx]
+		if !ok {
+			h = len(hits)
+			byComment[idx] = h
+			hits = append(hits, commentHit{
+				comment: comms[idx],
+				symbol:  documentedSymbol(comms, idx, ci.symbolsFor(path)),
+			})
+		}
+		hits[h].matches = append(hits[h].matches, m)
+	}
+	return hits
+}
+
+// commentAt returns the index of the comment containing m, or -1. Comment
+// end columns are exclusive.
+func commentAt(comms []model.CommentItem, m model.Match) int {
+	for i, c := range comms {
+		r := c.Range
+		if m.Line < r.SL || m.Line > r.EL {
+			continue
+		}
+		if m.Line == r.SL && r.SC > 1 && m.Col < r.SC {
+			continue
+		}
+		if m.Line == r.EL && r.EC > 1 && m.Col >= r.EC {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// documentedSymbol picks the symbol a comment documents: the one declared
+// right after it (and any comments directly following it), or else the
+// smallest symbol enclosing it, which covers docstrings and trailing
+// comments.
+func documentedSymbol(comms []model.CommentItem, i int, syms []model.SymbolItem) *model.SymbolItem {
+	c := comms[i]
+	if c.Kind != "docstring" {
+		next := c.Range.EL + 1
+		for j := i + 1; j < len(comms); j++ {
+			if comms[j].Range.SL > next {
+				break
+			}
+			if comms[j].Range.SL == next {
+				next = comms[j].Range.EL + 1
+			}
+		}
+		for k := range syms {
+			if syms[k].Range.SL == next {
+				sym := syms[k]
+				return &sym
+			}
+		}
+	}
+
+	return enclosingSymbol(syms, c.Range)
+}
+
+// enclosingSymbol returns the smallest symbol whose lines contain r.
+func enclosingSymbol(syms []model.SymbolItem, r model.Range) *model.SymbolItem {
+	best := -1
+	for k, sym := range syms {
+		if sym.Range.SL > r.SL || sym.Range.EL < r.EL {
+			continue
+		}
+		if best < 0 || sym.Range.EL-sym.Range.SL <= syms[best].Range.EL-syms[best].Range.SL {
+			best = k
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	sym := syms[best]
+	return &sym
+}
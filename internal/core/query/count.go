@@ -0,0 +1,142 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"otterindex/internal/core/search"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+// countFetchN is the first page size Count reads chunks with; it doubles
+// until the store runs out.
+const countFetchN = 1000
+
+// Count counts the lines matching q per file, like grep -c. Unlike Query it
+// reads all matching chunks and neither caps hits per file nor builds units, so no file is read
+// from disk. Limit, Offset, Sort, Unit, AllTerms and Mode are ignored.
+func Count(dbPath string, workspaceID string, q string, opts Options) (model.CountResult, error) {
+	pq, err := prepareQuery(dbPath, workspaceID, q, opts)
+	if err != nil {
+		return model.CountResult{}, err
+	}
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return model.CountResult{}, err
+	}
+	defer s.Close()
+	counts, err := countMatches(s, pq)
+	if err != nil {
+		return model.CountResult{}, err
+	}
+	res := model.CountResult{Files: make([]model.FileCount, 0, len(counts))}
+	for p, n := range counts {
+		res.Files = append(res.Files, model.FileCount{Path: p, Count: n})
+		res.Total += n
+	}
+	sort.Slice(res.Files, func(i, j int) bool { return res.Files[i].Path < res.Files[j].Path })
+	return res, nil
+}
+
+// FilesWithoutMatch lists the indexed files that pass the path filters of
+// opts but have no match of q, by path.
+func FilesWithoutMatch(dbPath string, workspaceID string, q string, opts Options) ([]string, error) {
+	pq, err := prepareQuery(dbPath, workspaceID, q, opts)
+	if err != nil {
+		return nil, err
+	}
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	counts, err := countMatches(s, pq)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.ListFilesMeta(pq.workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for p := range files {
+		if counts[p] == 0 && pq.filter.Match(p) {
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func countMatches(s store.Store, pq preparedQuery) (map[string]int, error) {
+	workspaceID, q, opts, node := pq.workspaceID, pq.q, pq.opts, pq.ast
+
+	var res store.SearchResult
+	var err error
+	for fetchN := countFetchN; ; fetchN *= 2 {
+		searchOpts := store.SearchOptions{
+			Limit:           fetchN,
+			CaseInsensitive: opts.CaseInsensitive,
+			Sort:            store.SortPath,
+			Subword:         opts.Boundary == search.BoundaryIdent,
+			Filter:          pq.filter,
+		}
+		if opts.Regex {
+			res, err = s.SearchChunksRegex(workspaceID, q, searchOpts)
+		} else {
+			res, err = s.SearchChunks(workspaceID, q, searchOpts)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Chunks) < fetchN {
+			break
+		}
+	}
+
+	var re *regexp.Regexp
+	if opts.Regex {
+		re, _ = search.CompileRegex(q, res.MatchCaseInsensitive)
+	}
+	var comments *commentIndex
+	if opts.In != inAll {
+		comments = newCommentIndex(s, workspaceID)
+	}
+	checkNear := node != nil && node.HasNear()
+	hasTerms := node != nil && len(node.Terms()) > 0
+
+	counts := map[string]int{}
+	// Chunks may overlap and terms share lines; a line is counted once.
+	seen := map[string]bool{}
+	for _, c := range res.Chunks {
+		text := c.Text
+		if comments != nil {
+			text = comments.mask(c.Path, c.SL, c.Text, opts.In == inComments)
+		}
+		var matches []model.Match
+		if re != nil {
+			matches = search.FindRegexInText(text, re)
+		} else {
+			if checkNear && !textMatches(node, text, res.MatchCaseInsensitive, opts.Boundary) {
+				continue
+			}
+			matches = findMatchesInChunk(text, q, res.MatchCaseInsensitive, opts.Boundary)
+			if !hasTerms && comments == nil {
+				// Only filters and exclusions: the chunk itself is the hit.
+				matches = []model.Match{{Line: 1, Col: 1}}
+			}
+		}
+		for _, m := range matches {
+			key := fmt.Sprintf("%s:%d", c.Path, c.SL+m.Line-1)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			counts[c.Path]++
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,70 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/index/backend"
+)
+
+func TestCount(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			// Five matching lines in separate chunks, more than a query
+			// returns per file; the line with two hits counts once.
+			var b strings.Builder
+			b.WriteString("package a\n")
+			for i := 0; i < 4; i++ {
+				b.WriteString(strings.Repeat("//\n", 45))
+				b.WriteString("// needle\n")
+			}
+			b.WriteString("// needle needle\n")
+			_ = os.WriteFile(filepath.Join(root, "many.go"), []byte(b.String()), 0o644)
+			_ = os.WriteFile(filepath.Join(root, "one.py"), []byte("# needle\n"), 0o644)
+			_ = os.WriteFile(filepath.Join(root, "none.go"), []byte("package a\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(t.TempDir(), "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			res, err := Count(dbPath, root, "needle", Options{Store: storeName})
+			if err != nil {
+				t.Fatalf("count: %v", err)
+			}
+			if res.Total != 6 || len(res.Files) != 2 {
+				t.Fatalf("count: %+v", res)
+			}
+			if res.Files[0].Path != "many.go" || res.Files[0].Count != 5 || res.Files[1].Path != "one.py" || res.Files[1].Count != 1 {
+				t.Fatalf("files: %+v", res.Files)
+			}
+
+			res, err = Count(dbPath, root, "needle", Options{Store: storeName, Types: []string{"py"}})
+			if err != nil {
+				t.Fatalf("count -t py: %v", err)
+			}
+			if res.Total != 1 || len(res.Files) != 1 || res.Files[0].Path != "one.py" {
+				t.Fatalf("count -t py: %+v", res)
+			}
+
+			without, err := FilesWithoutMatch(dbPath, root, "needle", Options{Store: storeName})
+			if err != nil {
+				t.Fatalf("without: %v", err)
+			}
+			if strings.Join(without, ",") != "none.go" {
+				t.Fatalf("without: %v", without)
+			}
+			without, err = FilesWithoutMatch(dbPath, root, "needle", Options{Store: storeName, Lang: "py"})
+			if err != nil {
+				t.Fatalf("without --lang py: %v", err)
+			}
+			if len(without) != 0 {
+				t.Fatalf("without --lang py: %v", without)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+type DepsOptions struct {
+	Store string
+	// Limit caps RDeps results; zero uses the store default.
+	Limit int
+}
+
+// Deps lists what one indexed file imports, in source order.
+func Deps(dbPath string, workspaceID string, path string, opts DepsOptions) ([]model.DepEdge, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	path = filepath.ToSlash(strings.TrimSpace(path))
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	if _, ok, err := s.GetFileMeta(workspaceID, path); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("file %q is not indexed", path)
+	}
+
+	refs, err := s.ListRefs(workspaceID, path)
+	if err != nil {
+		return nil, err
+	}
+	return depEdges(refs), nil
+}
+
+// RDeps lists the files importing module. An import matches when it equals
+// module or ends with it after a path separator, so "core/query" finds
+// imports of "otterindex/internal/core/query".
+func RDeps(dbPath string, workspaceID string, module string, opts DepsOptions) ([]model.DepEdge, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	module = strings.TrimSpace(module)
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if module == "" {
+		return nil, fmt.Errorf("module is required")
+	}
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	refs, err := s.FindRefs(workspaceID, module, store.RefSearchOptions{Kind: "import", Limit: opts.Limit})
+	if err != nil {
+		return nil, err
+	}
+	return depEdges(refs), nil
+}
+
+// DepGraph returns every import edge in the workspace, ordered by file.
+func DepGraph(dbPath string, workspaceID string, opts DepsOptions) ([]model.DepEdge, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	files, err := s.ListFilesMeta(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var out []model.DepEdge
+	for _, p := range paths {
+		refs, err := s.ListRefs(workspaceID, p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, depEdges(refs)...)
+	}
+	return out, nil
+}
+
+// depEdges keeps the imports among refs, once per file and module.
+func depEdges(refs []model.RefItem) []model.DepEdge {
+	seen := map[string]bool{}
+	var out []model.DepEdge
+	for _, r := range refs {
+		if r.Kind != "import" || r.Name == "" {
+			continue
+		}
+		key := r.Path + "\x00" + r.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, model.DepEdge{Path: r.Path, Module: r.Name, Lang: r.Lang, Range: r.Range})
+	}
+	return out
+}
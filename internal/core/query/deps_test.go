@@ -0,0 +1,79 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+)
+
+func TestDeps(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			_ = os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n"), 0o644)
+			_ = os.WriteFile(filepath.Join(root, "b.go"), []byte("package b\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			st, err := backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			if err := st.ReplaceRefsBatch(root, "a.go", []store.RefInput{
+				{Kind: "import", Name: "fmt", SL: 3, SC: 8, EL: 3, EC: 13, Lang: "go"},
+				{Kind: "import", Name: "example.com/m/internal/core/query", SL: 4, SC: 8, EL: 4, EC: 40, Lang: "go"},
+				{Kind: "call", Name: "Println", SL: 7, SC: 6, EL: 7, EC: 13, Lang: "go"},
+			}); err != nil {
+				t.Fatalf("replace refs: %v", err)
+			}
+			if err := st.ReplaceRefsBatch(root, "b.go", []store.RefInput{
+				{Kind: "import", Name: "fmt", SL: 3, SC: 8, EL: 3, EC: 13, Lang: "go"},
+			}); err != nil {
+				t.Fatalf("replace refs: %v", err)
+			}
+			_ = st.Close()
+
+			opts := DepsOptions{Store: storeName}
+			edges, err := Deps(dbPath, root, "a.go", opts)
+			if err != nil {
+				t.Fatalf("deps: %v", err)
+			}
+			if len(edges) != 2 || edges[0].Module != "fmt" || edges[1].Module != "example.com/m/internal/core/query" {
+				t.Fatalf("bad deps: %+v", edges)
+			}
+			if _, err := Deps(dbPath, root, "missing.go", opts); err == nil {
+				t.Fatalf("expected error for unindexed file")
+			}
+
+			edges, err = RDeps(dbPath, root, "fmt", opts)
+			if err != nil {
+				t.Fatalf("rdeps: %v", err)
+			}
+			if len(edges) != 2 || edges[0].Path != "a.go" || edges[1].Path != "b.go" {
+				t.Fatalf("bad rdeps: %+v", edges)
+			}
+			edges, err = RDeps(dbPath, root, "core/query", opts)
+			if err != nil {
+				t.Fatalf("rdeps: %v", err)
+			}
+			if len(edges) != 1 || edges[0].Path != "a.go" {
+				t.Fatalf("bad rdeps by suffix: %+v", edges)
+			}
+
+			edges, err = DepGraph(dbPath, root, opts)
+			if err != nil {
+				t.Fatalf("graph: %v", err)
+			}
+			if len(edges) != 3 || edges[2].Path != "b.go" {
+				t.Fatalf("bad graph: %+v", edges)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"otterindex/internal/core/fuzzy"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/model"
+)
+
+type FilesOptions struct {
+	Store        string
+	IncludeGlobs []string
+	ExcludeGlobs []string
+	Limit        int
+}
+
+// SearchFiles lists indexed files whose relative path fuzzy-matches pattern
+// (fzf style, best first). An empty pattern lists every file in path order.
+func SearchFiles(dbPath string, workspaceID string, pattern string, opts FilesOptions) ([]model.FileItem, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	pattern = strings.TrimSpace(pattern)
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+	filter, err := newPathFilter(pathSpec{Include: opts.IncludeGlobs, Exclude: opts.ExcludeGlobs})
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	files, err := s.ListFilesMeta(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]model.FileItem, 0, len(files))
+	for p, f := range files {
+		if !filter.Match(p) {
+			continue
+		}
+		item := model.FileItem{Path: p, Size: f.Size, MTime: f.MTime, Hash: f.Hash}
+		if pattern != "" {
+			score, ok := fuzzy.MatchPath(pattern, p)
+			if !ok {
+				continue
+			}
+			item.Score = score
+		}
+		out = append(out, item)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Path < out[j].Path
+	})
+	if len(out) > opts.Limit {
+		out = out[:opts.Limit]
+	}
+	return out, nil
+}
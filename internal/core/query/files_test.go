@@ -0,0 +1,56 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/index/backend"
+)
+
+func TestSearchFiles(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			for _, p := range []string{"cmd/otidx/main.go", "internal/otidxcli/render.go", "internal/otidxcli/render_test.go", "README.md"} {
+				full := filepath.Join(root, filepath.FromSlash(p))
+				_ = os.MkdirAll(filepath.Dir(full), 0o755)
+				_ = os.WriteFile(full, []byte("package x\n"), 0o644)
+			}
+			// Keep the index outside root so it is not listed itself.
+			dbPath := backend.NormalizePath(storeName, filepath.Join(t.TempDir(), "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			files, err := SearchFiles(dbPath, root, "rndr", FilesOptions{Store: storeName})
+			if err != nil {
+				t.Fatalf("files: %v", err)
+			}
+			if len(files) != 2 || files[0].Path != "internal/otidxcli/render.go" || files[1].Path != "internal/otidxcli/render_test.go" {
+				t.Fatalf("bad files: %+v", files)
+			}
+			if files[0].Size != int64(len("package x\n")) || files[0].MTime == 0 || files[0].Score <= files[1].Score {
+				t.Fatalf("bad file meta: %+v", files[0])
+			}
+
+			files, err = SearchFiles(dbPath, root, "", FilesOptions{Store: storeName, ExcludeGlobs: []string{"*_test.go"}})
+			if err != nil {
+				t.Fatalf("files: %v", err)
+			}
+			if len(files) != 3 || files[0].Path != "README.md" || files[1].Path != "cmd/otidx/main.go" {
+				t.Fatalf("bad listing: %+v", files)
+			}
+
+			files, err = SearchFiles(dbPath, root, "main", FilesOptions{Store: storeName, IncludeGlobs: []string{"*.md"}})
+			if err != nil {
+				t.Fatalf("files: %v", err)
+			}
+			if len(files) != 0 {
+				t.Fatalf("glob not applied: %+v", files)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package query
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"otterindex/internal/core/lang"
+	"otterindex/internal/core/search"
+	"otterindex/internal/index/store"
+)
+
+// pathSpec holds the path restrictions shared by the query commands: -g/-x
+// globs, -t/-T types, the directories (or files) in Paths and a --lang name
+// or alias.
+type pathSpec struct {
+	Include  []string
+	Exclude  []string
+	Types    []string
+	TypesNot []string
+	Paths    []string
+	Lang     string
+}
+
+// newPathFilter compiles spec into the expressions stores evaluate. -T types
+// are excluded like -x globs.
+func newPathFilter(spec pathSpec) (store.PathFilter, error) {
+	globs, err := search.CompileGlobs(spec.Include)
+	if err != nil {
+		return store.PathFilter{}, err
+	}
+	excludeGlobs, err := search.CompileGlobs(spec.Exclude)
+	if err != nil {
+		return store.PathFilter{}, err
+	}
+	typeGlobs, err := compileTypeGlobs(spec.Types)
+	if err != nil {
+		return store.PathFilter{}, err
+	}
+	notGlobs, err := compileTypeGlobs(spec.TypesNot)
+	if err != nil {
+		return store.PathFilter{}, err
+	}
+	excludeGlobs = append(excludeGlobs, notGlobs...)
+
+	var include, exclude []string
+	if expr := prefixExpr(spec.Paths); expr != "" {
+		include = append(include, expr)
+	}
+	if len(globs) > 0 {
+		include = append(include, globAlternates(globs))
+	}
+	if len(typeGlobs) > 0 {
+		include = append(include, globAlternates(typeGlobs))
+	}
+	if strings.TrimSpace(spec.Lang) != "" {
+		l, ok := lang.Lookup(spec.Lang)
+		if !ok {
+			return store.PathFilter{}, fmt.Errorf("unknown language %q", spec.Lang)
+		}
+		include = append(include, lang.PathRegexp(l))
+	}
+	if len(excludeGlobs) > 0 {
+		exclude = append(exclude, globAlternates(excludeGlobs))
+	}
+
+	var f store.PathFilter
+	if f.Include, err = compilePathExprs(include); err != nil {
+		return store.PathFilter{}, err
+	}
+	if f.Exclude, err = compilePathExprs(exclude); err != nil {
+		return store.PathFilter{}, err
+	}
+	return f, nil
+}
+
+func compileTypeGlobs(types []string) ([]search.Glob, error) {
+	globs, err := lang.TypeGlobs(types)
+	if err != nil {
+		return nil, err
+	}
+	return search.CompileGlobs(globs)
+}
+
+// prefixExpr matches the paths under any of prefixes (slash-separated
+// directories or files). It is empty when prefixes do not restrict anything.
+func prefixExpr(prefixes []string) string {
+	alts := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		p = path.Clean(strings.ReplaceAll(strings.TrimSpace(p), "\\", "/"))
+		p = strings.TrimPrefix(p, "./")
+		if p == "." {
+			return ""
+		}
+		alts = append(alts, regexp.QuoteMeta(p)+"(?:/.*)?")
+	}
+	if len(alts) == 0 {
+		return ""
+	}
+	return alternate(alts)
+}
+
+func globAlternates(globs []search.Glob) string {
+	alts := make([]string, 0, len(globs))
+	for _, g := range globs {
+		alts = append(alts, g.Regexp())
+	}
+	return alternate(alts)
+}
+
+func alternate(alts []string) string {
+	return "(?:" + strings.Join(alts, "|") + ")"
+}
+
+func compilePathExprs(exprs []string) ([]store.PathExpr, error) {
+	var out []store.PathExpr
+	for _, expr := range exprs {
+		e, err := store.CompilePathExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// keepPaths drops candidates the filter rejects, for sources that do not
+// filter in the store.
+func keepPaths(cands []candidateRow, f store.PathFilter) []candidateRow {
+	if f.IsZero() {
+		return cands
+	}
+	out := cands[:0]
+	for _, c := range cands {
+		if f.Match(c.Path) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
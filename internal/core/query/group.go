@@ -0,0 +1,51 @@
+package query
+
+import "otterindex/internal/model"
+
+// QueryGroups runs q like Query and groups the results by file, reporting
+// how many more hits of each file the per-file cap dropped. opts.Limit and
+// opts.Offset count groups, not hits.
+func QueryGroups(dbPath string, workspaceID string, q string, opts Options) ([]model.FileGroup, error) {
+	pq, err := prepareQuery(dbPath, workspaceID, q, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts = pq.opts
+	// Fetch enough hits to fill every wanted group.
+	hitOpts := opts
+	hitOpts.Offset, hitOpts.Limit = 0, maxPageCount
+	if n := (opts.Offset + opts.Limit) * maxPerFile(opts); n > hitOpts.Limit {
+		hitOpts.Limit = n
+	}
+	items, info, err := queryWithInfo(dbPath, workspaceID, q, hitOpts, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := GroupByFile(items, info.truncated)
+	if opts.Offset >= len(groups) {
+		return []model.FileGroup{}, nil
+	}
+	groups = groups[opts.Offset:]
+	if len(groups) > opts.Limit {
+		groups = groups[:opts.Limit]
+	}
+	return groups, nil
+}
+
+// GroupByFile groups items by path, in the order each path first appears.
+// truncated (may be nil) gives the dropped hit count per path.
+func GroupByFile(items []model.ResultItem, truncated map[string]int) []model.FileGroup {
+	groups := []model.FileGroup{}
+	index := map[string]int{}
+	for _, it := range items {
+		i, ok := index[it.Path]
+		if !ok {
+			i = len(groups)
+			index[it.Path] = i
+			groups = append(groups, model.FileGroup{Path: it.Path, Truncated: truncated[it.Path]})
+		}
+		groups[i].Items = append(groups[i].Items, it)
+	}
+	return groups
+}
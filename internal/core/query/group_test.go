@@ -0,0 +1,104 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/index/backend"
+)
+
+func TestQuery_MaxPerFileAndGroups(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			// Six hits in separate chunks of one file, one hit in another.
+			var b strings.Builder
+			b.WriteString("package a\n")
+			for i := 1; i <= 6; i++ {
+				b.WriteString(strings.Repeat("//\n", 45))
+				_, _ = fmt.Fprintf(&b, "func f%d() {} // needle\n", i)
+			}
+			_ = os.WriteFile(filepath.Join(root, "many.go"), []byte(b.String()), 0o644)
+			_ = os.WriteFile(filepath.Join(root, "one.go"), []byte("package a\n\nfunc needle() {}\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			count := func(opts Options) int {
+				items, err := Query(dbPath, root, "needle", opts)
+				if err != nil {
+					t.Fatalf("query: %v", err)
+				}
+				n := 0
+				for _, it := range items {
+					if it.Path == "many.go" {
+						n++
+					}
+				}
+				return n
+			}
+			opts := Options{Store: storeName, Unit: "line", Sort: "path", Limit: 50}
+			if n := count(opts); n != DefaultMaxPerFile {
+				t.Fatalf("default cap: got %d hits, want %d", n, DefaultMaxPerFile)
+			}
+			opts.MaxPerFile = 5
+			if n := count(opts); n != 5 {
+				t.Fatalf("cap 5: got %d hits", n)
+			}
+			opts.MaxPerFile = -1
+			if n := count(opts); n != 6 {
+				t.Fatalf("no cap: got %d hits", n)
+			}
+
+			opts.MaxPerFile = 2
+			groups, err := QueryGroups(dbPath, root, "needle", opts)
+			if err != nil {
+				t.Fatalf("groups: %v", err)
+			}
+			if len(groups) != 2 || groups[0].Path != "many.go" || groups[1].Path != "one.go" {
+				t.Fatalf("groups: %+v", groups)
+			}
+			if len(groups[0].Items) != 2 || groups[0].Truncated != 4 {
+				t.Fatalf("many.go: %d items, truncated=%d", len(groups[0].Items), groups[0].Truncated)
+			}
+			if len(groups[1].Items) != 1 || groups[1].Truncated != 0 {
+				t.Fatalf("one.go: %d items, truncated=%d", len(groups[1].Items), groups[1].Truncated)
+			}
+
+			// The limit and offset count groups.
+			opts.Limit = 2
+			groups, err = QueryGroups(dbPath, root, "needle", opts)
+			if err != nil {
+				t.Fatalf("groups: %v", err)
+			}
+			if len(groups) != 2 || len(groups[0].Items) != 2 || groups[0].Truncated != 4 {
+				t.Fatalf("limit 2: %+v", groups)
+			}
+			opts.Limit, opts.Offset = 1, 1
+			groups, err = QueryGroups(dbPath, root, "needle", opts)
+			if err != nil {
+				t.Fatalf("groups: %v", err)
+			}
+			if len(groups) != 1 || groups[0].Path != "one.go" {
+				t.Fatalf("offset 1: %+v", groups)
+			}
+
+			// A page reports the hits the cap dropped, including those past
+			// the page.
+			opts.Limit, opts.Offset = 1, 0
+			page, err := QueryPage(nil, dbPath, root, "needle", opts, "")
+			if err != nil {
+				t.Fatalf("page: %v", err)
+			}
+			if len(page.Items) != 1 || page.Truncated["many.go"] != 4 || len(page.Truncated) != 1 {
+				t.Fatalf("page truncated: %+v", page.Truncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package query
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"otterindex/internal/index/backend"
+	"otterindex/internal/model"
+)
+
+type OutlineOptions struct {
+	Store string
+}
+
+// Outline returns the symbol tree of one indexed file.
+func Outline(dbPath string, workspaceID string, path string, opts OutlineOptions) ([]model.OutlineNode, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	path = filepath.ToSlash(strings.TrimSpace(path))
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	if _, ok, err := s.GetFileMeta(workspaceID, path); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("file %q is not indexed", path)
+	}
+
+	syms, err := s.ListSymbols(workspaceID, path)
+	if err != nil {
+		return nil, err
+	}
+	return buildOutline(syms), nil
+}
+
+// buildOutline nests symbols by Container (a Go method under its receiver
+// type, even when declared elsewhere in the file) and otherwise by range
+// containment. syms must be sorted by start position.
+func buildOutline(syms []model.SymbolItem) []model.OutlineNode {
+	parent := make([]int, len(syms))
+	var stack []int
+	for i, sym := range syms {
+		for len(stack) > 0 && !rangeContains(syms[stack[len(stack)-1]].Range, sym.Range) {
+			stack = stack[:len(stack)-1]
+		}
+		parent[i] = -1
+		if len(stack) > 0 {
+			parent[i] = stack[len(stack)-1]
+		}
+		stack = append(stack, i)
+	}
+
+	byName := map[string]int{}
+	for i, sym := range syms {
+		if _, ok := byName[sym.Name]; !ok && sym.Name != "" {
+			byName[sym.Name] = i
+		}
+	}
+	for i, sym := range syms {
+		key := containerKey(sym.Container)
+		if key == "" {
+			continue
+		}
+		p, ok := byName[key]
+		if !ok || p == i || p == parent[i] || isAncestor(parent, i, p) {
+			continue
+		}
+		if parent[i] >= 0 && syms[parent[i]].Name == key {
+			continue
+		}
+		parent[i] = p
+	}
+
+	children := make([][]int, len(syms))
+	var roots []int
+	for i := range syms {
+		if parent[i] < 0 {
+			roots = append(roots, i)
+			continue
+		}
+		children[parent[i]] = append(children[parent[i]], i)
+	}
+
+	var build func(idx []int) []model.OutlineNode
+	build = func(idx []int) []model.OutlineNode {
+		if len(idx) == 0 {
+			return nil
+		}
+		out := make([]model.OutlineNode, 0, len(idx))
+		for _, i := range idx {
+			out = append(out, model.OutlineNode{SymbolItem: syms[i], Children: build(children[i])})
+		}
+		return out
+	}
+	return build(roots)
+}
+
+func rangeContains(outer model.Range, inner model.Range) bool {
+	if inner.SL < outer.SL || inner.EL > outer.EL {
+		return false
+	}
+	if inner.SL == outer.SL && inner.SC < outer.SC {
+		return false
+	}
+	if inner.EL == outer.EL && inner.EC > outer.EC {
+		return false
+	}
+	return true
+}
+
+// isAncestor reports whether a is an ancestor of (or equal to) n.
+func isAncestor(parent []int, a int, n int) bool {
+	for n >= 0 {
+		if n == a {
+			return true
+		}
+		n = parent[n]
+	}
+	return false
+}
+
+// containerKey reduces "*pkg.Outer[T]" or "Outer::Inner" to the bare name of
+// the innermost type.
+func containerKey(c string) string {
+	c = strings.TrimSpace(strings.TrimLeft(c, "*&"))
+	if i := strings.IndexAny(c, "[<("); i >= 0 {
+		c = c[:i]
+	}
+	c = strings.ReplaceAll(c, "::", ".")
+	if i := strings.LastIndex(c, "."); i >= 0 {
+		c = c[i+1:]
+	}
+	return strings.TrimSpace(c)
+}
@@ -0,0 +1,124 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+func outlineShape(nodes []model.OutlineNode) string {
+	var parts []string
+	for _, n := range nodes {
+		s := n.Name
+		if len(n.Children) > 0 {
+			s += "(" + outlineShape(n.Children) + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestBuildOutline(t *testing.T) {
+	// Go: methods sit outside the struct's range and nest via Container.
+	goSyms := []model.SymbolItem{
+		{Kind: "type", Name: "Server", Range: model.Range{SL: 3, SC: 1, EL: 6, EC: 2}},
+		{Kind: "field", Name: "addr", Container: "Server", Range: model.Range{SL: 4, SC: 2, EL: 4, EC: 13}},
+		{Kind: "function", Name: "New", Range: model.Range{SL: 8, SC: 1, EL: 10, EC: 2}},
+		{Kind: "method", Name: "Start", Container: "*Server", Range: model.Range{SL: 12, SC: 1, EL: 14, EC: 2}},
+		{Kind: "method", Name: "Stop", Container: "Server[T]", Range: model.Range{SL: 16, SC: 1, EL: 18, EC: 2}},
+		{Kind: "method", Name: "Other", Container: "Missing", Range: model.Range{SL: 20, SC: 1, EL: 22, EC: 2}},
+	}
+	if got := outlineShape(buildOutline(goSyms)); got != "Server(addr Start Stop) New Other" {
+		t.Fatalf("go outline: %s", got)
+	}
+
+	// Java-style: nested classes and methods nest by range.
+	javaSyms := []model.SymbolItem{
+		{Kind: "class", Name: "Outer", Range: model.Range{SL: 1, SC: 1, EL: 20, EC: 2}},
+		{Kind: "method", Name: "run", Container: "Outer", Range: model.Range{SL: 2, SC: 3, EL: 4, EC: 4}},
+		{Kind: "class", Name: "Inner", Container: "Outer", Range: model.Range{SL: 6, SC: 3, EL: 12, EC: 4}},
+		{Kind: "method", Name: "run", Container: "Outer.Inner", Range: model.Range{SL: 7, SC: 5, EL: 9, EC: 6}},
+	}
+	if got := outlineShape(buildOutline(javaSyms)); got != "Outer(run Inner(run))" {
+		t.Fatalf("java outline: %s", got)
+	}
+}
+
+func TestOutline(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			_ = os.WriteFile(filepath.Join(root, "server.go"), []byte("package srv\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			st, err := backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			if err := st.ReplaceSymbolsBatch(root, "server.go", []store.SymbolInput{
+				{Kind: "type", Name: "Server", SL: 3, SC: 1, EL: 6, EC: 2, Lang: "go"},
+				{Kind: "method", Name: "Start", Container: "Server", SL: 12, SC: 1, EL: 14, EC: 2, Lang: "go"},
+				{Kind: "function", Name: "New", SL: 8, SC: 1, EL: 10, EC: 2, Lang: "go"},
+			}); err != nil {
+				t.Fatalf("replace symbols: %v", err)
+			}
+			_ = st.Close()
+
+			nodes, err := Outline(dbPath, root, "server.go", OutlineOptions{Store: storeName})
+			if err != nil {
+				t.Fatalf("outline: %v", err)
+			}
+			if got := outlineShape(nodes); got != "Server(Start) New" {
+				t.Fatalf("outline: %s", got)
+			}
+			if nodes[0].Children[0].Path != "server.go" || nodes[0].Children[0].Range.SL != 12 {
+				t.Fatalf("bad child: %+v", nodes[0].Children[0])
+			}
+
+			if _, err := Outline(dbPath, root, "missing.go", OutlineOptions{Store: storeName}); err == nil {
+				t.Fatalf("expected error for unindexed file")
+			}
+		})
+	}
+}
+
+func TestOutline_Markdown(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			src := "---\ntitle: Guide\n---\n# Guide\n\nIntro.\n\n## Install\n\nRun the installer.\n\n```sh\nmake install\n```\n\n## Usage\n\nCall otidx.\n"
+			_ = os.WriteFile(filepath.Join(root, "guide.md"), []byte(src), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(t.TempDir(), "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			nodes, err := Outline(dbPath, root, "guide.md", OutlineOptions{Store: storeName})
+			if err != nil {
+				t.Fatalf("outline: %v", err)
+			}
+			if got := outlineShape(nodes); got != "frontmatter(title) Guide(Install(bash) Usage)" {
+				t.Fatalf("outline: %s", got)
+			}
+
+			results, err := Query(dbPath, root, "installer", Options{Store: storeName, Unit: "symbol"})
+			if err != nil {
+				t.Fatalf("query: %v", err)
+			}
+			if len(results) != 1 || results[0].Range.SL != 8 || results[0].Range.EL != 14 {
+				t.Fatalf("expected the Install section, got %+v", results)
+			}
+		})
+	}
+}
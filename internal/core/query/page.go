@@ -0,0 +1,216 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"sort"
+	"strings"
+
+	"otterindex/internal/core/cache"
+	"otterindex/internal/core/lang"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/model"
+)
+
+const (
+	// maxPageCount caps how many results QueryPage counts for Total and the
+	// facets; beyond it Total is a lower bound.
+	maxPageCount = 1000
+	// maxFacetValues caps each facet list.
+	maxFacetValues = 20
+)
+
+// PageCache keeps the counted results of recent QueryPage calls so that
+// following pages are sliced from memory instead of searching again.
+type PageCache struct {
+	lru *cache.LRU
+}
+
+func NewPageCache(size int) *PageCache {
+	if size <= 0 {
+		size = 1
+	}
+	return &PageCache{lru: cache.NewLRU(size)}
+}
+
+type pageEntry struct {
+	all       []model.ResultItem
+	exact     bool
+	facets    model.Facets
+	truncated map[string]int
+}
+
+type pageCursor struct {
+	Key     string `json:"k"`
+	Version int64  `json:"v"`
+	Offset  int    `json:"o"`
+}
+
+// QueryPage runs q like Query and returns one page with the total and facets
+// of all results. A cursor from a previous page continues after it (and
+// overrides opts.Offset); it fails once the index has changed. c may be nil.
+func QueryPage(c *PageCache, dbPath string, workspaceID string, q string, opts Options, cursor string) (model.ResultPage, error) {
+	pq, err := prepareQuery(dbPath, workspaceID, q, opts)
+	if err != nil {
+		return model.ResultPage{}, err
+	}
+	workspaceID, opts = pq.workspaceID, pq.opts
+	q = strings.TrimSpace(q)
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return model.ResultPage{}, err
+	}
+	ver, err := s.GetVersion(workspaceID)
+	ws, _ := s.GetWorkspace(workspaceID)
+	_ = s.Close()
+	if err != nil {
+		return model.ResultPage{}, err
+	}
+
+	keyOpts := opts
+	keyOpts.Limit, keyOpts.Offset = 0, 0
+	key := pageKey(makeCacheKey(workspaceID, 0, q, keyOpts))
+	if cursor != "" {
+		cur, err := decodeCursor(cursor)
+		if err != nil {
+			return model.ResultPage{}, err
+		}
+		if cur.Key != key {
+			return model.ResultPage{}, fmt.Errorf("cursor belongs to a different query")
+		}
+		if cur.Version != ver {
+			return model.ResultPage{}, fmt.Errorf("cursor is stale (the index changed); rerun the query")
+		}
+		opts.Offset = cur.Offset
+	}
+
+	var entry pageEntry
+	var items []model.ResultItem
+	lruKey := fmt.Sprintf("%s|ver=%d", key, ver)
+	cached := false
+	if c != nil && c.lru != nil {
+		if v, ok := c.lru.Get(lruKey); ok {
+			entry, cached = v.(pageEntry)
+		}
+	}
+	if cached && (entry.exact || opts.Offset+opts.Limit <= len(entry.all)) {
+		if ex := opts.Explain; ex != nil {
+			ex.KV("cache_hit", "page")
+		}
+		items = cloneResultItems(sliceLimitOffset(entry.all, opts.Offset, opts.Limit, opts.Explain))
+		if err := refinePage(dbPath, workspaceID, ws.Root, items, opts); err != nil {
+			return model.ResultPage{}, err
+		}
+	} else {
+		countN := maxPageCount
+		if n := opts.Offset + opts.Limit; n > countN {
+			countN = n
+		}
+		var info queryInfo
+		items, info, err = queryWithInfo(dbPath, workspaceID, q, opts, 0, countN)
+		if err != nil {
+			return model.ResultPage{}, err
+		}
+		entry = pageEntry{all: info.all, exact: info.allExact, facets: countFacets(info.all), truncated: info.truncated}
+		if c != nil && c.lru != nil {
+			c.lru.Put(lruKey, entry)
+		}
+	}
+
+	page := model.ResultPage{
+		Items:      items,
+		Total:      len(entry.all),
+		TotalExact: entry.exact,
+		Facets:     entry.facets,
+	}
+	if page.Items == nil {
+		page.Items = []model.ResultItem{}
+	}
+	for _, it := range items {
+		if n := entry.truncated[it.Path]; n > 0 {
+			if page.Truncated == nil {
+				page.Truncated = map[string]int{}
+			}
+			page.Truncated[it.Path] = n
+		}
+	}
+	next := opts.Offset + len(items)
+	if len(items) > 0 && (next < len(entry.all) || !entry.exact) {
+		page.Cursor = encodeCursor(pageCursor{Key: key, Version: ver, Offset: next})
+	}
+	return page, nil
+}
+
+// refinePage refines cached items like queryWithInfo does; only symbol units
+// need the store.
+func refinePage(dbPath string, workspaceID string, root string, items []model.ResultItem, opts Options) error {
+	if opts.Unit != "symbol" {
+		refineItems(nil, workspaceID, root, items, opts.Unit, opts.Explain)
+		return nil
+	}
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	refineItems(s, workspaceID, root, items, opts.Unit, opts.Explain)
+	return nil
+}
+
+func pageKey(cacheKey string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(cacheKey))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+func encodeCursor(c pageCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || json.Unmarshal(b, &c) != nil || c.Key == "" || c.Offset < 0 {
+		return pageCursor{}, fmt.Errorf("invalid cursor")
+	}
+	return c, nil
+}
+
+// countFacets counts items by parent directory, extension and language.
+func countFacets(items []model.ResultItem) model.Facets {
+	dirs := map[string]int{}
+	exts := map[string]int{}
+	langs := map[string]int{}
+	for _, it := range items {
+		dirs[path.Dir(it.Path)]++
+		if ext := strings.ToLower(path.Ext(it.Path)); ext != "" {
+			exts[ext]++
+		}
+		if l := lang.FromPath(it.Path); l != "" {
+			langs[l]++
+		}
+	}
+	return model.Facets{Dirs: topFacets(dirs), Exts: topFacets(exts), Langs: topFacets(langs)}
+}
+
+func topFacets(counts map[string]int) []model.FacetCount {
+	out := make([]model.FacetCount, 0, len(counts))
+	for v, n := range counts {
+		out = append(out, model.FacetCount{Value: v, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Value < out[j].Value
+	})
+	if len(out) > maxFacetValues {
+		out = out[:maxFacetValues]
+	}
+	return out
+}
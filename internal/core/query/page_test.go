@@ -0,0 +1,150 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/model"
+)
+
+func TestQueryPage(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			write := func(rel string, src string) {
+				p := filepath.Join(root, filepath.FromSlash(rel))
+				_ = os.MkdirAll(filepath.Dir(p), 0o755)
+				_ = os.WriteFile(p, []byte(src), 0o644)
+			}
+			for i := 0; i < 7; i++ {
+				write(fmt.Sprintf("src/f%d.go", i), "package src\n\n// needle\n")
+			}
+			write("tools/gen.py", "# needle\n")
+			write("tools/gen2.py", "# needle\n")
+			write("README", "needle\n")
+			dbPath := backend.NormalizePath(storeName, filepath.Join(t.TempDir(), "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			opts := Options{Store: storeName, Unit: "line", Sort: "path", Limit: 4}
+			all, err := Query(dbPath, root, "needle", Options{Store: storeName, Unit: "line", Sort: "path", Limit: 100})
+			if err != nil {
+				t.Fatalf("query: %v", err)
+			}
+
+			c := NewPageCache(4)
+			var paged []model.ResultItem
+			cursor := ""
+			for i := 0; ; i++ {
+				page, err := QueryPage(c, dbPath, root, "needle", opts, cursor)
+				if err != nil {
+					t.Fatalf("page %d: %v", i, err)
+				}
+				if page.Total != 10 || !page.TotalExact {
+					t.Fatalf("page %d: total=%d exact=%v", i, page.Total, page.TotalExact)
+				}
+				paged = append(paged, page.Items...)
+				if page.Cursor == "" {
+					break
+				}
+				cursor = page.Cursor
+			}
+			if got, want := itemPaths(paged), itemPaths(all); got != want {
+				t.Fatalf("paged %q, want %q", got, want)
+			}
+
+			page, err := QueryPage(nil, dbPath, root, "needle", opts, "")
+			if err != nil {
+				t.Fatalf("page: %v", err)
+			}
+			f := page.Facets
+			if len(f.Langs) != 2 || f.Langs[0] != (model.FacetCount{Value: "go", Count: 7}) || f.Langs[1] != (model.FacetCount{Value: "python", Count: 2}) {
+				t.Fatalf("langs facet: %+v", f.Langs)
+			}
+			if len(f.Dirs) != 3 || f.Dirs[0].Value != "src" || f.Dirs[2] != (model.FacetCount{Value: ".", Count: 1}) {
+				t.Fatalf("dirs facet: %+v", f.Dirs)
+			}
+			if len(f.Exts) != 2 || f.Exts[0].Value != ".go" {
+				t.Fatalf("exts facet: %+v", f.Exts)
+			}
+
+			if _, err := QueryPage(nil, dbPath, root, "other", opts, page.Cursor); err == nil {
+				t.Fatalf("expected error for a cursor of another query")
+			}
+			if _, err := QueryPage(nil, dbPath, root, "needle", opts, "bogus"); err == nil {
+				t.Fatalf("expected invalid cursor error")
+			}
+			write("src/new.go", "package src\n\n// needle\n")
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("rebuild: %v", err)
+			}
+			if _, err := QueryPage(c, dbPath, root, "needle", opts, page.Cursor); err == nil || !strings.Contains(err.Error(), "stale") {
+				t.Fatalf("expected stale cursor error, got %v", err)
+			}
+		})
+	}
+}
+
+func itemPaths(items []model.ResultItem) string {
+	var out []string
+	for _, it := range items {
+		out = append(out, fmt.Sprintf("%s:%d", it.Path, it.Range.SL))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestQueryPage_RanksLikeQuery(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			for i := 0; i < 150; i++ {
+				var b strings.Builder
+				fmt.Fprintf(&b, "package p%03d\n\nfunc run%03d() {\n", i, i)
+				for j := 0; j <= i%7; j++ {
+					b.WriteString("\twidget()\n")
+				}
+				b.WriteString("}\n")
+				p := filepath.Join(root, fmt.Sprintf("p%03d", i), "a.go")
+				_ = os.MkdirAll(filepath.Dir(p), 0o755)
+				_ = os.WriteFile(p, []byte(b.String()), 0o644)
+			}
+			_ = os.MkdirAll(filepath.Join(root, "zzz"), 0o755)
+			_ = os.WriteFile(filepath.Join(root, "zzz", "widget.go"), []byte("package zzz\n\nfunc widget() {}\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(t.TempDir(), "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			const n = 5
+			opts := Options{Store: storeName, Unit: "line", Limit: n}
+			want := Options{Store: storeName, Unit: "line", Limit: 2 * n}
+			all, err := Query(dbPath, root, "widget", want)
+			if err != nil {
+				t.Fatalf("query: %v", err)
+			}
+			if len(all) != 2*n {
+				t.Fatalf("expected %d results, got %d", 2*n, len(all))
+			}
+
+			first, err := QueryPage(nil, dbPath, root, "widget", opts, "")
+			if err != nil {
+				t.Fatalf("page 1: %v", err)
+			}
+			second, err := QueryPage(nil, dbPath, root, "widget", opts, first.Cursor)
+			if err != nil {
+				t.Fatalf("page 2: %v", err)
+			}
+			if got := itemPaths(append(first.Items, second.Items...)); got != itemPaths(all) {
+				t.Fatalf("pages %q, want %q", got, itemPaths(all))
+			}
+		})
+	}
+}
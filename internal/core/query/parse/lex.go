@@ -0,0 +1,168 @@
+package parse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokKind int
+
+const (
+	tokWord tokKind = iota
+	tokPhrase
+	tokField
+	tokOr
+	tokAnd
+	tokNeg
+	tokLParen
+	tokRParen
+	tokNear
+)
+
+type token struct {
+	kind  tokKind
+	field string
+	text  string
+	dist  int
+}
+
+func lex(q string) ([]token, error) {
+	var toks []token
+	depth := 0
+	i := 0
+	for i < len(q) {
+		r, size := utf8.DecodeRuneInString(q[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			toks = append(toks, token{kind: tokLParen, text: "("})
+			depth++
+			i += size
+		case r == ')' && depth > 0:
+			toks = append(toks, token{kind: tokRParen, text: ")"})
+			depth--
+			i += size
+		case r == '-' && i+1 < len(q) && !isSpaceByte(q[i+1]):
+			toks = append(toks, token{kind: tokNeg, text: "-"})
+			i += size
+		case r == '"':
+			text, n, err := lexPhrase(q[i:])
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{kind: tokPhrase, text: text})
+			i += n
+		default:
+			word, n := lexWord(q[i:], depth > 0)
+			i += n
+			if word == "OR" {
+				toks = append(toks, token{kind: tokOr, text: word})
+				break
+			}
+			if word == "AND" {
+				toks = append(toks, token{kind: tokAnd, text: word})
+				break
+			}
+			if dist, ok, err := lexNear(word); ok {
+				if err != nil {
+					return nil, err
+				}
+				toks = append(toks, token{kind: tokNear, text: word, dist: dist})
+				break
+			}
+			if name, value, ok := strings.Cut(word, ":"); ok && fields[name] {
+				if value == "" && i < len(q) && q[i] == '"' {
+					text, n, err := lexPhrase(q[i:])
+					if err != nil {
+						return nil, err
+					}
+					value = text
+					i += n
+				}
+				value = strings.TrimSpace(value)
+				if value == "" {
+					return nil, fmt.Errorf("%s: needs a value", name)
+				}
+				toks = append(toks, token{kind: tokField, field: name, text: value})
+				break
+			}
+			toks = append(toks, token{kind: tokWord, text: word})
+		}
+	}
+	return toks, nil
+}
+
+// lexNear recognizes NEAR and NEAR/n.
+func lexNear(word string) (int, bool, error) {
+	if word == "NEAR" {
+		return DefaultNear, true, nil
+	}
+	v, ok := strings.CutPrefix(word, "NEAR/")
+	if !ok {
+		return 0, false, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, true, fmt.Errorf("invalid %s (expected NEAR/<lines>)", word)
+	}
+	return n, true, nil
+}
+
+// lexPhrase reads a double-quoted phrase starting at s[0]; \" escapes a quote.
+func lexPhrase(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) && s[i+1] == '"' {
+				b.WriteByte('"')
+				i++
+				continue
+			}
+			b.WriteByte('\\')
+		case '"':
+			return strings.TrimSpace(b.String()), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated phrase")
+}
+
+// lexWord reads up to the next space. Inside a group a ')' that does not close
+// a '(' from the same word ends the word, so "(foo OR bar)" groups while
+// "Open()" stays a single term.
+func lexWord(s string, inGroup bool) (string, int) {
+	open := 0
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if unicode.IsSpace(r) {
+			break
+		}
+		if r == '(' {
+			open++
+		}
+		if r == ')' {
+			if open == 0 && inGroup {
+				break
+			}
+			if open > 0 {
+				open--
+			}
+		}
+		if r == '"' && i > 0 && s[i-1] == ':' {
+			break
+		}
+		i += size
+	}
+	return s[:i], i
+}
+
+func isSpaceByte(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
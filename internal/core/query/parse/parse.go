@@ -0,0 +1,413 @@
+// Package parse implements the backend-neutral query language shared by the
+// SQLite and Bleve stores.
+//
+//	foo bar           both terms (implicit AND)
+//	"exact phrase"    tokens in sequence
+//	-foo              exclude
+//	foo OR bar        either side
+//	(foo OR bar) baz  grouping
+//	foo bar NEAR/5    all terms within 5 lines of each other (also foo NEAR/5 bar)
+//	path:internal/    field filters: path, lang, kind, sym
+package parse
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type Kind int
+
+const (
+	Term Kind = iota
+	Phrase
+	Field
+	And
+	Or
+	Not
+	// Near holds terms and phrases that must all occur within Dist lines.
+	Near
+)
+
+// DefaultNear is the line distance of a bare NEAR.
+const DefaultNear = 10
+
+const (
+	FieldPath = "path"
+	FieldLang = "lang"
+	FieldKind = "kind"
+	FieldSym  = "sym"
+)
+
+var fields = map[string]bool{
+	FieldPath: true,
+	FieldLang: true,
+	FieldKind: true,
+	FieldSym:  true,
+}
+
+type Node struct {
+	Kind     Kind
+	Field    string
+	Value    string
+	Dist     int
+	Children []*Node
+}
+
+func Parse(q string) (*Node, error) {
+	toks, err := lex(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		t := p.toks[p.pos]
+		if t.kind == tokRParen {
+			return nil, fmt.Errorf("unbalanced ')'")
+		}
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+	if n == nil {
+		return nil, fmt.Errorf("query has no searchable terms")
+	}
+	return n, nil
+}
+
+// IsText reports whether n only constrains chunk text (no field filters).
+func (n *Node) IsText() bool {
+	switch n.Kind {
+	case Term, Phrase:
+		return true
+	case Field:
+		return false
+	default:
+		for _, c := range n.Children {
+			if !c.IsText() {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Terms returns the text values a matching chunk is expected to contain,
+// skipping anything under an exclusion. Used for highlighting.
+func (n *Node) Terms() []string {
+	var out []string
+	seen := map[string]bool{}
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		switch n.Kind {
+		case Term, Phrase:
+			if !seen[n.Value] {
+				seen[n.Value] = true
+				out = append(out, n.Value)
+			}
+		case And, Or, Near:
+			for _, c := range n.Children {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return out
+}
+
+// HasNear reports whether a NEAR group appears anywhere in n.
+func (n *Node) HasNear() bool {
+	if n.Kind == Near {
+		return true
+	}
+	for _, c := range n.Children {
+		if c.HasNear() {
+			return true
+		}
+	}
+	return false
+}
+
+// SimpleTerms returns the terms of a query that is a plain conjunction of
+// terms and phrases, or false if it uses OR, exclusions or field filters.
+func (n *Node) SimpleTerms() ([]string, bool) {
+	switch n.Kind {
+	case Term, Phrase:
+		return []string{n.Value}, true
+	case And:
+		var out []string
+		for _, c := range n.Children {
+			if c.Kind != Term && c.Kind != Phrase {
+				return nil, false
+			}
+			out = append(out, c.Value)
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+func (n *Node) String() string {
+	switch n.Kind {
+	case Term:
+		return n.Value
+	case Phrase:
+		return quote(n.Value)
+	case Field:
+		if strings.ContainsAny(n.Value, " \t\"") {
+			return n.Field + ":" + quote(n.Value)
+		}
+		return n.Field + ":" + n.Value
+	case Not:
+		return "-" + n.Children[0].String()
+	case And, Or:
+		parts := make([]string, len(n.Children))
+		for i, c := range n.Children {
+			parts[i] = c.String()
+		}
+		sep := " "
+		if n.Kind == Or {
+			sep = " OR "
+		}
+		return "(" + strings.Join(parts, sep) + ")"
+	case Near:
+		parts := make([]string, len(n.Children))
+		for i, c := range n.Children {
+			parts[i] = c.String()
+		}
+		return fmt.Sprintf("(%s NEAR/%d)", strings.Join(parts, " "), n.Dist)
+	default:
+		return ""
+	}
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// Searchable reports whether s contains at least one letter or digit, i.e.
+// whether it produces any token in the full-text indexes.
+func Searchable(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *parser) parseOr() (*Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []*Node{}
+	if left != nil {
+		children = append(children, left)
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokOr {
+			break
+		}
+		p.pos++
+		if left == nil {
+			return nil, fmt.Errorf("OR needs a term on its left")
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if right == nil {
+			return nil, fmt.Errorf("OR needs a term on its right")
+		}
+		children = append(children, right)
+	}
+	switch len(children) {
+	case 0:
+		return nil, nil
+	case 1:
+		return children[0], nil
+	default:
+		return &Node{Kind: Or, Children: flatten(Or, children)}, nil
+	}
+}
+
+func (p *parser) parseAnd() (*Node, error) {
+	var children []*Node
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokOr || t.kind == tokRParen {
+			break
+		}
+		if t.kind == tokAnd {
+			p.pos++
+			continue
+		}
+		if t.kind == tokNear {
+			p.pos++
+			var err error
+			children, err = p.parseNear(children, t.dist)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			children = append(children, n)
+		}
+	}
+	switch len(children) {
+	case 0:
+		return nil, nil
+	case 1:
+		return children[0], nil
+	default:
+		return &Node{Kind: And, Children: flatten(And, children)}, nil
+	}
+}
+
+// parseNear handles a NEAR/n after children. Between two terms it joins the
+// term before and the one after ("a NEAR/5 b"); at the end of a group it
+// joins every term before it ("a b NEAR/5").
+func (p *parser) parseNear(children []*Node, dist int) ([]*Node, error) {
+	if len(children) == 0 {
+		return nil, fmt.Errorf("NEAR needs terms on its left")
+	}
+	if t, ok := p.peek(); ok && t.kind != tokOr && t.kind != tokRParen && t.kind != tokNear {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if right == nil {
+			return children, nil
+		}
+		last := len(children) - 1
+		n, err := nearNode([]*Node{children[last], right}, dist)
+		if err != nil {
+			return nil, err
+		}
+		return append(children[:last], n), nil
+	}
+	n, err := nearNode(children, dist)
+	if err != nil {
+		return nil, err
+	}
+	return []*Node{n}, nil
+}
+
+func nearNode(children []*Node, dist int) (*Node, error) {
+	var terms []*Node
+	for _, c := range children {
+		switch {
+		case c.Kind == Term || c.Kind == Phrase:
+			terms = append(terms, c)
+		case c.Kind == Near && c.Dist == dist:
+			terms = append(terms, c.Children...)
+		default:
+			return nil, fmt.Errorf("NEAR only joins terms and phrases")
+		}
+	}
+	if len(terms) < 2 {
+		return nil, fmt.Errorf("NEAR needs at least two terms")
+	}
+	return &Node{Kind: Near, Dist: dist, Children: terms}, nil
+}
+
+func (p *parser) parseUnary() (*Node, error) {
+	t, _ := p.peek()
+	if t.kind != tokNeg {
+		return p.parsePrimary()
+	}
+	p.pos++
+	if _, ok := p.peek(); !ok {
+		return nil, fmt.Errorf("'-' needs a term")
+	}
+	n, err := p.parseUnary()
+	if err != nil || n == nil {
+		return nil, err
+	}
+	if n.Kind == Not {
+		return n.Children[0], nil
+	}
+	return &Node{Kind: Not, Children: []*Node{n}}, nil
+}
+
+func (p *parser) parsePrimary() (*Node, error) {
+	t, _ := p.peek()
+	p.pos++
+	switch t.kind {
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c, ok := p.peek(); !ok || c.kind != tokRParen {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return n, nil
+	case tokPhrase:
+		if !Searchable(t.text) {
+			return nil, nil
+		}
+		return &Node{Kind: Phrase, Value: t.text}, nil
+	case tokField:
+		return &Node{Kind: Field, Field: t.field, Value: t.text}, nil
+	case tokWord:
+		if !Searchable(t.text) {
+			return nil, nil
+		}
+		return &Node{Kind: Term, Value: t.text}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+}
+
+func flatten(kind Kind, children []*Node) []*Node {
+	out := make([]*Node, 0, len(children))
+	for _, c := range children {
+		if c.Kind == kind {
+			out = append(out, c.Children...)
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// PathGlobs expands a path: value into wildcard patterns (only * and ? are
+// special) matched against the whole path. Values without wildcards match as
+// substrings; patterns without a '/' also match the basename.
+func PathGlobs(value string) []string {
+	value = strings.TrimSpace(strings.ReplaceAll(value, "\\", "/"))
+	if value == "" {
+		return nil
+	}
+	if !strings.ContainsAny(value, "*?") {
+		return []string{"*" + value + "*"}
+	}
+	if strings.Contains(value, "/") {
+		return []string{value}
+	}
+	return []string{value, "*/" + value}
+}
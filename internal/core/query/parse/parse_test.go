@@ -0,0 +1,98 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		q    string
+		want string
+	}{
+		{`foo`, `foo`},
+		{`foo bar`, `(foo bar)`},
+		{`foo AND bar`, `(foo bar)`},
+		{`"exact phrase" foo`, `("exact phrase" foo)`},
+		{`foo -bar`, `(foo -bar)`},
+		{`--foo`, `foo`},
+		{`foo OR bar baz`, `(foo OR (bar baz))`},
+		{`(foo OR bar) baz`, `((foo OR bar) baz)`},
+		{`a OR (b OR c)`, `(a OR b OR c)`},
+		{`path:internal/ lang:go kind:chunk sym:Open`, `(path:internal/ lang:go kind:chunk sym:Open)`},
+		{`path:"my dir" x`, `(path:"my dir" x)`},
+		{`Open() -x`, `(Open() -x)`},
+		{`(Open() OR Close())`, `(Open() OR Close())`},
+		{`"say \"hi\""`, `"say \"hi\""`},
+		{`foo - bar`, `(foo bar)`},
+		{`a.b`, `a.b`},
+		{`unknown:x`, `unknown:x`},
+		{`lock unlock NEAR/5`, `(lock unlock NEAR/5)`},
+		{`x lock NEAR/3 unlock`, `(x (lock unlock NEAR/3))`},
+		{`a NEAR/2 b NEAR/2 "c d"`, `(a b "c d" NEAR/2)`},
+		{`a b NEAR`, `(a b NEAR/10)`},
+		{`(a b NEAR/1) OR c`, `((a b NEAR/1) OR c)`},
+	}
+	for _, tc := range cases {
+		n, err := Parse(tc.q)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.q, err)
+		}
+		if got := n.String(); got != tc.want {
+			t.Fatalf("Parse(%q) = %s, want %s", tc.q, got, tc.want)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, q := range []string{
+		``,
+		`"unterminated`,
+		`(foo`,
+		`foo OR`,
+		`OR foo`,
+		`path:`,
+		`-`,
+		`"" ()`,
+		`NEAR/2 a`,
+		`a NEAR/2`,
+		`a NEAR/x b`,
+		`a -b NEAR/2`,
+	} {
+		if _, err := Parse(q); err == nil {
+			t.Fatalf("Parse(%q): expected error", q)
+		}
+	}
+}
+
+func TestNode_Terms(t *testing.T) {
+	n, err := Parse(`(foo OR "a b") -bar path:x foo`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got, want := n.Terms(), []string{"foo", "a b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Terms() = %v, want %v", got, want)
+	}
+	if _, ok := n.SimpleTerms(); ok {
+		t.Fatalf("SimpleTerms() should reject operators")
+	}
+
+	n, _ = Parse(`foo "a b"`)
+	if got, ok := n.SimpleTerms(); !ok || !reflect.DeepEqual(got, []string{"foo", "a b"}) {
+		t.Fatalf("SimpleTerms() = %v, %v", got, ok)
+	}
+}
+
+func TestPathGlobs(t *testing.T) {
+	cases := map[string][]string{
+		"internal/":   {"*internal/*"},
+		"*.go":        {"*.go", "*/*.go"},
+		"cmd/*/main*": {"cmd/*/main*"},
+		`a\b`:         {"*a/b*"},
+	}
+	for in, want := range cases {
+		if got := PathGlobs(in); !reflect.DeepEqual(got, want) {
+			t.Fatalf("PathGlobs(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
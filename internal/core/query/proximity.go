@@ -0,0 +1,126 @@
+package query
+
+import (
+	"strings"
+
+	"otterindex/internal/core/query/parse"
+	"otterindex/internal/core/search"
+	"otterindex/internal/model"
+)
+
+// textMatches checks n against text line by line. The stores have already
+// matched the chunk, so only what they cannot see is decided here: NEAR line
+// distances and, for --all-terms, whether the terms fall inside the unit.
+// Without a boundary, terms match at identifier boundaries so that "lock"
+// does not count inside "unlock", close to the stores' whole-token matching.
+// Exclusions and field filters are left to the stores unless they contain a
+// NEAR.
+func textMatches(n *parse.Node, text string, caseInsensitive bool, boundary string) bool {
+	termBoundary := boundary
+	if termBoundary == "" {
+		termBoundary = search.BoundaryIdent
+	}
+	switch n.Kind {
+	case parse.Term, parse.Phrase:
+		return len(termLines(text, n.Value, caseInsensitive, termBoundary)) > 0
+	case parse.Near:
+		lines := make([][]int, len(n.Children))
+		for i, c := range n.Children {
+			lines[i] = termLines(text, c.Value, caseInsensitive, termBoundary)
+			if len(lines[i]) == 0 {
+				return false
+			}
+		}
+		return withinLines(lines, n.Dist)
+	case parse.And:
+		for _, c := range n.Children {
+			if !textMatches(c, text, caseInsensitive, boundary) {
+				return false
+			}
+		}
+		return true
+	case parse.Or:
+		for _, c := range n.Children {
+			if textMatches(c, text, caseInsensitive, boundary) {
+				return true
+			}
+		}
+		return false
+	case parse.Not:
+		if n.Children[0].HasNear() {
+			return !textMatches(n.Children[0], text, caseInsensitive, boundary)
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// termLines returns the (1-based, ascending) lines of text containing term.
+// A phrase whose literal spelling is not found falls back to its longest
+// token, since the stores match phrases on tokens.
+func termLines(text string, term string, caseInsensitive bool, boundary string) []int {
+	found := search.FindInTextAt(text, term, caseInsensitive, boundary)
+	if len(found) == 0 {
+		longest := ""
+		for _, tok := range extractQueryTerms(term) {
+			if len(tok) > len(longest) {
+				longest = tok
+			}
+		}
+		if longest != "" && longest != term {
+			found = search.FindInTextAt(text, longest, caseInsensitive, boundary)
+		}
+	}
+	out := make([]int, len(found))
+	for i, m := range found {
+		out[i] = m.Line
+	}
+	return out
+}
+
+// withinLines reports whether one line can be picked from each list so that
+// all picks lie within dist lines of each other.
+func withinLines(lines [][]int, dist int) bool {
+	for _, starts := range lines {
+		for _, lo := range starts {
+			ok := true
+			for _, ls := range lines {
+				if !hasLineIn(ls, lo, lo+dist) {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasLineIn(lines []int, lo int, hi int) bool {
+	for _, l := range lines {
+		if l >= lo && l <= hi {
+			return true
+		}
+	}
+	return false
+}
+
+// unitText returns the lines covered by r of a chunk text starting at line sl.
+func unitText(text string, sl int, r model.Range) string {
+	lines := strings.Split(text, "\n")
+	lo := r.SL - sl
+	hi := r.EL - sl + 1
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > len(lines) {
+		hi = len(lines)
+	}
+	if lo >= hi {
+		return ""
+	}
+	return strings.Join(lines[lo:hi], "\n")
+}
@@ -0,0 +1,31 @@
+package query
+
+import (
+	"testing"
+
+	"otterindex/internal/core/query/parse"
+)
+
+func TestTextMatches_Case(t *testing.T) {
+	text := "mu.Lock()\nmu.Unlock()\n"
+	cases := []struct {
+		q               string
+		caseInsensitive bool
+		want            bool
+	}{
+		{"Lock Unlock", false, true},
+		{"lock unlock", false, false},
+		{"lock unlock", true, true},
+		{"lock NEAR/1 unlock", false, false},
+		{"lock NEAR/1 unlock", true, true},
+	}
+	for _, tc := range cases {
+		n, err := parse.Parse(tc.q)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.q, err)
+		}
+		if got := textMatches(n, text, tc.caseInsensitive, ""); got != tc.want {
+			t.Fatalf("textMatches(%q, ci=%v) = %v, want %v", tc.q, tc.caseInsensitive, got, tc.want)
+		}
+	}
+}
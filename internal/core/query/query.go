@@ -3,14 +3,15 @@ package query
 import (
 	"fmt"
 	"os"
-	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
 	"unicode"
 
 	"otterindex/internal/core/explain"
+	"otterindex/internal/core/query/parse"
 	"otterindex/internal/core/search"
 	"otterindex/internal/core/unit"
 	"otterindex/internal/index/backend"
@@ -27,11 +28,42 @@ type Options struct {
 	ExcludeGlobs    []string
 	Limit           int
 	Offset          int
-	Explain         explain.Explain
+	Regex           bool
+	Sort            string // "score" (default) or "path"
+	In              string // "all" (default), "code" or "comments"
+	// Boundary restricts matches to whole words ("word") or identifier parts
+	// ("ident", camelCase/snake_case aware); empty matches any substring.
+	Boundary string
+	// AllTerms requires the whole query to match inside each returned unit
+	// (e.g. all terms on the same line with Unit "line"), not just somewhere
+	// in the chunk.
+	AllTerms bool
+	// Mode picks a fusion profile: ModeText (default) searches chunk text
+	// only, the others also retrieve symbol names, file names and comments
+	// and merge the ranked lists. Weights overrides the profile's weight per
+	// source (SourceChunks, ...); 0 turns a source off.
+	Mode    string
+	Weights map[string]float64
+	// Types keeps files of these types (lang.Types: go, py, make, ...) and
+	// TypesNot drops them.
+	Types    []string
+	TypesNot []string
+	// Paths keeps files under these directories (or these files) and Lang
+	// files of one language. Like the globs and types, they are applied by
+	// the store, so Limit and Offset count only matching results.
+	Paths []string
+	Lang  string
+	// MaxPerFile caps the text hits returned per file: 0 keeps
+	// DefaultMaxPerFile, a negative value means no cap.
+	MaxPerFile int
+	Explain    explain.Explain
 }
 
+// DefaultMaxPerFile is the per-file cap when Options.MaxPerFile is 0.
+const DefaultMaxPerFile = 3
+
 func Query(dbPath string, workspaceID string, q string, opts Options) ([]model.ResultItem, error) {
-	items, _, err := queryWithInfo(dbPath, workspaceID, q, opts, 0)
+	items, _, err := queryWithInfo(dbPath, workspaceID, q, opts, 0, 0)
 	return items, err
 }
 
@@ -42,14 +74,41 @@ type queryInfo struct {
 	candidates    []candidateRow
 	fetchN        int
 	exhausted     bool
+	// all holds the first countN items (before offset/limit and range
+	// refinement) when queryWithInfo was asked to count; allExact reports
+	// that there are no more.
+	all      []model.ResultItem
+	allExact bool
+	// truncated counts, per path, the fetched hits dropped by the per-file
+	// cap.
+	truncated map[string]int
 }
 
-func queryWithInfo(dbPath string, workspaceID string, q string, opts Options, prefetchMin int) ([]model.ResultItem, queryInfo, error) {
-	ex := opts.Explain
-	startTotal := time.Now()
+// preparedQuery is a query with its options checked and defaulted.
+type preparedQuery struct {
+	workspaceID string
+	// q is trimmed; a --regex --word query is wrapped in \b.
+	q    string
+	opts Options
+	// ast is nil for regex queries.
+	ast    *parse.Node
+	filter store.PathFilter
+}
 
+// prepareQuery checks the arguments and options shared by Query, QueryPage
+// and Count, fills in defaults and parses q.
+func prepareQuery(dbPath string, workspaceID string, q string, opts Options) (preparedQuery, error) {
 	workspaceID = strings.TrimSpace(workspaceID)
 	q = strings.TrimSpace(q)
+	if strings.TrimSpace(dbPath) == "" {
+		return preparedQuery{}, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return preparedQuery{}, fmt.Errorf("workspaceID is required")
+	}
+	if q == "" {
+		return preparedQuery{}, fmt.Errorf("query is required")
+	}
 	opts.Unit = strings.TrimSpace(opts.Unit)
 	if opts.Unit == "" {
 		opts.Unit = "block"
@@ -58,17 +117,78 @@ func queryWithInfo(dbPath string, workspaceID string, q string, opts Options, pr
 		opts.Limit = 20
 	}
 	if opts.Offset < 0 {
-		return nil, queryInfo{}, fmt.Errorf("offset must be >= 0")
+		return preparedQuery{}, fmt.Errorf("offset must be >= 0")
+	}
+	opts.Sort = normalizeSort(opts.Sort)
+	if opts.Sort != store.SortScore && opts.Sort != store.SortPath {
+		return preparedQuery{}, fmt.Errorf("invalid sort %q", opts.Sort)
+	}
+	opts.In = normalizeIn(opts.In)
+	if opts.In != inAll && opts.In != inCode && opts.In != inComments {
+		return preparedQuery{}, fmt.Errorf("invalid in %q", opts.In)
+	}
+	opts.Boundary = strings.ToLower(strings.TrimSpace(opts.Boundary))
+	if opts.Boundary != "" && opts.Boundary != search.BoundaryWord && opts.Boundary != search.BoundaryIdent {
+		return preparedQuery{}, fmt.Errorf("invalid boundary %q", opts.Boundary)
+	}
+	filter, err := newPathFilter(pathSpec{
+		Include:  opts.IncludeGlobs,
+		Exclude:  opts.ExcludeGlobs,
+		Types:    opts.Types,
+		TypesNot: opts.TypesNot,
+		Paths:    opts.Paths,
+		Lang:     opts.Lang,
+	})
+	if err != nil {
+		return preparedQuery{}, err
 	}
 
-	if strings.TrimSpace(dbPath) == "" {
-		return nil, queryInfo{}, fmt.Errorf("dbPath is required")
+	pq := preparedQuery{workspaceID: workspaceID, opts: opts, filter: filter}
+	if opts.Regex {
+		switch opts.Boundary {
+		case search.BoundaryWord:
+			q = `\b(?:` + q + `)\b`
+		case search.BoundaryIdent:
+			return preparedQuery{}, fmt.Errorf("boundary %q is not supported with regex", opts.Boundary)
+		}
+		if _, err := search.CompileRegex(q, opts.CaseInsensitive); err != nil {
+			return preparedQuery{}, err
+		}
+	} else {
+		node, err := parse.Parse(q)
+		if err != nil {
+			return preparedQuery{}, fmt.Errorf("invalid query: %w", err)
+		}
+		pq.ast = node
 	}
-	if workspaceID == "" {
-		return nil, queryInfo{}, fmt.Errorf("workspaceID is required")
+	pq.q = q
+	return pq, nil
+}
+
+// queryWithInfo runs a query. With countN > 0 it builds at least countN items
+// so the caller can count them (see QueryPage).
+func queryWithInfo(dbPath string, workspaceID string, q string, opts Options, prefetchMin int, countN int) ([]model.ResultItem, queryInfo, error) {
+	ex := opts.Explain
+	startTotal := time.Now()
+
+	pq, err := prepareQuery(dbPath, workspaceID, q, opts)
+	if err != nil {
+		return nil, queryInfo{}, err
 	}
-	if q == "" {
-		return nil, queryInfo{}, fmt.Errorf("query is required")
+	workspaceID, q, opts, ast, filter := pq.workspaceID, pq.q, pq.opts, pq.ast, pq.filter
+	if opts.Regex && opts.AllTerms {
+		return nil, queryInfo{}, fmt.Errorf("all-terms is not supported with regex")
+	}
+	weights, err := fusionWeights(opts.Mode, opts.Weights)
+	if err != nil {
+		return nil, queryInfo{}, err
+	}
+	opts.Mode = normalizeMode(opts.Mode)
+	// Symbol, path and comment lookups take plain terms; other queries, and
+	// --in code|comments, search chunk text only.
+	var fuseTerms []string
+	if isFused(weights) && ast != nil && opts.In == inAll {
+		fuseTerms, _ = ast.SimpleTerms()
 	}
 
 	if ex != nil {
@@ -82,10 +202,45 @@ func queryWithInfo(dbPath string, workspaceID string, q string, opts Options, pr
 		ex.KV("offset", opts.Offset)
 		ex.KV("include_globs", opts.IncludeGlobs)
 		ex.KV("exclude_globs", opts.ExcludeGlobs)
+		if len(opts.Types) > 0 {
+			ex.KV("types", strings.Join(opts.Types, ","))
+		}
+		if len(opts.TypesNot) > 0 {
+			ex.KV("types_not", strings.Join(opts.TypesNot, ","))
+		}
+		if len(opts.Paths) > 0 {
+			ex.KV("paths", strings.Join(opts.Paths, ","))
+		}
+		if opts.Lang != "" {
+			ex.KV("lang", opts.Lang)
+		}
 		ex.KV("unit", opts.Unit)
 		if opts.Unit == "line" {
 			ex.KV("context_lines", opts.ContextLines)
 		}
+		if opts.Regex {
+			ex.KV("regex", true)
+		}
+		ex.KV("sort", opts.Sort)
+		if opts.In != inAll {
+			ex.KV("in", opts.In)
+		}
+		if opts.Boundary != "" {
+			ex.KV("boundary", opts.Boundary)
+		}
+		if opts.AllTerms {
+			ex.KV("all_terms", true)
+		}
+		if ast != nil {
+			ex.KV("query_ast", ast.String())
+		}
+		if opts.Mode != ModeText || len(opts.Weights) > 0 {
+			ex.KV("mode", opts.Mode)
+			ex.KV("fusion_weights", formatWeights(weights))
+			if len(fuseTerms) == 0 {
+				ex.KV("fusion", "off (needs plain terms and --in all)")
+			}
+		}
 	}
 
 	s, err := backend.Open(opts.Store, dbPath)
@@ -110,8 +265,11 @@ func queryWithInfo(dbPath string, workspaceID string, q string, opts Options, pr
 	if wantN < 0 {
 		return nil, queryInfo{}, fmt.Errorf("limit+offset overflow")
 	}
+	if countN > wantN {
+		wantN = countN
+	}
 
-	pathTopN := 3
+	pathTopN := maxPerFile(opts)
 	fetchN := wantN * 5
 	if fetchN < 100 {
 		fetchN = 100
@@ -119,11 +277,9 @@ func queryWithInfo(dbPath string, workspaceID string, q string, opts Options, pr
 	if prefetchMin > 0 && fetchN < prefetchMin {
 		fetchN = prefetchMin
 	}
-	if len(opts.IncludeGlobs) > 0 || len(opts.ExcludeGlobs) > 0 {
-		// Over-fetch a bit to keep results useful when filters are strict.
-		if fetchN < 500 {
-			fetchN = 500
-		}
+	if opts.Sort == store.SortScore && fetchN < rankWindow {
+		// Rank the same window whatever the page, so pages slice one list.
+		fetchN = rankWindow
 	}
 	if ex != nil {
 		ex.KV("prefetch_n", fetchN)
@@ -131,6 +287,25 @@ func queryWithInfo(dbPath string, workspaceID string, q string, opts Options, pr
 	}
 	info.fetchN = fetchN
 
+	var comments *commentIndex
+	if opts.In != inAll {
+		comments = newCommentIndex(s, workspaceID)
+	}
+
+	var aux []retriever
+	if len(fuseTerms) > 0 {
+		for _, src := range sourceOrder[1:] {
+			if weights[src] <= 0 {
+				continue
+			}
+			fn := auxSources[src]
+			aux = append(aux, retriever{source: src, run: func() ([]candidateRow, error) {
+				cands, err := fn(s, workspaceID, newSourceLines(ws.Root), fuseTerms, auxWindow)
+				return keepPaths(cands, filter), err
+			}})
+		}
+	}
+
 	var items []model.ResultItem
 	rowsReturned := 0
 	var candidates []candidateRow
@@ -139,33 +314,86 @@ func queryWithInfo(dbPath string, workspaceID string, q string, opts Options, pr
 	for attempt := 0; attempt < 3; attempt++ {
 		attempts++
 
-		stopSQL := func() {}
-		if ex != nil {
-			stopSQL = ex.Timer("sql")
-		}
-		res, err := s.SearchChunks(workspaceID, q, fetchN, opts.CaseInsensitive)
-		stopSQL()
-		if err != nil {
-			return nil, queryInfo{}, err
+		var res store.SearchResult
+		chunks := retriever{source: SourceChunks, run: func() ([]candidateRow, error) {
+			stopSQL := func() {}
+			if ex != nil {
+				stopSQL = ex.Timer("sql")
+			}
+			var err error
+			searchOpts := store.SearchOptions{
+				Limit:           fetchN,
+				CaseInsensitive: opts.CaseInsensitive,
+				Sort:            opts.Sort,
+				Subword:         opts.Boundary == search.BoundaryIdent,
+				Filter:          filter,
+			}
+			if opts.Regex {
+				res, err = s.SearchChunksRegex(workspaceID, q, searchOpts)
+			} else {
+				res, err = s.SearchChunks(workspaceID, q, searchOpts)
+			}
+			stopSQL()
+			if err != nil {
+				return nil, err
+			}
+			cands := candidatesFromChunks(res.Chunks)
+			if opts.Sort == store.SortScore {
+				stopRank := func() {}
+				if ex != nil {
+					stopRank = ex.Timer("rank")
+				}
+				var terms []string
+				if ast != nil {
+					terms = ast.Terms()
+				}
+				// Later candidates keep the store order, so a larger fetch
+				// only appends to the ranked list.
+				rankCandidates(s, workspaceID, cands[:min(len(cands), rankWindow)], terms)
+				stopRank()
+			}
+			return cands, nil
+		}}
+		if len(aux) == 0 {
+			candidates, err = chunks.run()
+			if err != nil {
+				return nil, queryInfo{}, err
+			}
+		} else {
+			rs := append([]retriever{chunks}, aux...)
+			stopRetrieve := func() {}
+			if ex != nil {
+				stopRetrieve = ex.Timer("retrieve")
+			}
+			lists, err := runRetrievers(rs)
+			stopRetrieve()
+			if err != nil {
+				return nil, queryInfo{}, err
+			}
+			candidates = fuseCandidates(rs, lists, weights, opts.Sort)
+			if ex != nil {
+				for i, list := range lists {
+					ex.KV("retrieved_"+rs[i].source, len(list))
+				}
+			}
 		}
 		rowsReturned = len(res.Chunks)
 		matchCaseInsensitive = res.MatchCaseInsensitive
 		if ex != nil && attempt == 0 {
 			ex.KV("match_case_insensitive", matchCaseInsensitive)
 		}
-		candidates = candidatesFromChunks(res.Chunks)
 
 		stopMatch := func() {}
 		if ex != nil {
 			stopMatch = ex.Timer("match")
 		}
-		items, err = buildItemsFromCandidates(candidates, q, opts, matchCaseInsensitive, pathTopN, wantN, ex)
+		items, info.truncated, err = buildItemsFromCandidates(candidates, q, opts, matchCaseInsensitive, pathTopN, wantN, comments, ex)
 		stopMatch()
 		if err != nil {
 			return nil, queryInfo{}, err
 		}
 
-		// If we have enough (after dedupe/filters) or the DB returned fewer than requested, stop.
+		// If we have enough (after dedupe) or the DB returned fewer than requested, stop.
 		if len(items) >= wantN || len(res.Chunks) < fetchN {
 			info.exhausted = len(res.Chunks) < fetchN
 			break
@@ -183,38 +411,41 @@ func queryWithInfo(dbPath string, workspaceID string, q string, opts Options, pr
 		ex.KV("rows_returned", rowsReturned)
 		ex.KV("prefetch_attempts", attempts)
 		ex.KV("items_after_dedupe", len(items))
+		if len(aux) > 0 {
+			ex.KV("fusion_top", fusionSummary(candidates, 10))
+		}
 	}
 	info.candidates = candidates
+	if countN > 0 {
+		info.all = cloneResultItems(items)
+		info.allExact = info.exhausted && len(items) < wantN
+	}
 
 	items = sliceLimitOffset(items, opts.Offset, opts.Limit, ex)
+	refineItems(s, workspaceID, ws.Root, items, opts.Unit, ex)
 
-	if opts.Unit == "symbol" {
-		stopSymbol := func() {}
-		if ex != nil {
-			stopSymbol = ex.Timer("symbol")
-		}
-		fallback := refineSymbolRangesWithStore(s, workspaceID, items, ex)
-		stopSymbol()
-		if ex != nil && fallback > 0 {
-			ex.KV("unit_fallback", "symbol->block")
-		}
+	if ex != nil {
+		ex.KV("elapsed_ms_total", time.Since(startTotal).Milliseconds())
 	}
 
-	// Refine ranges using the real file when available.
-	if ws.Root != "" {
-		stopFile := func() {}
-		if ex != nil {
-			stopFile = ex.Timer("file_read")
+	return items, info, nil
+}
+
+func firstNonBlankLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
 		}
-		refineRangesWithFiles(items, ws.Root, opts.Unit)
-		stopFile()
 	}
+	return ""
+}
 
-	if ex != nil {
-		ex.KV("elapsed_ms_total", time.Since(startTotal).Milliseconds())
+func normalizeSort(v string) string {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" {
+		return store.SortScore
 	}
-
-	return items, info, nil
+	return v
 }
 
 func countFileLines(path string) int {
@@ -237,52 +468,21 @@ func readFileText(path string) string {
 	return string(b)
 }
 
-func anyGlobMatch(patterns []string, rel string) bool {
-	for _, pat := range patterns {
-		if matchesGlob(pat, rel) {
-			return true
-		}
-	}
-	return false
-}
-
-func matchesGlob(pattern string, rel string) bool {
-	pat := strings.TrimSpace(pattern)
-	if pat == "" {
-		return false
-	}
-	pat = strings.ReplaceAll(pat, "\\", "/")
-	rel = filepath.ToSlash(rel)
-
-	// Support csv passed via -x "*.js,*.sql" when not using StringSliceVar.
-	if strings.Contains(pat, ",") {
-		for _, piece := range strings.Split(pat, ",") {
-			if matchesGlob(strings.TrimSpace(piece), rel) {
-				return true
-			}
-		}
-		return false
-	}
-
-	// Treat patterns without path separators as basename patterns.
-	if !strings.Contains(pat, "/") {
-		ok, _ := path.Match(pat, path.Base(rel))
-		return ok
-	}
-
-	ok, _ := path.Match(pat, rel)
-	return ok
-}
-
-func findMatchesInChunk(text string, q string, caseInsensitive bool) []model.Match {
-	matches := search.FindInText(text, q, caseInsensitive)
+func findMatchesInChunk(text string, q string, caseInsensitive bool, boundary string) []model.Match {
+	matches := search.FindInTextAt(text, q, caseInsensitive, boundary)
 	if len(matches) > 0 {
 		return matches
 	}
 
-	terms := extractQueryTerms(q)
-	for _, term := range terms {
-		matches = append(matches, search.FindInText(text, term, caseInsensitive)...)
+	for _, term := range highlightTerms(q) {
+		found := search.FindInTextAt(text, term, caseInsensitive, boundary)
+		if len(found) == 0 {
+			// Phrases match on tokens, so the literal spelling may differ.
+			for _, tok := range extractQueryTerms(term) {
+				found = append(found, search.FindInTextAt(text, tok, caseInsensitive, boundary)...)
+			}
+		}
+		matches = append(matches, found...)
 	}
 	if len(matches) <= 1 {
 		return matches
@@ -309,6 +509,24 @@ func findMatchesInChunk(text string, q string, caseInsensitive bool) []model.Mat
 	return matches
 }
 
+// highlightTerms returns the terms and phrases a hit is expected to contain,
+// ignoring operators, exclusions and field filters.
+func highlightTerms(q string) []string {
+	node, err := parse.Parse(q)
+	if err != nil {
+		return extractQueryTerms(q)
+	}
+	return node.Terms()
+}
+
+func simpleQueryTerms(q string) ([]string, bool) {
+	node, err := parse.Parse(q)
+	if err != nil {
+		return nil, false
+	}
+	return node.SimpleTerms()
+}
+
 func extractQueryTerms(q string) []string {
 	q = strings.TrimSpace(q)
 	if q == "" {
@@ -368,102 +586,318 @@ func candidatesFromChunks(chunks []store.Chunk) []candidateRow {
 			EL:      chunks[i].EL,
 			Text:    chunks[i].Text,
 			Snippet: chunks[i].Snippet,
+			Score:   chunks[i].Score,
 		}
 	}
 	return out
 }
 
-func buildItemsFromCandidates(candidates []candidateRow, q string, opts Options, matchCaseInsensitive bool, pathTopN int, wantN int, ex explain.Explain) ([]model.ResultItem, error) {
+// maxPerFile resolves opts.MaxPerFile to the cap buildItemsFromCandidates
+// takes, where 0 means no cap.
+func maxPerFile(opts Options) int {
+	switch {
+	case opts.MaxPerFile == 0:
+		return DefaultMaxPerFile
+	case opts.MaxPerFile < 0:
+		return 0
+	}
+	return opts.MaxPerFile
+}
+
+// buildItemsFromCandidates turns candidate chunks into result items. When
+// comments is non-nil, matches are restricted to code or to comments per
+// opts.In; comment matches yield one item per comment. It also returns how
+// many hits of each path the pathTopN cap dropped.
+func buildItemsFromCandidates(candidates []candidateRow, q string, opts Options, matchCaseInsensitive bool, pathTopN int, wantN int, comments *commentIndex, ex explain.Explain) ([]model.ResultItem, map[string]int, error) {
+	var re *regexp.Regexp
+	if opts.Regex {
+		var err error
+		re, err = search.CompileRegex(q, matchCaseInsensitive)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	var node *parse.Node
+	if re == nil {
+		node, _ = parse.Parse(q)
+	}
+	checkNear := node != nil && node.HasNear()
+
 	items := make([]model.ResultItem, 0, len(candidates))
 	seen := map[string]int{}
+	truncated := map[string]int{}
+	seenComment := map[string]bool{}
+	// Fused candidates from different sources can land on the same unit.
+	seenUnit := map[string]int{}
+	capped := func(path string) bool { return pathTopN > 0 && seen[path] >= pathTopN }
+	// Once wantN items are built, the rest of the candidates are only
+	// checked to count the hits the per-file cap drops.
+	full := false
 	for _, c := range candidates {
-		if len(opts.IncludeGlobs) > 0 && !anyGlobMatch(opts.IncludeGlobs, c.Path) {
+		if full && (c.Kind != "" || !capped(c.Path)) {
 			continue
 		}
-		if anyGlobMatch(opts.ExcludeGlobs, c.Path) {
-			continue
-		}
-
 		item := model.ResultItem{
 			Kind:  "unit",
 			Path:  c.Path,
 			Range: model.Range{SL: c.SL, SC: 1, EL: c.EL, EC: 1},
+			Score: roundScore(c.Score),
+		}
+		if c.Kind != "" {
+			item.Kind = c.Kind
+			item.Title = c.Title
 		}
 
-		relMatches := findMatchesInChunk(c.Text, q, matchCaseInsensitive)
+		text := c.Text
+		if comments != nil {
+			text = comments.mask(c.Path, c.SL, c.Text, opts.In == inComments)
+		}
+		var relMatches []model.Match
+		if c.Kind == "file" {
+			// A file named like the query is shown by its head; text hits
+			// inside it come from other candidates.
+		} else if re != nil {
+			relMatches = search.FindRegexInText(text, re)
+		} else {
+			relMatches = findMatchesInChunk(text, q, matchCaseInsensitive, opts.Boundary)
+			if opts.Boundary != "" && len(relMatches) == 0 && len(highlightTerms(q)) > 0 {
+				// The store matched on tokens or substrings; none sit on a boundary.
+				continue
+			}
+			if checkNear && !textMatches(node, text, matchCaseInsensitive, opts.Boundary) {
+				continue
+			}
+		}
+		if comments != nil {
+			if len(relMatches) == 0 {
+				continue
+			}
+			lines := strings.Split(c.Text, "\n")
+			for i := range relMatches {
+				if l := relMatches[i].Line - 1; l >= 0 && l < len(lines) {
+					relMatches[i].Text = lines[l]
+				}
+			}
+		}
 		for i := range relMatches {
 			relMatches[i].Line = c.SL + relMatches[i].Line - 1
 		}
-		item.Matches = relMatches
-		if strings.TrimSpace(c.Snippet) != "" {
-			item.Snippet = strings.TrimSpace(c.Snippet)
-		} else if len(relMatches) > 0 {
-			item.Snippet = buildSnippetFromMatchLine(relMatches[0].Text, relMatches[0].Col, q, matchCaseInsensitive)
+		if comments != nil && opts.In == inComments {
+			for _, hit := range comments.commentHits(c.Path, relMatches) {
+				key := fmt.Sprintf("%s:%d:%d", c.Path, hit.comment.Range.SL, hit.comment.Range.SC)
+				if seenComment[key] {
+					continue
+				}
+				seenComment[key] = true
+				if capped(c.Path) {
+					truncated[c.Path]++
+					continue
+				}
+				if full {
+					break
+				}
+				if pathTopN > 0 {
+					seen[c.Path]++
+				}
+				items = append(items, commentItem(c, hit, q, opts, matchCaseInsensitive, re != nil))
+				if wantN > 0 && len(items) >= wantN {
+					full = true
+				}
+			}
+			continue
 		}
-
 		stopUnitize := func() {}
 		if ex != nil {
 			stopUnitize = ex.Timer("unitize")
 		}
-		switch opts.Unit {
-		case "symbol":
-			fallthrough
-		case "block":
-			relLine := 1
-			relCol := 1
-			if len(relMatches) > 0 {
-				relLine = relMatches[0].Line - c.SL + 1
-				relCol = relMatches[0].Col
+		// The unit is anchored at the first match; with --all-terms, at the
+		// first match whose unit holds the whole query.
+		anchor := 0
+		r, err := unitRange(c, relMatches, anchor, opts)
+		if err != nil {
+			stopUnitize()
+			return nil, nil, err
+		}
+		if opts.AllTerms && node != nil && c.Kind != "file" {
+			for anchor < len(relMatches) && !textMatches(node, unitText(text, c.SL, r), matchCaseInsensitive, opts.Boundary) {
+				anchor++
+				if anchor < len(relMatches) {
+					r, _ = unitRange(c, relMatches, anchor, opts)
+				}
 			}
-			r := unit.BlockRange(c.Text, model.Match{Line: relLine, Col: relCol})
-			r.SL += c.SL - 1
-			r.EL += c.SL - 1
-			item.Range = r
-		case "line":
-			if len(relMatches) == 0 {
-				r := unit.LineRange(c.Text, model.Match{Line: 1, Col: 1}, opts.ContextLines)
-				r.SL += c.SL - 1
-				r.EL += c.SL - 1
-				item.Range = r
-				break
+			if anchor == len(relMatches) {
+				stopUnitize()
+				continue
 			}
+		}
+		item.Range = r
+		stopUnitize()
 
-			m := relMatches[0]
-			contextLines := opts.ContextLines
-			if contextLines < 0 {
-				contextLines = 0
+		item.Matches = relMatches
+		if anchor > 0 && anchor < len(relMatches) {
+			// Range refinement re-anchors on Matches[0].
+			item.Matches = make([]model.Match, 0, len(relMatches))
+			item.Matches = append(item.Matches, relMatches[anchor])
+			item.Matches = append(item.Matches, relMatches[:anchor]...)
+			item.Matches = append(item.Matches, relMatches[anchor+1:]...)
+		}
+		if strings.TrimSpace(c.Snippet) != "" {
+			item.Snippet = strings.TrimSpace(c.Snippet)
+		} else if len(relMatches) > 0 && re != nil {
+			item.Snippet = buildSnippetFromSpan(relMatches[anchor].Text, relMatches[anchor].Col, relMatches[anchor].Len)
+		} else if len(relMatches) > 0 {
+			item.Snippet = buildSnippetFromMatchLine(relMatches[anchor].Text, relMatches[anchor].Col, q, matchCaseInsensitive, opts.Boundary)
+		} else if c.Kind == "file" {
+			item.Snippet = firstNonBlankLine(text)
+		}
+
+		unitKey := ""
+		if len(c.Sources) > 0 {
+			line := item.Range.SL
+			if anchor < len(relMatches) {
+				line = relMatches[anchor].Line
 			}
-			r := model.Range{
-				SL: m.Line - contextLines,
-				SC: 1,
-				EL: m.Line + contextLines,
-				EC: 1,
+			unitKey = fmt.Sprintf("%s:%d", item.Path, line)
+			if c.Kind == "file" {
+				unitKey = "file|" + unitKey
 			}
-			if r.SL < 1 {
-				r.SL = 1
+			if j, ok := seenUnit[unitKey]; ok {
+				if items[j].Kind == "unit" && item.Kind != "unit" {
+					// The text hit is a definition or a doc comment; show it
+					// as such.
+					item.Score = max(item.Score, items[j].Score)
+					items[j] = item
+				}
+				continue
 			}
-			item.Range = r
-		case "file":
-			// Filled after selection, if we have the workspace root.
-		default:
-			stopUnitize()
-			return nil, fmt.Errorf("invalid unit %q", opts.Unit)
 		}
-		stopUnitize()
 
-		if pathTopN > 0 {
-			if seen[item.Path] >= pathTopN {
+		// Symbol, file and comment hits come on top of a file's text hits.
+		if pathTopN > 0 && c.Kind == "" {
+			if capped(item.Path) {
+				truncated[item.Path]++
 				continue
 			}
 			seen[item.Path]++
 		}
+		if unitKey != "" {
+			seenUnit[unitKey] = len(items)
+		}
 		items = append(items, item)
 		if wantN > 0 && len(items) >= wantN {
-			break
+			full = true
+		}
+	}
+
+	return items, truncated, nil
+}
+
+// unitRange returns the range of the unit around relMatches[i] (lines already
+// absolute) in chunk c; without matches the unit starts at the chunk.
+func unitRange(c candidateRow, relMatches []model.Match, i int, opts Options) (model.Range, error) {
+	switch opts.Unit {
+	case "symbol":
+		fallthrough
+	case "block":
+		relLine := 1
+		relCol := 1
+		if i < len(relMatches) {
+			relLine = relMatches[i].Line - c.SL + 1
+			relCol = relMatches[i].Col
+		}
+		r := unit.BlockRange(c.Text, model.Match{Line: relLine, Col: relCol})
+		r.SL += c.SL - 1
+		r.EL += c.SL - 1
+		return r, nil
+	case "line":
+		if i >= len(relMatches) {
+			r := unit.LineRange(c.Text, model.Match{Line: 1, Col: 1}, opts.ContextLines)
+			r.SL += c.SL - 1
+			r.EL += c.SL - 1
+			return r, nil
+		}
+
+		m := relMatches[i]
+		contextLines := opts.ContextLines
+		if contextLines < 0 {
+			contextLines = 0
+		}
+		r := model.Range{
+			SL: m.Line - contextLines,
+			SC: 1,
+			EL: m.Line + contextLines,
+			EC: 1,
+		}
+		if r.SL < 1 {
+			r.SL = 1
+		}
+		return r, nil
+	case "file":
+		// Filled after selection, if we have the workspace root.
+		return model.Range{SL: c.SL, SC: 1, EL: c.EL, EC: 1}, nil
+	default:
+		return model.Range{}, fmt.Errorf("invalid unit %q", opts.Unit)
+	}
+}
+
+func commentItem(c candidateRow, hit commentHit, q string, opts Options, matchCaseInsensitive bool, isRegex bool) model.ResultItem {
+	m := hit.matches[0]
+	item := model.ResultItem{
+		Kind:    "comment",
+		Path:    c.Path,
+		Range:   hit.comment.Range,
+		Matches: hit.matches,
+		Score:   roundScore(c.Score),
+		Symbol:  hit.symbol,
+	}
+	if hit.symbol != nil {
+		item.Title = strings.TrimSpace(hit.symbol.Signature)
+		if item.Title == "" {
+			item.Title = hit.symbol.Name
+		}
+	}
+	if isRegex {
+		item.Snippet = buildSnippetFromSpan(m.Text, m.Col, m.Len)
+	} else {
+		item.Snippet = buildSnippetFromMatchLine(m.Text, m.Col, q, matchCaseInsensitive, opts.Boundary)
+	}
+	if opts.Unit == "line" {
+		contextLines := opts.ContextLines
+		if contextLines < 0 {
+			contextLines = 0
+		}
+		item.Range = model.Range{SL: m.Line - contextLines, SC: 1, EL: m.Line + contextLines, EC: 1}
+		if item.Range.SL < 1 {
+			item.Range.SL = 1
 		}
 	}
+	return item
+}
 
-	return items, nil
+// refineItems narrows the ranges of a page of items to symbols (Unit
+// "symbol") and to the file's real lines.
+func refineItems(s store.Store, workspaceID string, root string, items []model.ResultItem, unitKind string, ex explain.Explain) {
+	if unitKind == "symbol" {
+		stopSymbol := func() {}
+		if ex != nil {
+			stopSymbol = ex.Timer("symbol")
+		}
+		fallback := refineSymbolRangesWithStore(s, workspaceID, items, ex)
+		stopSymbol()
+		if ex != nil && fallback > 0 {
+			ex.KV("unit_fallback", "symbol->block")
+		}
+	}
+
+	// Refine ranges using the real file when available.
+	if root != "" {
+		stopFile := func() {}
+		if ex != nil {
+			stopFile = ex.Timer("file_read")
+		}
+		refineRangesWithFiles(items, root, unitKind)
+		stopFile()
+	}
 }
 
 func sliceLimitOffset(items []model.ResultItem, offset int, limit int, ex explain.Explain) []model.ResultItem {
@@ -493,6 +927,10 @@ func refineRangesWithFiles(items []model.ResultItem, workspaceRoot string, unitK
 	fileTextLoaded := map[string]bool{}
 
 	for i := range items {
+		if (items[i].Kind == "comment" || items[i].Kind == "file") && (unitKind == "block" || unitKind == "symbol") {
+			// Comment hits keep the comment's own range, file hits their head.
+			continue
+		}
 		switch unitKind {
 		case "block":
 			match := model.Match{Line: items[i].Range.SL, Col: 1}
@@ -570,6 +1008,9 @@ func refineSymbolRangesWithStore(s store.Store, workspaceID string, items []mode
 	}
 
 	for i := range items {
+		if items[i].Kind == "comment" || items[i].Kind == "file" {
+			continue
+		}
 		line := items[i].Range.SL
 		if len(items[i].Matches) > 0 {
 			line = items[i].Matches[0].Line
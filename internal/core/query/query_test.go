@@ -1,14 +1,17 @@
 package query
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
 	"otterindex/internal/core/indexer"
 	"otterindex/internal/index/backend"
 	"otterindex/internal/index/store"
+	"otterindex/internal/model"
 )
 
 func TestQueryReturnsRanges(t *testing.T) {
@@ -126,3 +129,719 @@ func TestQuery_UnitSymbol_UsesEnclosingRangeWhenPresent(t *testing.T) {
 		})
 	}
 }
+
+func TestQuery_Regex(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			src := "package a\n\nfunc fooHandler(w int) {}\n\nfunc helper() {}\n\nfunc BarHandler(r int) {}\n"
+			_ = os.WriteFile(filepath.Join(root, "a.go"), []byte(src), 0o644)
+			_ = os.WriteFile(filepath.Join(root, "b.go"), []byte("package b\n\n// fooHandler is mentioned here\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+
+			// Line windows keep both handlers in one chunk, with or without tree-sitter.
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName, Chunking: indexer.ChunkingLines}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			results, err := Query(dbPath, root, `func \w+Handler\(`, Options{Store: storeName, Unit: "line", Regex: true})
+			if err != nil {
+				t.Fatalf("query: %v", err)
+			}
+			if len(results) != 1 || results[0].Path != "a.go" {
+				t.Fatalf("unexpected results: %+v", results)
+			}
+			ms := results[0].Matches
+			if len(ms) != 2 {
+				t.Fatalf("expected 2 matches, got %+v", ms)
+			}
+			if ms[0].Line != 3 || ms[0].Col != 1 || ms[0].Len != len("func fooHandler(") {
+				t.Fatalf("unexpected match: %+v", ms[0])
+			}
+			if ms[1].Line != 7 || ms[1].Len != len("func BarHandler(") {
+				t.Fatalf("unexpected match: %+v", ms[1])
+			}
+			if results[0].Snippet != "<<func fooHandler(>>w int) {}" {
+				t.Fatalf("unexpected snippet: %q", results[0].Snippet)
+			}
+
+			results, err = Query(dbPath, root, `BARHANDLER`, Options{Store: storeName, Unit: "line", Regex: true, CaseInsensitive: true})
+			if err != nil {
+				t.Fatalf("query -i: %v", err)
+			}
+			if len(results) != 1 || results[0].Matches[0].Col != 6 {
+				t.Fatalf("unexpected -i results: %+v", results)
+			}
+
+			if _, err := Query(dbPath, root, `(`, Options{Store: storeName, Regex: true}); err == nil {
+				t.Fatalf("expected invalid regex error")
+			}
+		})
+	}
+}
+
+func TestQuery_BooleanSyntax(t *testing.T) {
+	files := map[string]string{
+		"internal/auth/login.go":      "package auth\n\nfunc Login(user string) error {\n\treturn checkPassword(user)\n}\n",
+		"internal/auth/login_test.go": "package auth\n\nfunc TestLogin() { Login(\"x\") }\n",
+		"web/login.js":                "function login(user) { return checkPassword(user) }\n",
+		"web/Legacy.JS":               "function legacy() { return 1 }\n",
+		"docs.md":                     "The login flow checks the password.\n",
+	}
+	cases := []struct {
+		q    string
+		want []string
+	}{
+		{`login`, []string{"docs.md", "internal/auth/login.go", "internal/auth/login_test.go", "web/login.js"}},
+		{`"checkPassword(user)"`, []string{"internal/auth/login.go", "web/login.js"}},
+		{`login -TestLogin`, []string{"docs.md", "internal/auth/login.go", "web/login.js"}},
+		{`checkPassword OR flow`, []string{"docs.md", "internal/auth/login.go", "web/login.js"}},
+		{`(flow OR TestLogin) -password`, []string{"internal/auth/login_test.go"}},
+		{`login path:internal/`, []string{"internal/auth/login.go", "internal/auth/login_test.go"}},
+		{`login lang:js`, []string{"web/login.js"}},
+		{`user -lang:go`, []string{"web/login.js"}},
+		{`legacy lang:js`, []string{"web/Legacy.JS"}},
+		{`sym:Login`, []string{"internal/auth/login.go"}},
+		{`"user string" OR sym:Login`, []string{"internal/auth/login.go"}},
+	}
+
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			for rel, src := range files {
+				p := filepath.Join(root, filepath.FromSlash(rel))
+				_ = os.MkdirAll(filepath.Dir(p), 0o755)
+				_ = os.WriteFile(p, []byte(src), 0o644)
+			}
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			st, err := backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			if err := st.ReplaceSymbolsBatch(root, "internal/auth/login.go", []store.SymbolInput{
+				{Kind: "function", Name: "Login", SL: 3, SC: 1, EL: 5, EC: 1, Lang: "go", Signature: "func Login(user string) error"},
+			}); err != nil {
+				_ = st.Close()
+				t.Fatalf("replace symbols: %v", err)
+			}
+			_ = st.Close()
+
+			for _, tc := range cases {
+				results, err := Query(dbPath, root, tc.q, Options{Store: storeName})
+				if err != nil {
+					t.Fatalf("query %q: %v", tc.q, err)
+				}
+				var got []string
+				for _, r := range results {
+					got = append(got, r.Path)
+				}
+				sort.Strings(got)
+				if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+					t.Fatalf("query %q: got %v, want %v", tc.q, got, tc.want)
+				}
+			}
+
+			for _, q := range []string{`"unterminated`, `(login`, `login OR`, `lang:klingon`} {
+				if _, err := Query(dbPath, root, q, Options{Store: storeName}); err == nil {
+					t.Fatalf("expected error for %q", q)
+				}
+			}
+		})
+	}
+}
+
+func TestQuery_SortScore(t *testing.T) {
+	files := map[string]string{
+		"aaa/calls.go":      "package aaa\n\nfunc run() {\n\tLogin()\n\tLogin()\n}\n",
+		"zzz/auth.go":       "package zzz\n\nfunc Login() {}\n",
+		"vendor/x/login.go": "package x\n\nfunc Login() {}\n",
+	}
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			for rel, src := range files {
+				p := filepath.Join(root, filepath.FromSlash(rel))
+				_ = os.MkdirAll(filepath.Dir(p), 0o755)
+				_ = os.WriteFile(p, []byte(src), 0o644)
+			}
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName, ScanAll: true}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			paths := func(items []model.ResultItem) string {
+				var out []string
+				for _, it := range items {
+					out = append(out, it.Path)
+				}
+				return strings.Join(out, ",")
+			}
+
+			results, err := Query(dbPath, root, "Login", Options{Store: storeName})
+			if err != nil {
+				t.Fatalf("query: %v", err)
+			}
+			if got := paths(results); got != "zzz/auth.go,aaa/calls.go,vendor/x/login.go" {
+				t.Fatalf("unexpected score order: %s", got)
+			}
+			for i, it := range results {
+				if it.Score <= 0 || (i > 0 && it.Score > results[i-1].Score) {
+					t.Fatalf("unexpected scores: %+v", results)
+				}
+			}
+
+			results, err = Query(dbPath, root, "Login", Options{Store: storeName, Sort: "path"})
+			if err != nil {
+				t.Fatalf("query path: %v", err)
+			}
+			if got := paths(results); got != "aaa/calls.go,vendor/x/login.go,zzz/auth.go" {
+				t.Fatalf("unexpected path order: %s", got)
+			}
+			if results[0].Score != 0 {
+				t.Fatalf("expected no score in path order: %+v", results[0])
+			}
+
+			if _, err := Query(dbPath, root, "Login", Options{Store: storeName, Sort: "random"}); err == nil {
+				t.Fatalf("expected invalid sort error")
+			}
+		})
+	}
+}
+
+func TestQuery_SortScoreIsPageIndependent(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			for i := 0; i < 150; i++ {
+				var b strings.Builder
+				fmt.Fprintf(&b, "package p%03d\n\nfunc run%03d() {\n", i, i)
+				for j := 0; j <= i%7; j++ {
+					b.WriteString("\twidget()\n")
+				}
+				b.WriteString("}\n")
+				p := filepath.Join(root, fmt.Sprintf("p%03d", i), "a.go")
+				_ = os.MkdirAll(filepath.Dir(p), 0o755)
+				_ = os.WriteFile(p, []byte(b.String()), 0o644)
+			}
+			_ = os.MkdirAll(filepath.Join(root, "zzz"), 0o755)
+			_ = os.WriteFile(filepath.Join(root, "zzz", "widget.go"), []byte("package zzz\n\nfunc widget() {}\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			page := func(offset, limit int) []string {
+				results, err := Query(dbPath, root, "widget", Options{Store: storeName, Unit: "line", Offset: offset, Limit: limit})
+				if err != nil {
+					t.Fatalf("query: %v", err)
+				}
+				var out []string
+				for _, it := range results {
+					out = append(out, fmt.Sprintf("%s:%d", it.Path, it.Range.SL))
+				}
+				return out
+			}
+
+			all := page(0, 40)
+			if len(all) != 40 {
+				t.Fatalf("expected 40 results, got %d", len(all))
+			}
+			if !strings.HasPrefix(all[0], "zzz/widget.go:") {
+				t.Fatalf("expected the definition first: %v", all[:4])
+			}
+			if got := page(0, 4); strings.Join(got, ",") != strings.Join(all[:4], ",") {
+				t.Fatalf("limit 4 = %v, limit 40 starts %v", got, all[:4])
+			}
+			var pages []string
+			for off := 0; off < 40; off += 8 {
+				pages = append(pages, page(off, 8)...)
+			}
+			if strings.Join(pages, ",") != strings.Join(all, ",") {
+				t.Fatalf("pages differ from one query:\n%v\n%v", pages, all)
+			}
+		})
+	}
+}
+
+func TestQuery_In(t *testing.T) {
+	src := "package a\n\n// Fetch downloads with retries.\n// TODO: tune the retries\nfunc Fetch() {\n\tretries := 3 // retries left\n\t_ = retries\n}\n"
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			_ = os.WriteFile(filepath.Join(root, "a.go"), []byte(src), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			st, err := backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			if err := st.ReplaceSymbolsBatch(root, "a.go", []store.SymbolInput{
+				{Kind: "function", Name: "Fetch", SL: 5, SC: 1, EL: 8, EC: 2, Lang: "go", Signature: "func Fetch()"},
+			}); err != nil {
+				t.Fatalf("replace symbols: %v", err)
+			}
+			if err := st.ReplaceCommentsBatch(root, "a.go", []store.CommentInput{
+				{Kind: "line", Text: "// Fetch downloads with retries.", SL: 3, SC: 1, EL: 3, EC: 33, Lang: "go"},
+				{Kind: "line", Text: "// TODO: tune the retries", SL: 4, SC: 1, EL: 4, EC: 26, Lang: "go"},
+				{Kind: "line", Text: "// retries left", SL: 6, SC: 15, EL: 6, EC: 30, Lang: "go"},
+			}); err != nil {
+				t.Fatalf("replace comments: %v", err)
+			}
+			_ = st.Close()
+
+			results, err := Query(dbPath, root, "retries", Options{Store: storeName, In: "comments"})
+			if err != nil {
+				t.Fatalf("query comments: %v", err)
+			}
+			if len(results) != 3 {
+				t.Fatalf("expected 3 comment hits, got %+v", results)
+			}
+			for i, it := range results {
+				if it.Kind != "comment" || it.Range.SL != []int{3, 4, 6}[i] || it.Range.SL != it.Range.EL {
+					t.Fatalf("bad comment hit: %+v", it)
+				}
+				if it.Symbol == nil || it.Symbol.Name != "Fetch" || it.Title != "func Fetch()" {
+					t.Fatalf("bad attribution: %+v", it)
+				}
+			}
+
+			results, err = Query(dbPath, root, "retries", Options{Store: storeName, In: "code"})
+			if err != nil {
+				t.Fatalf("query code: %v", err)
+			}
+			if len(results) != 1 || len(results[0].Matches) != 2 || results[0].Matches[0].Line != 6 || results[0].Matches[0].Col != 2 {
+				t.Fatalf("bad code hits: %+v", results)
+			}
+
+			results, err = Query(dbPath, root, "TODO", Options{Store: storeName, In: "code"})
+			if err != nil {
+				t.Fatalf("query code: %v", err)
+			}
+			if len(results) != 0 {
+				t.Fatalf("expected no code hits: %+v", results)
+			}
+
+			if _, err := Query(dbPath, root, "retries", Options{Store: storeName, In: "docs"}); err == nil {
+				t.Fatalf("expected invalid in error")
+			}
+		})
+	}
+}
+
+func TestQuery_Boundary(t *testing.T) {
+	files := map[string]string{
+		"a.go": "package a\n\nfunc reopen() {}\n",
+		"b.go": "package b\n\nfunc OpenFile() {}\n",
+		"c.go": "package c\n\nfunc open() {}\n",
+		"d.go": "package d\n\nfunc do_open() {}\n",
+	}
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			for name, src := range files {
+				_ = os.WriteFile(filepath.Join(root, name), []byte(src), 0o644)
+			}
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			run := func(q string, opts Options) []model.ResultItem {
+				t.Helper()
+				opts.Store = storeName
+				opts.Unit = "line"
+				opts.Sort = "path"
+				items, err := Query(dbPath, root, q, opts)
+				if err != nil {
+					t.Fatalf("query %q: %v", q, err)
+				}
+				return items
+			}
+			paths := func(items []model.ResultItem) string {
+				var out []string
+				for _, it := range items {
+					out = append(out, it.Path)
+				}
+				return strings.Join(out, ",")
+			}
+
+			if got := paths(run("open", Options{Boundary: "word"})); got != "c.go" {
+				t.Fatalf("word: got %q", got)
+			}
+			items := run("open", Options{Boundary: "ident", CaseInsensitive: true})
+			if got := paths(items); got != "b.go,c.go,d.go" {
+				t.Fatalf("ident: got %q", got)
+			}
+			if m := items[0].Matches; len(m) != 1 || m[0].Line != 3 || m[0].Col != 6 {
+				t.Fatalf("ident matches: %+v", m)
+			}
+			if m := items[2].Matches; len(m) != 1 || m[0].Col != 9 {
+				t.Fatalf("ident matches: %+v", m)
+			}
+			if got := paths(run(`op.n`, Options{Regex: true, Boundary: "word"})); got != "c.go" {
+				t.Fatalf("regex word: got %q", got)
+			}
+			if _, err := Query(dbPath, root, `op.n`, Options{Store: storeName, Regex: true, Boundary: "ident"}); err == nil {
+				t.Fatalf("expected error for regex with ident boundary")
+			}
+			if _, err := Query(dbPath, root, "open", Options{Store: storeName, Boundary: "nope"}); err == nil {
+				t.Fatalf("expected invalid boundary error")
+			}
+		})
+	}
+}
+
+func TestQuery_NearAndAllTerms(t *testing.T) {
+	files := map[string]string{
+		"a.go": "package a\n\nfunc a() {\n\tmu.Lock()\n\tx++\n\tmu.Unlock()\n}\n",
+		"b.go": "package b\n\nfunc b() {\n\tmu.Lock()\n" + strings.Repeat("\tx++\n", 15) + "\tmu.Unlock()\n}\n",
+		"c.go": "package c\n\nfunc c() {\n\tmu.Lock(); defer mu.Unlock()\n}\n",
+	}
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			for name, src := range files {
+				_ = os.WriteFile(filepath.Join(root, name), []byte(src), 0o644)
+			}
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			paths := func(q string, opts Options) string {
+				t.Helper()
+				opts.Store = storeName
+				opts.Unit = "line"
+				opts.Sort = "path"
+				items, err := Query(dbPath, root, q, opts)
+				if err != nil {
+					t.Fatalf("query %q: %v", q, err)
+				}
+				var out []string
+				for _, it := range items {
+					out = append(out, it.Path)
+				}
+				return strings.Join(out, ",")
+			}
+
+			cases := []struct {
+				q    string
+				opts Options
+				want string
+			}{
+				{"lock unlock", Options{}, "a.go,b.go,c.go"},
+				{"lock unlock NEAR/5", Options{}, "a.go,c.go"},
+				{"lock unlock NEAR/20", Options{}, "a.go,b.go,c.go"},
+				{"lock NEAR/1 unlock", Options{}, "c.go"},
+				{"(lock unlock NEAR/0) OR nosuchterm", Options{}, "c.go"},
+				{"lock unlock", Options{AllTerms: true}, "c.go"},
+				{"lock unlock", Options{AllTerms: true, ContextLines: 2}, "a.go,c.go"},
+			}
+			for _, tc := range cases {
+				if got := paths(tc.q, tc.opts); got != tc.want {
+					t.Fatalf("%q %+v: got %q, want %q", tc.q, tc.opts, got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestQuery_AllTermsRangeFollowsAnchor(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			_ = os.WriteFile(filepath.Join(root, "a.txt"), []byte("alpha\n\nfiller\n\nbeta and alpha\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			for _, u := range []string{"block", "symbol", "line"} {
+				items, err := Query(dbPath, root, "alpha beta", Options{Store: storeName, Unit: u, AllTerms: true})
+				if err != nil {
+					t.Fatalf("query %s: %v", u, err)
+				}
+				if len(items) != 1 || items[0].Range.SL != 5 || items[0].Range.EL != 5 {
+					t.Fatalf("%s: expected range 5-5, got %+v", u, items)
+				}
+				if items[0].Matches[0].Line != 5 {
+					t.Fatalf("%s: expected the anchor match first: %+v", u, items[0].Matches)
+				}
+			}
+		})
+	}
+}
+
+func TestQuery_Fusion(t *testing.T) {
+	files := map[string]string{
+		"config.go": "package app\n\nvar loaded bool\n",
+		"main.go":   "package app\n\nfunc main() {\n\t_ = config\n}\n",
+	}
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			for name, src := range files {
+				_ = os.WriteFile(filepath.Join(root, name), []byte(src), 0o644)
+			}
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			kinds := func(opts Options) string {
+				t.Helper()
+				opts.Store = storeName
+				opts.Unit = "line"
+				items, err := Query(dbPath, root, "config", opts)
+				if err != nil {
+					t.Fatalf("query %+v: %v", opts, err)
+				}
+				var out []string
+				for _, it := range items {
+					out = append(out, it.Kind+":"+it.Path)
+				}
+				sort.Strings(out)
+				return strings.Join(out, ",")
+			}
+
+			if got, want := kinds(Options{}), "unit:main.go"; got != want {
+				t.Fatalf("text mode: got %q, want %q", got, want)
+			}
+			if got, want := kinds(Options{Mode: ModeHybrid}), "file:config.go,unit:main.go"; got != want {
+				t.Fatalf("hybrid mode: got %q, want %q", got, want)
+			}
+			if got, want := kinds(Options{Mode: ModeHybrid, Weights: map[string]float64{SourcePaths: 0}}), "unit:main.go"; got != want {
+				t.Fatalf("paths off: got %q, want %q", got, want)
+			}
+
+			if _, err := Query(dbPath, root, "config", Options{Store: storeName, Mode: "nope"}); err == nil {
+				t.Fatalf("expected invalid mode error")
+			}
+			if _, err := Query(dbPath, root, "config", Options{Store: storeName, Weights: map[string]float64{"nope": 1}}); err == nil {
+				t.Fatalf("expected unknown source error")
+			}
+		})
+	}
+}
+
+func TestQuery_Types(t *testing.T) {
+	files := map[string]string{
+		"main.go":        "package app\n\n// needle\n",
+		"go.mod":         "module needle\n",
+		"Makefile":       "build: # needle\n",
+		"tools/gen.py":   "# needle\n",
+		"tools/gen.mk":   "# needle\n",
+		"docs/needle.md": "needle\n",
+	}
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			for name, src := range files {
+				p := filepath.Join(root, filepath.FromSlash(name))
+				_ = os.MkdirAll(filepath.Dir(p), 0o755)
+				_ = os.WriteFile(p, []byte(src), 0o644)
+			}
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			paths := func(opts Options) string {
+				t.Helper()
+				opts.Store = storeName
+				opts.Unit = "line"
+				items, err := Query(dbPath, root, "needle", opts)
+				if err != nil {
+					t.Fatalf("query %+v: %v", opts, err)
+				}
+				seen := map[string]bool{}
+				var out []string
+				for _, it := range items {
+					if !seen[it.Path] {
+						seen[it.Path] = true
+						out = append(out, it.Path)
+					}
+				}
+				sort.Strings(out)
+				return strings.Join(out, ",")
+			}
+
+			if got, want := paths(Options{Types: []string{"go"}}), "go.mod,main.go"; got != want {
+				t.Fatalf("-t go: got %q, want %q", got, want)
+			}
+			if got, want := paths(Options{Types: []string{"make", "py"}}), "Makefile,tools/gen.mk,tools/gen.py"; got != want {
+				t.Fatalf("-t make -t py: got %q, want %q", got, want)
+			}
+			if got, want := paths(Options{TypesNot: []string{"go", "make"}}), "docs/needle.md,tools/gen.py"; got != want {
+				t.Fatalf("-T go -T make: got %q, want %q", got, want)
+			}
+			if got, want := paths(Options{Types: []string{"python"}, Regex: true}), "tools/gen.py"; got != want {
+				t.Fatalf("-t python --regex: got %q, want %q", got, want)
+			}
+			if _, err := Query(dbPath, root, "needle", Options{Store: storeName, Types: []string{"nope"}}); err == nil {
+				t.Fatalf("expected unknown type error")
+			}
+		})
+	}
+}
+
+func TestQuery_PathFilterIsExact(t *testing.T) {
+	files := map[string]string{
+		"src/a.go":       "package src\n\n// needle\n",
+		"src/b.go":       "package src\n\n// needle\n",
+		"src/sub/c.go":   "package sub\n\n// needle\n",
+		"src/tool.py":    "# needle\n",
+		"srcx/d.go":      "package srcx\n\n// needle\n",
+		"docs/needle.md": "needle\n",
+	}
+	for i := 0; i < 60; i++ {
+		files[fmt.Sprintf("gen/f%02d.go", i)] = "package gen\n\n// needle\n"
+	}
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			for name, src := range files {
+				p := filepath.Join(root, filepath.FromSlash(name))
+				_ = os.MkdirAll(filepath.Dir(p), 0o755)
+				_ = os.WriteFile(p, []byte(src), 0o644)
+			}
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			// The store applies the filter before the limit.
+			s, err := backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			filter, err := newPathFilter(pathSpec{Exclude: []string{"gen/*"}})
+			if err != nil {
+				t.Fatalf("filter: %v", err)
+			}
+			res, err := s.SearchChunks(root, "needle", store.SearchOptions{Limit: 3, Sort: store.SortPath, Filter: filter})
+			_ = s.Close()
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			var got []string
+			for _, c := range res.Chunks {
+				got = append(got, c.Path)
+			}
+			if want := "docs/needle.md,src/a.go,src/b.go"; strings.Join(got, ",") != want {
+				t.Fatalf("store filter: got %q, want %q", strings.Join(got, ","), want)
+			}
+
+			paths := func(opts Options) string {
+				t.Helper()
+				opts.Store = storeName
+				opts.Unit = "line"
+				opts.Sort = store.SortPath
+				items, err := Query(dbPath, root, "needle", opts)
+				if err != nil {
+					t.Fatalf("query %+v: %v", opts, err)
+				}
+				var out []string
+				for _, it := range items {
+					out = append(out, it.Path)
+				}
+				return strings.Join(out, ",")
+			}
+
+			if got, want := paths(Options{ExcludeGlobs: []string{"gen/*"}, Limit: 2, Offset: 1}), "src/a.go,src/b.go"; got != want {
+				t.Fatalf("-x gen/* page 2: got %q, want %q", got, want)
+			}
+			if got, want := paths(Options{IncludeGlobs: []string{"src/*.go"}}), "src/a.go,src/b.go"; got != want {
+				t.Fatalf("-g src/*.go: got %q, want %q", got, want)
+			}
+			if got, want := paths(Options{Paths: []string{"src/"}, Lang: "go"}), "src/a.go,src/b.go,src/sub/c.go"; got != want {
+				t.Fatalf("paths src, lang go: got %q, want %q", got, want)
+			}
+			if got, want := paths(Options{Paths: []string{"src"}, TypesNot: []string{"go"}, Regex: true}), "src/tool.py"; got != want {
+				t.Fatalf("paths src, -T go, regex: got %q, want %q", got, want)
+			}
+			if _, err := Query(dbPath, root, "needle", Options{Store: storeName, IncludeGlobs: []string{"[a-"}}); err == nil {
+				t.Fatalf("expected invalid glob error")
+			}
+			if _, err := Query(dbPath, root, "needle", Options{Store: storeName, Lang: "nope"}); err == nil {
+				t.Fatalf("expected unknown language error")
+			}
+		})
+	}
+}
+
+func TestDocumentedSymbol(t *testing.T) {
+	syms := []model.SymbolItem{
+		{Kind: "class", Name: "Foo", Range: model.Range{SL: 1, EL: 10}},
+		{Kind: "function", Name: "bar", Range: model.Range{SL: 5, EL: 9}},
+	}
+	comms := []model.CommentItem{
+		{Kind: "line", Range: model.Range{SL: 3, EL: 3}},
+		{Kind: "line", Range: model.Range{SL: 4, EL: 4}},
+		{Kind: "docstring", Range: model.Range{SL: 6, EL: 6}},
+		{Kind: "line", Range: model.Range{SL: 12, EL: 12}},
+	}
+	want := []string{"bar", "bar", "bar", ""}
+	for i, w := range want {
+		got := ""
+		if sym := documentedSymbol(comms, i, syms); sym != nil {
+			got = sym.Name
+		}
+		if got != w {
+			t.Fatalf("comment %d: got %q want %q", i, got, w)
+		}
+	}
+}
+
+func TestQuery_StoresAgreeOnSymbolChunks(t *testing.T) {
+	// LongFunc is cut into several windows; only the first names it.
+	var b strings.Builder
+	b.WriteString("package a\n\nfunc LongFunc() {\n")
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&b, "\tx%d := %d\n\t_ = x%d\n", i, i, i)
+	}
+	b.WriteString("}\n\nfunc caller() {\n\tLongFunc()\n}\n")
+
+	got := map[string]string{}
+	for _, storeName := range []string{"sqlite", "bleve"} {
+		root := t.TempDir()
+		_ = os.WriteFile(filepath.Join(root, "a.go"), []byte(b.String()), 0o644)
+		dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+		if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName, Chunking: indexer.ChunkingSymbols}); err != nil {
+			t.Fatalf("%s build: %v", storeName, err)
+		}
+		items, err := Query(dbPath, root, "LongFunc", Options{Store: storeName, Unit: "line", Sort: "path"})
+		if err != nil {
+			t.Fatalf("%s query: %v", storeName, err)
+		}
+		var out []string
+		for _, it := range items {
+			if len(it.Matches) == 0 {
+				t.Fatalf("%s: hit without a match: %+v", storeName, it)
+			}
+			out = append(out, fmt.Sprintf("%s:%d", it.Path, it.Range.SL))
+		}
+		got[storeName] = strings.Join(out, ",")
+	}
+	if got["sqlite"] != got["bleve"] || got["sqlite"] != "a.go:3,a.go:607" {
+		t.Fatalf("sqlite %q, bleve %q", got["sqlite"], got["bleve"])
+	}
+}
@@ -0,0 +1,188 @@
+package query
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+const (
+	// definitionBoost applies when the chunk defines a symbol named exactly
+	// like a query term (definition rather than usage).
+	definitionBoost = 1.0
+	// symbolHitBoost applies when a query term is part of a symbol name
+	// defined in the chunk.
+	symbolHitBoost = 0.5
+	// depthPenalty shrinks the score by this much per directory level.
+	depthPenalty  = 0.05
+	testPenalty   = 0.5
+	vendorPenalty = 0.3
+
+	// rankWindow is how many matches --sort score ranks, whatever the limit
+	// and offset: the first rankWindow in store order (bm25, or path order
+	// without FTS, with regex or --ident). Later matches follow unranked.
+	rankWindow = 2000
+)
+
+// definitionPrefix matches the text right before a name on a line that
+// declares it, for files without indexed symbols.
+var definitionPrefix = regexp.MustCompile(`(?i)(?:^|[^\w])(?:func|def|class|type|interface|struct|enum|trait|fn|function|module|record|object|protocol)\s+(?:\([^)]*\)\s*)?$`)
+
+// rankCandidates orders candidates by relevance: the backend score relative
+// to the best hit, raised by symbol boosts and lowered by path penalties.
+// Ties keep the backend order.
+func rankCandidates(s store.Store, workspaceID string, candidates []candidateRow, terms []string) {
+	if len(candidates) == 0 {
+		return
+	}
+
+	maxScore := 0.0
+	for _, c := range candidates {
+		if c.Score > maxScore {
+			maxScore = c.Score
+		}
+	}
+
+	lower := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			lower = append(lower, t)
+		}
+	}
+
+	var symsByPath map[string][]model.SymbolItem
+	if s != nil && len(lower) > 0 {
+		var paths []string
+		seen := map[string]bool{}
+		for _, c := range candidates {
+			if !seen[c.Path] {
+				seen[c.Path] = true
+				paths = append(paths, c.Path)
+			}
+		}
+		symsByPath, _ = s.ListSymbolsByPaths(workspaceID, paths)
+	}
+	for i := range candidates {
+		c := &candidates[i]
+		base := 1.0
+		if maxScore > 0 {
+			base = c.Score / maxScore
+		}
+
+		syms := symsByPath[c.Path]
+
+		boost := 1.0
+		def, hit := symbolBoosts(syms, c.SL, c.EL, lower)
+		if len(syms) == 0 {
+			def = textDefinesTerm(c.Text, lower)
+		}
+		if def {
+			boost += definitionBoost
+		}
+		if hit {
+			boost += symbolHitBoost
+		}
+		c.Score = base * boost * pathFactor(c.Path)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+}
+
+func symbolBoosts(syms []model.SymbolItem, sl int, el int, terms []string) (def bool, hit bool) {
+	for _, sym := range syms {
+		if sym.Range.SL < sl || sym.Range.SL > el || sym.Name == "" {
+			continue
+		}
+		name := strings.ToLower(sym.Name)
+		for _, t := range terms {
+			if name == t {
+				def = true
+				hit = true
+			} else if strings.Contains(name, t) {
+				hit = true
+			}
+		}
+	}
+	return def, hit
+}
+
+func textDefinesTerm(text string, terms []string) bool {
+	if text == "" || len(terms) == 0 {
+		return false
+	}
+	for _, line := range strings.Split(strings.ToLower(text), "\n") {
+		for _, t := range terms {
+			if strings.ContainsAny(t, " \t") {
+				continue
+			}
+			from := 0
+			for {
+				idx := strings.Index(line[from:], t)
+				if idx < 0 {
+					break
+				}
+				idx += from
+				end := idx + len(t)
+				if (end == len(line) || !isWordByte(line[end])) && definitionPrefix.MatchString(line[:idx]) {
+					return true
+				}
+				from = end
+			}
+		}
+	}
+	return false
+}
+
+func pathFactor(p string) float64 {
+	p = strings.ToLower(p)
+	f := 1 / (1 + depthPenalty*float64(strings.Count(p, "/")))
+	switch {
+	case isVendorPath(p):
+		f *= vendorPenalty
+	case isTestPath(p):
+		f *= testPenalty
+	}
+	return f
+}
+
+func isVendorPath(p string) bool {
+	for _, seg := range strings.Split(path.Dir(p), "/") {
+		switch seg {
+		case "vendor", "node_modules", "third_party", "thirdparty":
+			return true
+		}
+	}
+	return false
+}
+
+func isTestPath(p string) bool {
+	base := path.Base(p)
+	if strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") {
+		return true
+	}
+	if strings.HasPrefix(base, "test_") || strings.HasSuffix(strings.TrimSuffix(base, path.Ext(base)), "_test") {
+		return true
+	}
+	for _, seg := range strings.Split(path.Dir(p), "/") {
+		switch seg {
+		case "test", "tests", "__tests__", "testdata", "spec":
+			return true
+		}
+	}
+	return false
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+}
+
+func roundScore(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
@@ -0,0 +1,79 @@
+package query
+
+import (
+	"testing"
+
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+func TestRankCandidates_SymbolBoosts(t *testing.T) {
+	st := &fakeSymbolStore{syms: map[string][]model.SymbolItem{
+		"def.go": {{Name: "Open", Range: model.Range{SL: 3, EL: 5}}},
+		"hit.go": {{Name: "OpenFile", Range: model.Range{SL: 3, EL: 5}}},
+	}}
+	candidates := []candidateRow{
+		{Path: "use.go", SL: 1, EL: 10, Text: "x := Open()", Score: 1},
+		{Path: "hit.go", SL: 1, EL: 10, Text: "func OpenFile() {}", Score: 1},
+		{Path: "def.go", SL: 1, EL: 10, Text: "func Open() {}", Score: 1},
+	}
+	rankCandidates(st, "ws", candidates, []string{"open"})
+
+	if candidates[0].Path != "def.go" || candidates[1].Path != "hit.go" || candidates[2].Path != "use.go" {
+		t.Fatalf("unexpected order: %+v", candidates)
+	}
+}
+
+func TestTextDefinesTerm(t *testing.T) {
+	cases := map[string]bool{
+		"func Open() {}":                   true,
+		"func (s *Store) Open() error {":   true,
+		"class Open:":                      true,
+		"export function open(a) {":        true,
+		"x := Open()":                      false,
+		"func OpenFile() {}":               false,
+		"// see func documentation. Open.": false,
+	}
+	for text, want := range cases {
+		if got := textDefinesTerm(text, []string{"open"}); got != want {
+			t.Fatalf("textDefinesTerm(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestPathFactor(t *testing.T) {
+	if pathFactor("a.go") <= pathFactor("a/b/c.go") {
+		t.Fatalf("deeper paths should score lower")
+	}
+	if pathFactor("pkg/a_test.go") >= pathFactor("pkg/a.go") {
+		t.Fatalf("tests should score lower")
+	}
+	if pathFactor("vendor/x/a.go") >= pathFactor("pkg/a_test.go") {
+		t.Fatalf("vendored code should score lowest")
+	}
+	for _, p := range []string{"web/__tests__/a.js", "src/a.spec.ts", "test_a.py", "internal/x/testdata/a.go"} {
+		if !isTestPath(p) {
+			t.Fatalf("expected test path: %s", p)
+		}
+	}
+	if isTestPath("internal/latest/a.go") || isVendorPath("vendored.go") {
+		t.Fatalf("unexpected test/vendor match")
+	}
+}
+
+type fakeSymbolStore struct {
+	store.Store
+	syms map[string][]model.SymbolItem
+}
+
+func (f *fakeSymbolStore) ListSymbols(workspaceID string, path string) ([]model.SymbolItem, error) {
+	return f.syms[path], nil
+}
+
+func (f *fakeSymbolStore) ListSymbolsByPaths(workspaceID string, paths []string) (map[string][]model.SymbolItem, error) {
+	out := map[string][]model.SymbolItem{}
+	for _, p := range paths {
+		out[p] = f.syms[p]
+	}
+	return out, nil
+}
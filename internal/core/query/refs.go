@@ -0,0 +1,135 @@
+package query
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"otterindex/internal/core/lang"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+type RefOptions struct {
+	Store string
+	Kind  string // "ref", "call" or "import"; empty for all
+	Lang  string
+	Limit int
+}
+
+// FindRefs lists the uses of a name: identifier references, calls and
+// imports, each with the innermost symbol enclosing it. Qualified names
+// ("pkg.Func", "Type::method") are looked up by their last segment; names
+// containing '/' match import paths exactly.
+func FindRefs(dbPath string, workspaceID string, name string, opts RefOptions) ([]model.RefItem, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	name = strings.TrimSpace(name)
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 100
+	}
+	langName := strings.ToLower(strings.TrimSpace(opts.Lang))
+	if l, ok := lang.Lookup(langName); ok {
+		langName = l.Name
+	}
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	refs, err := s.FindRefs(workspaceID, refKey(name), store.RefSearchOptions{
+		Kind:  strings.TrimSpace(opts.Kind),
+		Lang:  langName,
+		Limit: opts.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ws, _ := s.GetWorkspace(workspaceID)
+	lines := newSourceLines(ws.Root)
+	symsByPath := map[string][]model.SymbolItem{}
+	for i := range refs {
+		r := &refs[i]
+		syms, ok := symsByPath[r.Path]
+		if !ok {
+			syms, _ = s.ListSymbols(workspaceID, r.Path)
+			symsByPath[r.Path] = syms
+		}
+		r.Symbol = enclosingSymbol(syms, r.Range)
+		r.Text = lines.line(r.Path, r.Range.SL)
+	}
+	return refs, nil
+}
+
+// sourceLines reads workspace files on demand to show the source line of a
+// use.
+type sourceLines struct {
+	root  string
+	files map[string][]string
+}
+
+func newSourceLines(root string) *sourceLines {
+	return &sourceLines{root: root, files: map[string][]string{}}
+}
+
+func (l *sourceLines) load(path string) []string {
+	if l.root == "" {
+		return nil
+	}
+	lines, ok := l.files[path]
+	if !ok {
+		lines = strings.Split(readFileText(filepath.Join(l.root, filepath.FromSlash(path))), "\n")
+		if n := len(lines); n > 0 && lines[n-1] == "" {
+			lines = lines[:n-1]
+		}
+		l.files[path] = lines
+	}
+	return lines
+}
+
+// line returns line n (1-based) of path with surrounding spaces trimmed.
+func (l *sourceLines) line(path string, n int) string {
+	lines := l.load(path)
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(lines[n-1], "\r"))
+}
+
+// span returns lines sl..el of path as they are in the file, or "" when the
+// file is missing or shorter.
+func (l *sourceLines) span(path string, sl int, el int) string {
+	lines := l.load(path)
+	if sl < 1 || el < sl || el > len(lines) {
+		return ""
+	}
+	return strings.Join(lines[sl-1:el], "\n")
+}
+
+// count returns the number of lines of path.
+func (l *sourceLines) count(path string) int {
+	return len(l.load(path))
+}
+
+func refKey(name string) string {
+	if strings.Contains(name, "/") {
+		return name
+	}
+	name = strings.ReplaceAll(name, "::", ".")
+	if i := strings.LastIndex(name, "."); i >= 0 && i < len(name)-1 {
+		name = name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+)
+
+func TestFindRefs(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			src := "package main\n\nimport \"os/exec\"\n\nfunc run() {\n\texec.Command(\"ls\")\n}\n"
+			_ = os.WriteFile(filepath.Join(root, "main.go"), []byte(src), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			st, err := backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			if err := st.ReplaceSymbolsBatch(root, "main.go", []store.SymbolInput{
+				{Kind: "function", Name: "run", SL: 5, SC: 1, EL: 7, EC: 2, Lang: "go"},
+			}); err != nil {
+				t.Fatalf("replace symbols: %v", err)
+			}
+			if err := st.ReplaceRefsBatch(root, "main.go", []store.RefInput{
+				{Kind: "import", Name: "os/exec", SL: 3, SC: 8, EL: 3, EC: 17, Lang: "go"},
+				{Kind: "ref", Name: "exec", SL: 6, SC: 2, EL: 6, EC: 6, Lang: "go"},
+				{Kind: "call", Name: "Command", SL: 6, SC: 7, EL: 6, EC: 14, Lang: "go"},
+			}); err != nil {
+				t.Fatalf("replace refs: %v", err)
+			}
+			_ = st.Close()
+
+			refs, err := FindRefs(dbPath, root, "exec.Command", RefOptions{Store: storeName})
+			if err != nil {
+				t.Fatalf("refs: %v", err)
+			}
+			if len(refs) != 1 || refs[0].Kind != "call" || refs[0].Text != `exec.Command("ls")` {
+				t.Fatalf("bad refs: %+v", refs)
+			}
+			if refs[0].Symbol == nil || refs[0].Symbol.Name != "run" {
+				t.Fatalf("bad enclosing symbol: %+v", refs[0].Symbol)
+			}
+
+			// Imports match by their last path segment.
+			refs, err = FindRefs(dbPath, root, "exec", RefOptions{Store: storeName})
+			if err != nil {
+				t.Fatalf("refs: %v", err)
+			}
+			if len(refs) != 2 || refs[0].Kind != "import" || refs[1].Kind != "ref" {
+				t.Fatalf("bad refs: %+v", refs)
+			}
+
+			refs, err = FindRefs(dbPath, root, "exec", RefOptions{Store: storeName, Kind: "import"})
+			if err != nil {
+				t.Fatalf("refs: %v", err)
+			}
+			if len(refs) != 1 || refs[0].Name != "os/exec" || refs[0].Symbol != nil {
+				t.Fatalf("bad refs: %+v", refs)
+			}
+		})
+	}
+}
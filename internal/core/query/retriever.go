@@ -0,0 +1,368 @@
+package query
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+// Retrieval sources that Options.Weights and the fusion modes refer to.
+const (
+	SourceChunks   = "chunks"
+	SourceSymbols  = "symbols"
+	SourcePaths    = "paths"
+	SourceComments = "comments"
+)
+
+// Fusion modes (Options.Mode). ModeText searches chunk text only; the others
+// also look up symbol names, file paths and comments and merge the lists.
+const (
+	ModeText    = "text"
+	ModeHybrid  = "hybrid"
+	ModeSymbols = "symbols"
+	ModeDocs    = "docs"
+)
+
+// rrfK is the rank offset of reciprocal-rank fusion: a candidate at rank r
+// (1-based) in a source adds weight/(rrfK+r). 60 is the usual choice; it keeps
+// one source's top hit from drowning agreement between sources.
+const rrfK = 60
+
+// auxWindow is how many candidates each auxiliary source returns. It does not
+// depend on the page, so every page is cut from the same fused list.
+const auxWindow = 100
+
+var sourceOrder = []string{SourceChunks, SourceSymbols, SourcePaths, SourceComments}
+
+// auxSources look up candidates for plain query terms; chunk text search is
+// set up by queryWithInfo itself.
+var auxSources = map[string]func(s store.Store, workspaceID string, lines *sourceLines, terms []string, limit int) ([]candidateRow, error){
+	SourceSymbols:  symbolCandidates,
+	SourcePaths:    pathCandidates,
+	SourceComments: commentCandidates,
+}
+
+var modeWeights = map[string]map[string]float64{
+	ModeText:    {SourceChunks: 1},
+	ModeHybrid:  {SourceChunks: 1, SourceSymbols: 1, SourcePaths: 0.5, SourceComments: 0.5},
+	ModeSymbols: {SourceChunks: 0.5, SourceSymbols: 2, SourcePaths: 0.5, SourceComments: 0.25},
+	ModeDocs:    {SourceChunks: 0.5, SourceSymbols: 0.5, SourceComments: 2},
+}
+
+// Modes lists the fusion modes.
+func Modes() []string {
+	return []string{ModeText, ModeHybrid, ModeSymbols, ModeDocs}
+}
+
+func normalizeMode(v string) string {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" {
+		return ModeText
+	}
+	return v
+}
+
+// fusionWeights returns the per-source weights of mode with overrides
+// applied; a zero weight turns a source off.
+func fusionWeights(mode string, overrides map[string]float64) (map[string]float64, error) {
+	base, ok := modeWeights[normalizeMode(mode)]
+	if !ok {
+		return nil, fmt.Errorf("invalid mode %q (expected: %s)", mode, strings.Join(Modes(), "|"))
+	}
+	out := map[string]float64{}
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range overrides {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if _, ok := modeWeights[ModeHybrid][k]; !ok {
+			return nil, fmt.Errorf("unknown retrieval source %q (expected: %s)", k, strings.Join(sourceOrder, "|"))
+		}
+		if v < 0 {
+			return nil, fmt.Errorf("weight of %s must be >= 0", k)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// isFused reports whether sources besides chunk text contribute.
+func isFused(weights map[string]float64) bool {
+	for k, v := range weights {
+		if k != SourceChunks && v > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func formatWeights(weights map[string]float64) string {
+	var parts []string
+	for _, k := range sourceOrder {
+		if v := weights[k]; v > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%g", k, v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// fusionKey is the cache key part for opts' mode and weights, empty for the
+// default text mode.
+func fusionKey(opts Options) string {
+	mode := normalizeMode(opts.Mode)
+	if mode == ModeText && len(opts.Weights) == 0 {
+		return ""
+	}
+	key := "|mode=" + mode
+	names := make([]string, 0, len(opts.Weights))
+	for k := range opts.Weights {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		key += fmt.Sprintf("|w.%s=%g", k, opts.Weights[k])
+	}
+	return key
+}
+
+// retriever produces candidates for one query from one source, best first.
+type retriever struct {
+	source string
+	run    func() ([]candidateRow, error)
+}
+
+// runRetrievers runs rs concurrently and returns their lists in order.
+func runRetrievers(rs []retriever) ([][]candidateRow, error) {
+	lists := make([][]candidateRow, len(rs))
+	errs := make([]error, len(rs))
+	var wg sync.WaitGroup
+	for i := range rs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lists[i], errs[i] = rs[i].run()
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rs[i].source, err)
+		}
+	}
+	return lists, nil
+}
+
+// fuseCandidates merges ranked lists with weighted reciprocal-rank fusion.
+// Candidates of the same kind covering the same lines are merged; the result is
+// ordered by fused score, or by path with sortBy "path".
+func fuseCandidates(rs []retriever, lists [][]candidateRow, weights map[string]float64, sortBy string) []candidateRow {
+	var out []candidateRow
+	index := map[string]int{}
+	for i, list := range lists {
+		w := weights[rs[i].source]
+		if w <= 0 {
+			continue
+		}
+		for rank, c := range list {
+			contrib := fmt.Sprintf("%s#%d", rs[i].source, rank+1)
+			score := w / float64(rrfK+rank+1)
+			key := fmt.Sprintf("%s|%s:%d:%d", c.Kind, c.Path, c.SL, c.EL)
+			if j, ok := index[key]; ok {
+				out[j].Score += score
+				out[j].Sources = append(out[j].Sources, contrib)
+				continue
+			}
+			c.Score = score
+			c.Sources = []string{contrib}
+			index[key] = len(out)
+			out = append(out, c)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if sortBy == store.SortPath {
+			if out[i].Path != out[j].Path {
+				return out[i].Path < out[j].Path
+			}
+			return out[i].SL < out[j].SL
+		}
+		return out[i].Score > out[j].Score
+	})
+	return out
+}
+
+// fusionSummary describes where the top candidates came from, for --explain.
+func fusionSummary(cands []candidateRow, n int) []string {
+	if len(cands) < n {
+		n = len(cands)
+	}
+	out := make([]string, 0, n)
+	for _, c := range cands[:n] {
+		out = append(out, fmt.Sprintf("%s:%d-%d %s %.4f", c.Path, c.SL, c.EL, strings.Join(c.Sources, "+"), c.Score))
+	}
+	return out
+}
+
+// symbolCandidates returns the definitions whose name contains every term,
+// exact names first; each candidate spans the symbol.
+func symbolCandidates(s store.Store, workspaceID string, lines *sourceLines, terms []string, limit int) ([]candidateRow, error) {
+	pattern := strings.Join(terms, "")
+	if len(terms) > 1 {
+		// Multi-word queries find camelCase and snake_case names.
+		pattern = terms[0]
+	}
+	syms, err := s.SearchSymbols(workspaceID, pattern, store.SymbolSearchOptions{Limit: limit * 20})
+	if err != nil {
+		return nil, err
+	}
+	var keep []model.SymbolItem
+	for _, sym := range syms {
+		name := strings.ToLower(sym.Name)
+		all := true
+		for _, t := range terms {
+			if !strings.Contains(name, strings.ToLower(t)) {
+				all = false
+				break
+			}
+		}
+		if all {
+			keep = append(keep, sym)
+		}
+	}
+	q := strings.Join(terms, "")
+	keep = rankSymbols(q, keep, limit)
+
+	out := make([]candidateRow, 0, len(keep))
+	for _, sym := range keep {
+		text := lines.span(sym.Path, sym.Range.SL, sym.Range.EL)
+		if text == "" {
+			continue
+		}
+		title := strings.TrimSpace(sym.Signature)
+		if title == "" {
+			title = sym.Name
+		}
+		out = append(out, candidateRow{Path: sym.Path, SL: sym.Range.SL, EL: sym.Range.EL, Text: text, Kind: "symbol", Title: title})
+	}
+	return out, nil
+}
+
+// pathCandidates returns the files whose name contains every term, files
+// named exactly like the query first; each candidate spans the file.
+func pathCandidates(s store.Store, workspaceID string, lines *sourceLines, terms []string, limit int) ([]candidateRow, error) {
+	files, err := s.ListFilesMeta(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	joined := strings.ToLower(strings.Join(terms, ""))
+	type scored struct {
+		path  string
+		score int
+	}
+	var hits []scored
+	for p := range files {
+		base := strings.ToLower(path.Base(p))
+		stem := strings.TrimSuffix(base, path.Ext(base))
+		flat := strings.NewReplacer("_", "", "-", "").Replace(stem)
+		all := true
+		for _, t := range terms {
+			if !strings.Contains(base, strings.ToLower(t)) {
+				all = false
+				break
+			}
+		}
+		if !all {
+			continue
+		}
+		score := 0
+		switch {
+		case flat == joined:
+			score = 2
+		case strings.HasPrefix(flat, joined):
+			score = 1
+		}
+		hits = append(hits, scored{path: p, score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		fi, fj := pathFactor(hits[i].path), pathFactor(hits[j].path)
+		if fi != fj {
+			return fi > fj
+		}
+		return hits[i].path < hits[j].path
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	out := make([]candidateRow, 0, len(hits))
+	for _, h := range hits {
+		n := lines.count(h.path)
+		if n == 0 {
+			continue
+		}
+		out = append(out, candidateRow{Path: h.path, SL: 1, EL: n, Text: lines.span(h.path, 1, n), Kind: "file", Title: path.Base(h.path)})
+	}
+	return out, nil
+}
+
+// commentCandidates returns the comments containing every term. Doc comments
+// of a symbol named like the query come first, then by path rank.
+func commentCandidates(s store.Store, workspaceID string, lines *sourceLines, terms []string, limit int) ([]candidateRow, error) {
+	comms, err := s.SearchComments(workspaceID, terms, store.CommentSearchOptions{Limit: limit * 20})
+	if err != nil {
+		return nil, err
+	}
+	joined := strings.ToLower(strings.Join(terms, ""))
+	ci := newCommentIndex(s, workspaceID)
+	type scored struct {
+		c     model.CommentItem
+		sym   *model.SymbolItem
+		score float64
+	}
+	hits := make([]scored, 0, len(comms))
+	for _, c := range comms {
+		h := scored{c: c, score: pathFactor(c.Path)}
+		all := ci.forPath(c.Path)
+		for i := range all {
+			if all[i].Range == c.Range {
+				h.sym = documentedSymbol(all, i, ci.symbolsFor(c.Path))
+				break
+			}
+		}
+		if h.sym != nil && strings.ToLower(h.sym.Name) == joined {
+			h.score += definitionBoost
+		}
+		hits = append(hits, h)
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].score > hits[j].score
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	out := make([]candidateRow, 0, len(hits))
+	for _, h := range hits {
+		text := lines.span(h.c.Path, h.c.Range.SL, h.c.Range.EL)
+		if text == "" {
+			continue
+		}
+		row := candidateRow{Path: h.c.Path, SL: h.c.Range.SL, EL: h.c.Range.EL, Text: text, Kind: "comment"}
+		if h.sym != nil {
+			row.Title = strings.TrimSpace(h.sym.Signature)
+			if row.Title == "" {
+				row.Title = h.sym.Name
+			}
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
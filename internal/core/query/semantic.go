@@ -0,0 +1,138 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"otterindex/internal/core/embed"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+type SemanticOptions struct {
+	Store        string
+	IncludeGlobs []string
+	ExcludeGlobs []string
+	Types        []string
+	TypesNot     []string
+	// Paths and Lang restrict results as in Options.
+	Paths []string
+	Lang  string
+	Limit int
+}
+
+// Semantic ranks chunks and functions by embedding similarity to q, using
+// the embedder that built the workspace's vectors. Overlapping hits in a file
+// are collapsed to the best one, and each item's snippet is its line closest
+// to q.
+func Semantic(dbPath string, workspaceID string, q string, opts SemanticOptions) ([]model.ResultItem, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	q = strings.TrimSpace(q)
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if q == "" {
+		return nil, fmt.Errorf("q is required")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+	filter, err := newPathFilter(pathSpec{
+		Include:  opts.IncludeGlobs,
+		Exclude:  opts.ExcludeGlobs,
+		Types:    opts.Types,
+		TypesNot: opts.TypesNot,
+		Paths:    opts.Paths,
+		Lang:     opts.Lang,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	name, err := s.VectorModel(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("index has no embeddings; run otidx index build --embed")
+	}
+	e, ok := embed.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown embedder %q", name)
+	}
+	qv := e.Embed(q)
+
+	fetch := opts.Limit * 4
+	hits, err := s.SearchVectors(workspaceID, qv, store.VectorSearchOptions{Model: name, Limit: fetch, Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	ws, _ := s.GetWorkspace(workspaceID)
+	lines := newSourceLines(ws.Root)
+	taken := map[string][]model.Range{}
+	out := make([]model.ResultItem, 0, opts.Limit)
+	for _, h := range hits {
+		if len(out) >= opts.Limit {
+			break
+		}
+		if h.Score <= 0 {
+			break
+		}
+		if overlapsAny(taken[h.Path], h.SL, h.EL) {
+			continue
+		}
+		taken[h.Path] = append(taken[h.Path], model.Range{SL: h.SL, EL: h.EL})
+		item := model.ResultItem{
+			Kind:  h.Kind,
+			Path:  h.Path,
+			Range: model.Range{SL: h.SL, SC: 1, EL: h.EL, EC: 1},
+			Title: h.Title,
+			Score: h.Score,
+		}
+		if n, line := closestLine(e, qv, lines, h.Path, h.SL, h.EL); n > 0 {
+			item.Snippet = line
+			item.Matches = []model.Match{{Line: n, Col: 1, Text: line}}
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func overlapsAny(rs []model.Range, sl int, el int) bool {
+	for _, r := range rs {
+		if sl <= r.EL && r.SL <= el {
+			return true
+		}
+	}
+	return false
+}
+
+// closestLine returns the number and text of the line in [sl, el] whose
+// embedding is most similar to qv, falling back to the first non-blank line.
+func closestLine(e embed.Embedder, qv []float32, lines *sourceLines, path string, sl int, el int) (int, string) {
+	bestN, best, bestScore := 0, "", 0.0
+	for n := sl; n <= el; n++ {
+		line := lines.line(path, n)
+		if line == "" {
+			continue
+		}
+		if bestN == 0 {
+			bestN, best = n, line
+		}
+		if score := embed.Cosine(qv, e.Embed(line)); score > bestScore {
+			bestN, best, bestScore = n, line, score
+		}
+	}
+	return bestN, best
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"otterindex/internal/core/embed"
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/index/backend"
+)
+
+func TestSemantic(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			files := map[string]string{
+				"writer.go": "package store\n\n// flush retries failed writes with backoff.\nfunc (w *Writer) flushWithRetry(recs []Record) error {\n\tfor attempt := 0; attempt < w.maxRetries; attempt++ {\n\t\tif err := w.write(recs); err == nil {\n\t\t\treturn nil\n\t\t}\n\t}\n\treturn errGiveUp\n}\n",
+				"config.go": "package store\n\nfunc parseConfig(path string) (*Config, error) {\n\tb, err := os.ReadFile(path)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\treturn decodeYAML(b)\n}\n",
+			}
+			for p, text := range files {
+				_ = os.WriteFile(filepath.Join(root, p), []byte(text), 0o644)
+			}
+			dbPath := backend.NormalizePath(storeName, filepath.Join(t.TempDir(), "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+			if _, err := Semantic(dbPath, root, "retry failed writes", SemanticOptions{Store: storeName}); err == nil {
+				t.Fatalf("expected error without embeddings")
+			}
+
+			e, _ := embed.Lookup("")
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName, Embedder: e}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+			items, err := Semantic(dbPath, root, "where do we retry failed writes", SemanticOptions{Store: storeName})
+			if err != nil {
+				t.Fatalf("semantic: %v", err)
+			}
+			if len(items) == 0 || items[0].Path != "writer.go" || items[0].Score <= 0 {
+				t.Fatalf("bad items: %+v", items)
+			}
+			if len(items[0].Matches) != 1 || items[0].Matches[0].Line != 3 {
+				t.Fatalf("bad snippet line: %+v", items[0])
+			}
+
+			items, err = Semantic(dbPath, root, "retry failed writes", SemanticOptions{Store: storeName, ExcludeGlobs: []string{"writer.go"}})
+			if err != nil {
+				t.Fatalf("semantic: %v", err)
+			}
+			for _, it := range items {
+				if it.Path == "writer.go" {
+					t.Fatalf("glob not applied: %+v", items)
+				}
+			}
+
+			// Updates keep embedding with the index's embedder.
+			_ = os.WriteFile(filepath.Join(root, "http.go"), []byte("package store\n\nfunc serveHTTP(addr string) error {\n\treturn http.ListenAndServe(addr, nil)\n}\n"), 0o644)
+			if err := indexer.UpdateFile(root, dbPath, "http.go", indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("update: %v", err)
+			}
+			items, err = Semantic(dbPath, root, "http server listen", SemanticOptions{Store: storeName, Limit: 1})
+			if err != nil {
+				t.Fatalf("semantic: %v", err)
+			}
+			if len(items) != 1 || items[0].Path != "http.go" {
+				t.Fatalf("updated file not embedded: %+v", items)
+			}
+		})
+	}
+}
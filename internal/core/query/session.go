@@ -17,6 +17,12 @@ type candidateRow struct {
 	EL      int
 	Text    string
 	Snippet string
+	Score   float64
+	// Kind and Title label candidates of other retrieval sources ("symbol",
+	// "file", "comment"); Sources records their fused ranks ("chunks#3").
+	Kind    string
+	Title   string
+	Sources []string
 }
 
 type SessionOptions struct {
@@ -88,7 +94,8 @@ func (s *SessionStore) ClearWorkspace(workspaceID string) {
 }
 
 func QueryWithSession(sess *SessionStore, version int64, dbPath string, workspaceID string, q string, opts Options) ([]model.ResultItem, error) {
-	if sess == nil {
+	if sess == nil || opts.Regex || normalizeIn(opts.In) != inAll || opts.Boundary != "" || normalizeMode(opts.Mode) != ModeText || len(opts.Weights) > 0 {
+		// Prefix narrowing only holds for plain substring queries over all text.
 		return Query(dbPath, workspaceID, q, opts)
 	}
 
@@ -97,7 +104,7 @@ func QueryWithSession(sess *SessionStore, version int64, dbPath string, workspac
 		prefetchMin = sess.maxCandidates
 	}
 	return queryWithSessionCommon(sess, version, dbPath, workspaceID, q, opts, func(dbPath string, workspaceID string, q string, opts Options) ([]model.ResultItem, queryInfo, error) {
-		return queryWithInfo(dbPath, workspaceID, q, opts, prefetchMin)
+		return queryWithInfo(dbPath, workspaceID, q, opts, prefetchMin, 0)
 	})
 }
 
@@ -155,13 +162,14 @@ func queryWithSessionCommon(sess *SessionStore, version int64, dbPath string, wo
 		ses.candidates = nil
 	}
 
-	if ses != nil && isPrefixExtension(ses.lastQ, q, sess.minPrefixLen, opts.CaseInsensitive || ses.hasFTS) && len(ses.candidates) > 0 {
+	// Narrowing by substring is only sound for plain conjunctions of terms.
+	tokens, simple := simpleQueryTerms(q)
+	if ses != nil && simple {
+		_, simple = simpleQueryTerms(ses.lastQ)
+	}
+	if ses != nil && simple && isPrefixExtension(ses.lastQ, q, sess.minPrefixLen, opts.CaseInsensitive || ses.hasFTS) && len(ses.candidates) > 0 {
 		narrowIn = len(ses.candidates)
 
-		tokens := extractQueryTerms(q)
-		if len(tokens) == 0 {
-			tokens = []string{q}
-		}
 		narrowed := narrowCandidates(ses.candidates, tokens, opts.CaseInsensitive || ses.hasFTS)
 
 		truncated := false
@@ -306,7 +314,7 @@ func queryFromCandidates(dbPath string, workspaceID string, q string, opts Optio
 	}
 
 	matchCaseInsensitive := opts.CaseInsensitive
-	if env.hasFTS {
+	if env.hasFTS && !opts.Regex {
 		matchCaseInsensitive = true
 	}
 	if ex != nil {
@@ -318,7 +326,7 @@ func queryFromCandidates(dbPath string, workspaceID string, q string, opts Optio
 		return nil, fmt.Errorf("limit+offset overflow")
 	}
 
-	pathTopN := 3
+	pathTopN := maxPerFile(opts)
 	if ex != nil {
 		ex.KV("dedupe_topn", pathTopN)
 		if env.fetchN > 0 {
@@ -330,7 +338,7 @@ func queryFromCandidates(dbPath string, workspaceID string, q string, opts Optio
 	if ex != nil {
 		stopMatch = ex.Timer("match")
 	}
-	items, err := buildItemsFromCandidates(env.candidates, q, opts, matchCaseInsensitive, pathTopN, wantN, ex)
+	items, _, err := buildItemsFromCandidates(env.candidates, q, opts, matchCaseInsensitive, pathTopN, wantN, nil, ex)
 	stopMatch()
 	if err != nil {
 		return nil, err
@@ -384,7 +392,33 @@ func makeSessionKey(dbPath string, workspaceID string, opts Options) string {
 	_, _ = fmt.Fprintf(&b, "|unit=%s|i=%t", opts.Unit, opts.CaseInsensitive)
 	_, _ = fmt.Fprintf(&b, "|limit=%d|offset=%d", opts.Limit, opts.Offset)
 	_, _ = fmt.Fprintf(&b, "|ctx=%d", opts.ContextLines)
+	if opts.Regex {
+		b.WriteString("|re=1")
+	}
+	_, _ = fmt.Fprintf(&b, "|sort=%s", normalizeSort(opts.Sort))
+	_, _ = fmt.Fprintf(&b, "|in=%s", normalizeIn(opts.In))
+	if opts.Boundary != "" {
+		_, _ = fmt.Fprintf(&b, "|b=%s", opts.Boundary)
+	}
+	if opts.AllTerms {
+		b.WriteString("|all=1")
+	}
 
+	if len(opts.Types) > 0 {
+		_, _ = fmt.Fprintf(&b, "|t=%s", strings.Join(opts.Types, ","))
+	}
+	if len(opts.TypesNot) > 0 {
+		_, _ = fmt.Fprintf(&b, "|T=%s", strings.Join(opts.TypesNot, ","))
+	}
+	if len(opts.Paths) > 0 {
+		_, _ = fmt.Fprintf(&b, "|p=%s", strings.Join(opts.Paths, ","))
+	}
+	if opts.Lang != "" {
+		_, _ = fmt.Fprintf(&b, "|lang=%s", opts.Lang)
+	}
+	if opts.MaxPerFile != 0 {
+		_, _ = fmt.Fprintf(&b, "|mpf=%d", opts.MaxPerFile)
+	}
 	if len(opts.IncludeGlobs) > 0 {
 		inc := append([]string(nil), opts.IncludeGlobs...)
 		sort.Strings(inc)
@@ -36,6 +36,16 @@ func TestSessionPrefixReuse_NoSQL(t *testing.T) {
 	if sqlCalls != 1 {
 		t.Fatalf("expected sqlCalls still 1, got %d", sqlCalls)
 	}
+	// A different per-file cap keeps results apart.
+	capped := base
+	capped.MaxPerFile = 1
+	_, err = queryWithSessionFetch(sess, ver, "index.db", "ws1", "hello", capped, sqlFn)
+	if err != nil {
+		t.Fatalf("q3: %v", err)
+	}
+	if sqlCalls != 2 {
+		t.Fatalf("expected a new fetch for another max_per_file, sqlCalls=%d", sqlCalls)
+	}
 }
 
 func TestNarrowCandidates_ANDTokens(t *testing.T) {
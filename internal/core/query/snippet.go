@@ -4,9 +4,11 @@ import (
 	"sort"
 	"strings"
 	"unicode"
+
+	"otterindex/internal/core/search"
 )
 
-func buildSnippetFromMatchLine(line string, col int, q string, caseInsensitive bool) string {
+func buildSnippetFromMatchLine(line string, col int, q string, caseInsensitive bool, boundary string) string {
 	line = strings.TrimRight(line, " \t\r")
 	if strings.TrimSpace(line) == "" {
 		return ""
@@ -23,7 +25,7 @@ func buildSnippetFromMatchLine(line string, col int, q string, caseInsensitive b
 			if term == "" {
 				continue
 			}
-			if hasTermAt(line, idx, term, caseInsensitive) {
+			if hasTermAt(line, idx, term, caseInsensitive) && search.AtBoundary(line, idx, idx+len(term), boundary) {
 				return windowedHighlight(line, idx, idx+len(term))
 			}
 		}
@@ -34,7 +36,7 @@ func buildSnippetFromMatchLine(line string, col int, q string, caseInsensitive b
 			if term == "" {
 				continue
 			}
-			pos := indexOfTerm(line, term, caseInsensitive)
+			pos := search.IndexAt(line, term, caseInsensitive, boundary)
 			if pos < 0 {
 				continue
 			}
@@ -58,13 +60,34 @@ func buildSnippetFromMatchLine(line string, col int, q string, caseInsensitive b
 	return windowedHighlight(line, start, end)
 }
 
+func buildSnippetFromSpan(line string, col int, n int) string {
+	line = strings.TrimRight(line, " \t\r")
+	if strings.TrimSpace(line) == "" {
+		return ""
+	}
+	start := col - 1
+	if start < 0 || start >= len(line) || n <= 0 {
+		return strings.TrimSpace(line)
+	}
+	return windowedHighlight(line, start, start+n)
+}
+
 func snippetCandidates(q string) []string {
 	q = strings.TrimSpace(q)
 	if q == "" {
 		return nil
 	}
 
-	terms := extractQueryTerms(q)
+	var terms []string
+	seen := map[string]bool{}
+	for _, t := range highlightTerms(q) {
+		for _, v := range append([]string{t}, extractQueryTerms(t)...) {
+			if !seen[v] {
+				seen[v] = true
+				terms = append(terms, v)
+			}
+		}
+	}
 	if len(terms) == 0 {
 		terms = []string{q}
 	}
@@ -88,13 +111,6 @@ func hasTermAt(line string, idx int, term string, caseInsensitive bool) bool {
 	return strings.EqualFold(line[idx:idx+len(term)], term)
 }
 
-func indexOfTerm(line string, term string, caseInsensitive bool) int {
-	if !caseInsensitive {
-		return strings.Index(line, term)
-	}
-	return strings.Index(strings.ToLower(line), strings.ToLower(term))
-}
-
 func windowedHighlight(line string, start int, end int) string {
 	if start < 0 {
 		start = 0
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"otterindex/internal/core/fuzzy"
+	"otterindex/internal/core/lang"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+type SymbolOptions struct {
+	Store string
+	Kind  string
+	Lang  string
+	Limit int
+}
+
+// Container and signature only match as substrings and rank below any name
+// match (fuzzy scores are positive).
+const (
+	containerScore = 0
+	signatureScore = -10000
+)
+
+// SearchSymbols looks symbols up by name: exact, prefix, camelCase
+// abbreviation ("NRC" finds NewRootCommand) and fuzzy matches, best first.
+// "Container.Name" queries match qualified names; container and signature
+// substrings match too, ranked last.
+func SearchSymbols(dbPath string, workspaceID string, q string, opts SymbolOptions) ([]model.SymbolItem, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	q = strings.TrimSpace(q)
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, fmt.Errorf("dbPath is required")
+	}
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if q == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+	langName := strings.ToLower(strings.TrimSpace(opts.Lang))
+	if l, ok := lang.Lookup(langName); ok {
+		langName = l.Name
+	}
+
+	s, err := backend.Open(opts.Store, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	cands, err := s.SearchSymbols(workspaceID, strings.ReplaceAll(q, "::", "."), store.SymbolSearchOptions{
+		Kind:  strings.TrimSpace(opts.Kind),
+		Lang:  langName,
+		Limit: opts.Limit,
+		Score: func(sym model.SymbolItem) (int, bool) { return scoreSymbol(q, sym) },
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rankSymbols(q, cands, opts.Limit), nil
+}
+
+// rankSymbols keeps the symbols matching q, best first, at most limit.
+func rankSymbols(q string, cands []model.SymbolItem, limit int) []model.SymbolItem {
+	return store.BestSymbols(cands, func(sym model.SymbolItem) (int, bool) { return scoreSymbol(q, sym) }, limit)
+}
+
+func scoreSymbol(q string, sym model.SymbolItem) (int, bool) {
+	best, ok := 0, false
+	consider := func(score int) {
+		if !ok || score > best {
+			best, ok = score, true
+		}
+	}
+
+	if r, hit := fuzzy.Match(q, sym.Name); hit {
+		consider(r.Score)
+	}
+	qualified := strings.ReplaceAll(q, "::", ".")
+	if sym.Container != "" && strings.Contains(qualified, ".") {
+		if r, hit := fuzzy.Match(qualified, sym.Container+"."+sym.Name); hit {
+			consider(r.Score)
+		}
+	}
+
+	lq := strings.ToLower(q)
+	if sym.Container != "" && strings.Contains(strings.ToLower(sym.Container), lq) {
+		consider(containerScore - len(sym.Name))
+	}
+	if sym.Signature != "" && strings.Contains(strings.ToLower(sym.Signature), lq) {
+		consider(signatureScore - len(sym.Name))
+	}
+	return best, ok
+}
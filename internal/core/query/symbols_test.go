@@ -0,0 +1,100 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"otterindex/internal/core/indexer"
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
+)
+
+func TestSearchSymbols(t *testing.T) {
+	stores := []string{"sqlite", "bleve"}
+	for _, storeName := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			root := t.TempDir()
+			_ = os.WriteFile(filepath.Join(root, "root.go"), []byte("package cli\n"), 0o644)
+			_ = os.WriteFile(filepath.Join(root, "tool.py"), []byte("pass\n"), 0o644)
+			dbPath := backend.NormalizePath(storeName, filepath.Join(root, "index.db"))
+			if err := indexer.Build(root, dbPath, indexer.Options{Store: storeName}); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			st, err := backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			if err := st.ReplaceSymbolsBatch(root, "root.go", []store.SymbolInput{
+				{Kind: "function", Name: "NewRootCommand", SL: 10, EL: 20, Lang: "go", Signature: "func NewRootCommand() *cobra.Command"},
+				{Kind: "function", Name: "NewQCommand", SL: 30, EL: 40, Lang: "go", Signature: "func NewQCommand() *cobra.Command"},
+				{Kind: "method", Name: "Prepare", Container: "Options", SL: 50, EL: 60, Lang: "go", Signature: "func (o *Options) Prepare() error"},
+				{Kind: "function", Name: "newRoot", SL: 70, EL: 80, Lang: "go"},
+			}); err != nil {
+				t.Fatalf("replace symbols: %v", err)
+			}
+			if err := st.ReplaceSymbolsBatch(root, "tool.py", []store.SymbolInput{
+				{Kind: "function", Name: "new_root_command", SL: 1, EL: 2, Lang: "python"},
+			}); err != nil {
+				t.Fatalf("replace symbols: %v", err)
+			}
+			_ = st.Close()
+
+			search := func(q string, opts SymbolOptions) []string {
+				t.Helper()
+				opts.Store = storeName
+				syms, err := SearchSymbols(dbPath, root, q, opts)
+				if err != nil {
+					t.Fatalf("search %q: %v", q, err)
+				}
+				var names []string
+				for _, s := range syms {
+					names = append(names, s.Name)
+				}
+				return names
+			}
+
+			if got := search("NRC", SymbolOptions{}); len(got) < 2 || got[0] != "NewRootCommand" || got[1] != "new_root_command" {
+				t.Fatalf("camelCase: %v", got)
+			}
+			if got := search("newroot", SymbolOptions{}); len(got) < 2 || got[0] != "newRoot" || got[1] != "NewRootCommand" {
+				t.Fatalf("exact/prefix: %v", got)
+			}
+			if got := search("Options.Prepare", SymbolOptions{}); len(got) != 1 || got[0] != "Prepare" {
+				t.Fatalf("qualified: %v", got)
+			}
+			if got := search("New", SymbolOptions{Kind: "method"}); len(got) != 0 {
+				t.Fatalf("kind filter: %v", got)
+			}
+			if got := search("NRC", SymbolOptions{Lang: "py"}); len(got) != 1 || got[0] != "new_root_command" {
+				t.Fatalf("lang filter: %v", got)
+			}
+			if got := search("cobra", SymbolOptions{}); len(got) != 2 {
+				t.Fatalf("signature: %v", got)
+			}
+			if got := search("NewCommand", SymbolOptions{Limit: 1}); len(got) != 1 {
+				t.Fatalf("limit: %v", got)
+			}
+
+			// Weaker matches that sort first by path and name length must not
+			// crowd the best one out of the candidates.
+			st, err = backend.Open(storeName, dbPath)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			var decoys []store.SymbolInput
+			for i := 0; i < 1500; i++ {
+				decoys = append(decoys, store.SymbolInput{Kind: "function", Name: fmt.Sprintf("nxrxc%04d", i), SL: i + 1, EL: i + 1, Lang: "go"})
+			}
+			if err := st.ReplaceSymbolsBatch(root, "aaa.go", decoys); err != nil {
+				t.Fatalf("replace decoys: %v", err)
+			}
+			_ = st.Close()
+			if got := search("NRC", SymbolOptions{Limit: 1}); len(got) != 1 || got[0] != "NewRootCommand" {
+				t.Fatalf("ranked before limit: %v", got)
+			}
+		})
+	}
+}
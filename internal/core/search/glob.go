@@ -0,0 +1,101 @@
+package search
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Glob is a compiled -g/-x pattern. Patterns without a '/' match the
+// basename; others match the whole slash-separated path. As with path.Match,
+// '*' and '?' never match '/'.
+type Glob struct {
+	Pattern string
+	expr    string
+	re      *regexp.Regexp
+}
+
+// CompileGlob compiles one pattern. Backslashes are treated as '/'.
+func CompileGlob(pattern string) (Glob, error) {
+	pat := strings.TrimSpace(strings.ReplaceAll(pattern, "\\", "/"))
+	if pat == "" {
+		return Glob{}, fmt.Errorf("glob is required")
+	}
+	if _, err := path.Match(pat, ""); err != nil {
+		return Glob{}, fmt.Errorf("invalid glob %q", pattern)
+	}
+	expr := globExpr(pat)
+	if !strings.Contains(pat, "/") {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return Glob{}, fmt.Errorf("invalid glob %q", pattern)
+	}
+	return Glob{Pattern: pat, expr: expr, re: re}, nil
+}
+
+// CompileGlobs compiles patterns, splitting comma-separated lists and
+// skipping blanks.
+func CompileGlobs(patterns []string) ([]Glob, error) {
+	var out []Glob
+	for _, p := range patterns {
+		for _, piece := range strings.Split(p, ",") {
+			if strings.TrimSpace(piece) == "" {
+				continue
+			}
+			g, err := CompileGlob(piece)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+// Match reports whether the slash-separated path rel matches.
+func (g Glob) Match(rel string) bool {
+	return g.re != nil && g.re.MatchString(strings.ReplaceAll(rel, "\\", "/"))
+}
+
+// Regexp returns the RE2 expression that matches exactly the paths Match
+// accepts. It has no anchors: callers anchor it (or rely on whole-term
+// matching, as Bleve does).
+func (g Glob) Regexp() string {
+	return g.expr
+}
+
+// globExpr translates path.Match syntax into RE2.
+func globExpr(pat string) string {
+	var b strings.Builder
+	for i := 0; i < len(pat); i++ {
+		switch pat[i] {
+		case '*':
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			// path.Match already rejected malformed classes, so the
+			// closing ']' is there.
+			j := i + 1
+			b.WriteString("[")
+			if pat[j] == '^' {
+				b.WriteString("^")
+				j++
+			}
+			for ; pat[j] != ']'; j++ {
+				if strings.IndexByte(`\[^`, pat[j]) >= 0 {
+					b.WriteByte('\\')
+				}
+				b.WriteByte(pat[j])
+			}
+			b.WriteString("]")
+			i = j
+		default:
+			b.WriteString(regexp.QuoteMeta(pat[i : i+1]))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package search
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGlobMatchesLikePathMatch(t *testing.T) {
+	patterns := []string{"*.go", "a*.go", "internal/*.go", "internal/*/*.go", "?.md", "[a-c]*.txt", "[^a]*.txt", "go.mod", "docs/a.b", "ü*.go"}
+	paths := []string{"main.go", "a.go", "abc.go", "internal/x.go", "internal/core/x.go", "x/a/b.go", "a.md", "ab.md",
+		"b.txt", "d.txt", "x/a.txt", "go.mod", "x/go.mod", "docs/a.b", "docs/axb", "über.go", "x/über.go"}
+	for _, pat := range patterns {
+		g, err := CompileGlob(pat)
+		if err != nil {
+			t.Fatalf("compile %q: %v", pat, err)
+		}
+		re := regexp.MustCompile("^(?:" + g.Regexp() + ")$")
+		for _, p := range paths {
+			target := p
+			if !strings.Contains(pat, "/") {
+				target = path.Base(p)
+			}
+			want, _ := path.Match(pat, target)
+			if got := g.Match(p); got != want {
+				t.Fatalf("%q on %q: Match=%v want %v", pat, p, got, want)
+			}
+			if got := re.MatchString(p); got != want {
+				t.Fatalf("%q on %q: Regexp=%v want %v", pat, p, got, want)
+			}
+		}
+	}
+}
+
+func TestCompileGlobs(t *testing.T) {
+	gs, err := CompileGlobs([]string{"*.js, *.sql", "", `docs\*.md`})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	var pats []string
+	for _, g := range gs {
+		pats = append(pats, g.Pattern)
+	}
+	if got := strings.Join(pats, " "); got != "*.js *.sql docs/*.md" {
+		t.Fatalf("patterns=%q", got)
+	}
+	if _, err := CompileGlobs([]string{"[a-"}); err == nil {
+		t.Fatalf("expected invalid glob error")
+	}
+}
@@ -0,0 +1,274 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxExactSet   = 16
+	maxLiteralOrs = 32
+)
+
+func CompileRegex(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, fmt.Errorf("regex is required")
+	}
+	if caseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	return re, nil
+}
+
+// FindRegexInText reports the first match of re on every line of text.
+// Col is 1-based and Len is the match length in bytes.
+func FindRegexInText(text string, re *regexp.Regexp) []Match {
+	if re == nil {
+		return nil
+	}
+	var out []Match
+	for i, line := range strings.Split(text, "\n") {
+		loc := re.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		out = append(out, Match{
+			Line: i + 1,
+			Col:  loc[0] + 1,
+			Len:  loc[1] - loc[0],
+			Text: line,
+		})
+	}
+	return out
+}
+
+func RegexMatchesText(text string, re *regexp.Regexp) bool {
+	if re == nil {
+		return false
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegexLiterals returns the literal substrings any match of pattern must
+// contain, as an OR of AND groups. Literals are lower-cased and at least three
+// runes long so they can be looked up in a case-folded trigram index. A nil
+// result means the pattern cannot be used to prune candidates.
+func RegexLiterals(pattern string) [][]string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	info := analyzeRegex(re.Simplify())
+	groups := info.required()
+
+	var out [][]string
+	for _, g := range groups {
+		var kept []string
+		for _, lit := range g {
+			if utf8.RuneCountInString(lit) >= 3 {
+				kept = append(kept, lit)
+			}
+		}
+		if len(kept) == 0 {
+			return nil
+		}
+		out = append(out, kept)
+	}
+	return out
+}
+
+// Trigrams splits a literal into its overlapping three-rune substrings.
+func Trigrams(lit string) []string {
+	runes := []rune(lit)
+	if len(runes) < 3 {
+		return nil
+	}
+	seen := map[string]bool{}
+	var out []string
+	for i := 0; i+3 <= len(runes); i++ {
+		tri := string(runes[i : i+3])
+		if seen[tri] {
+			continue
+		}
+		seen[tri] = true
+		out = append(out, tri)
+	}
+	return out
+}
+
+// regexInfo describes what a regexp node can match: either a small exact set
+// of strings, or a set of literals that every match contains (nil = anything).
+type regexInfo struct {
+	exact []string
+	req   [][]string
+}
+
+func (ri regexInfo) required() [][]string {
+	if ri.exact == nil {
+		return ri.req
+	}
+	out := make([][]string, 0, len(ri.exact))
+	for _, s := range ri.exact {
+		if s == "" {
+			return nil
+		}
+		out = append(out, []string{s})
+	}
+	return out
+}
+
+func analyzeRegex(re *syntax.Regexp) regexInfo {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return regexInfo{exact: []string{strings.ToLower(string(re.Rune))}}
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return regexInfo{exact: []string{""}}
+	case syntax.OpCharClass:
+		var set []string
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			lo, hi := re.Rune[i], re.Rune[i+1]
+			if int(hi-lo)+len(set) >= maxExactSet {
+				return regexInfo{}
+			}
+			for r := lo; r <= hi; r++ {
+				s := strings.ToLower(string(r))
+				if !seen[s] {
+					seen[s] = true
+					set = append(set, s)
+				}
+			}
+		}
+		if len(set) == 0 {
+			return regexInfo{}
+		}
+		return regexInfo{exact: set}
+	case syntax.OpCapture:
+		return analyzeRegex(re.Sub[0])
+	case syntax.OpPlus:
+		return regexInfo{req: analyzeRegex(re.Sub[0]).required()}
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return regexInfo{req: analyzeRegex(re.Sub[0]).required()}
+		}
+		return regexInfo{}
+	case syntax.OpConcat:
+		return analyzeConcat(re.Sub)
+	case syntax.OpAlternate:
+		infos := make([]regexInfo, len(re.Sub))
+		allExact := true
+		n := 0
+		for i, sub := range re.Sub {
+			infos[i] = analyzeRegex(sub)
+			if infos[i].exact == nil {
+				allExact = false
+			}
+			n += len(infos[i].exact)
+		}
+		if allExact && n <= maxExactSet {
+			var exact []string
+			for _, info := range infos {
+				exact = append(exact, info.exact...)
+			}
+			return regexInfo{exact: exact}
+		}
+		var req [][]string
+		for _, info := range infos {
+			r := info.required()
+			if r == nil {
+				return regexInfo{}
+			}
+			req = append(req, r...)
+		}
+		if len(req) > maxLiteralOrs {
+			return regexInfo{}
+		}
+		return regexInfo{req: req}
+	default:
+		return regexInfo{}
+	}
+}
+
+func analyzeConcat(subs []*syntax.Regexp) regexInfo {
+	cur := []string{""}
+	var req [][]string
+	allExact := true
+
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		req = andLiterals(req, regexInfo{exact: cur}.required())
+		cur = nil
+	}
+
+	for _, sub := range subs {
+		info := analyzeRegex(sub)
+		if info.exact != nil {
+			if cur != nil && len(cur)*len(info.exact) <= maxExactSet {
+				cur = crossStrings(cur, info.exact)
+				continue
+			}
+			flush()
+			cur = info.exact
+			allExact = false
+			continue
+		}
+		allExact = false
+		flush()
+		req = andLiterals(req, info.req)
+	}
+	if allExact {
+		return regexInfo{exact: cur}
+	}
+	flush()
+	return regexInfo{req: req}
+}
+
+func crossStrings(a []string, b []string) []string {
+	out := make([]string, 0, len(a)*len(b))
+	for _, x := range a {
+		for _, y := range b {
+			out = append(out, x+y)
+		}
+	}
+	return out
+}
+
+func andLiterals(a [][]string, b [][]string) [][]string {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if len(a)*len(b) > maxLiteralOrs {
+		if len(a) <= len(b) {
+			return a
+		}
+		return b
+	}
+	out := make([][]string, 0, len(a)*len(b))
+	for _, x := range a {
+		for _, y := range b {
+			g := make([]string, 0, len(x)+len(y))
+			g = append(g, x...)
+			g = append(g, y...)
+			out = append(out, g)
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindRegexInText_ReportsColumnAndLength(t *testing.T) {
+	re, err := CompileRegex(`func \w+Handler\(`, false)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	ms := FindRegexInText("package x\n\nfunc fooHandler(w, r) {}\n", re)
+	if len(ms) != 1 {
+		t.Fatalf("matches=%v", ms)
+	}
+	if ms[0].Line != 3 || ms[0].Col != 1 || ms[0].Len != len("func fooHandler(") {
+		t.Fatalf("match=%+v", ms[0])
+	}
+}
+
+func TestCompileRegex_Invalid(t *testing.T) {
+	if _, err := CompileRegex(`(`, false); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestRegexLiterals(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    [][]string
+	}{
+		{`func \w+Handler\(`, [][]string{{"func ", "handler("}}},
+		{`fo|barbaz`, nil},
+		{`fooo|barbaz`, [][]string{{"fooo"}, {"barbaz"}}},
+		{`\w+`, nil},
+		{`(?i)Hello`, [][]string{{"hello"}}},
+		{`ab[cd]ef`, [][]string{{"abcef"}, {"abdef"}}},
+		{`x(abc)+y`, [][]string{{"abc"}}},
+	}
+	for _, tc := range cases {
+		got := RegexLiterals(tc.pattern)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("RegexLiterals(%q)=%q want %q", tc.pattern, got, tc.want)
+		}
+	}
+}
+
+func TestTrigrams(t *testing.T) {
+	got := Trigrams("abcab")
+	want := []string{"abc", "bca", "cab"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q want %q", got, want)
+	}
+	if Trigrams("ab") != nil {
+		t.Fatalf("expected nil for short literal")
+	}
+}
@@ -2,13 +2,30 @@ package search
 
 import (
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"otterindex/internal/model"
 )
 
 type Match = model.Match
 
+// Match boundaries for FindInTextAt. BoundaryWord needs non-identifier
+// characters (or the line's ends) around a match; BoundaryIdent also accepts
+// identifier part boundaries: camelCase humps, '_' and letter/digit changes.
+const (
+	BoundaryWord  = "word"
+	BoundaryIdent = "ident"
+)
+
 func FindInText(text string, keyword string, caseInsensitive bool) []Match {
+	return FindInTextAt(text, keyword, caseInsensitive, "")
+}
+
+// FindInTextAt is FindInText restricted to occurrences starting and ending on
+// boundary; an empty boundary accepts any substring. It reports the first
+// such occurrence per line.
+func FindInTextAt(text string, keyword string, caseInsensitive bool, boundary string) []Match {
 	if keyword == "" {
 		return nil
 	}
@@ -27,7 +44,7 @@ func FindInText(text string, keyword string, caseInsensitive bool) []Match {
 			hay = strings.ToLower(hay)
 		}
 
-		idx := strings.Index(hay, needle)
+		idx := indexAt(hay, line, needle, boundary)
 		if idx < 0 {
 			continue
 		}
@@ -35,9 +52,108 @@ func FindInText(text string, keyword string, caseInsensitive bool) []Match {
 		out = append(out, Match{
 			Line: i + 1,
 			Col:  idx + 1,
+			Len:  len(needle),
 			Text: line,
 		})
 	}
 	return out
 }
 
+// IndexAt returns the byte offset of the first occurrence of needle in line
+// that starts and ends on boundary, or -1.
+func IndexAt(line string, needle string, caseInsensitive bool, boundary string) int {
+	hay := line
+	if caseInsensitive {
+		hay = strings.ToLower(hay)
+		needle = strings.ToLower(needle)
+	}
+	return indexAt(hay, line, needle, boundary)
+}
+
+// indexAt searches hay (line, possibly lower-cased) and checks boundaries
+// against the original line, whose case marks camelCase humps.
+func indexAt(hay string, line string, needle string, boundary string) int {
+	if needle == "" {
+		return -1
+	}
+	if boundary == "" || len(hay) != len(line) {
+		return strings.Index(hay, needle)
+	}
+	from := 0
+	for from <= len(hay)-len(needle) {
+		i := strings.Index(hay[from:], needle)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		if AtBoundary(line, i, i+len(needle), boundary) {
+			return i
+		}
+		_, size := utf8.DecodeRuneInString(hay[i:])
+		from = i + size
+	}
+	return -1
+}
+
+// AtBoundary reports whether line[start:end] starts and ends on boundary.
+func AtBoundary(line string, start int, end int, boundary string) bool {
+	if boundary == "" {
+		return true
+	}
+	if start < 0 || end > len(line) || start >= end {
+		return false
+	}
+	return startsOn(line, start, boundary) && endsOn(line, end, boundary)
+}
+
+func startsOn(line string, i int, boundary string) bool {
+	if i == 0 {
+		return true
+	}
+	prev, _ := utf8.DecodeLastRuneInString(line[:i])
+	cur, size := utf8.DecodeRuneInString(line[i:])
+	if !isWordRune(prev) || !isWordRune(cur) {
+		return true
+	}
+	if boundary != BoundaryIdent {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(line[i+size:])
+	return identSplit(prev, cur, next)
+}
+
+func endsOn(line string, i int, boundary string) bool {
+	if i == len(line) {
+		return true
+	}
+	last, _ := utf8.DecodeLastRuneInString(line[:i])
+	cur, size := utf8.DecodeRuneInString(line[i:])
+	if !isWordRune(last) || !isWordRune(cur) {
+		return true
+	}
+	if boundary != BoundaryIdent {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(line[i+size:])
+	return identSplit(last, cur, next)
+}
+
+// identSplit reports whether an identifier splits between a and b; c is the
+// rune after b ("HTTPServer" splits between P and S because e follows).
+func identSplit(a rune, b rune, c rune) bool {
+	switch {
+	case a == '_' || b == '_':
+		return true
+	case unicode.IsDigit(a) != unicode.IsDigit(b):
+		return true
+	case (unicode.IsLower(a) || unicode.IsDigit(a)) && unicode.IsUpper(b):
+		return true
+	case unicode.IsUpper(a) && unicode.IsUpper(b) && unicode.IsLower(c):
+		return true
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
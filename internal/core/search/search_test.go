@@ -29,3 +29,39 @@ func TestFindInText_ReportsColumnAndText(t *testing.T) {
 		t.Fatalf("matches=%v", ms)
 	}
 }
+
+func TestFindInTextAt_Boundaries(t *testing.T) {
+	cases := []struct {
+		line     string
+		keyword  string
+		boundary string
+		col      int // 0: no match
+	}{
+		{"reopen(); OpenFile(); Open()", "Open", BoundaryWord, 23},
+		{"reopen(); OpenFile(); Open()", "Open", BoundaryIdent, 11},
+		{"reopen(); OpenAPI", "Open", BoundaryIdent, 11},
+		{"reopen(); OpenAPI", "Open", BoundaryWord, 0},
+		{"file_open(x)", "open", BoundaryIdent, 6},
+		{"file_open(x)", "open", BoundaryWord, 0},
+		{"HTTPServer", "Server", BoundaryIdent, 5},
+		{"HTTPServer", "HTTP", BoundaryIdent, 1},
+		{"HTTPServer", "HTTPS", BoundaryIdent, 0},
+		{"Opened", "Open", BoundaryIdent, 0},
+		{"utf8Decode", "8", BoundaryIdent, 4},
+		{"call foo(", "foo(", BoundaryWord, 6},
+	}
+	for _, c := range cases {
+		ms := FindInTextAt(c.line, c.keyword, false, c.boundary)
+		col := 0
+		if len(ms) > 0 {
+			col = ms[0].Col
+		}
+		if col != c.col {
+			t.Fatalf("FindInTextAt(%q, %q, %s) col=%d want %d", c.line, c.keyword, c.boundary, col, c.col)
+		}
+	}
+
+	if ms := FindInTextAt("x := OPEN", "open", true, BoundaryWord); len(ms) != 1 || ms[0].Col != 6 {
+		t.Fatalf("case-insensitive word match: %v", ms)
+	}
+}
@@ -0,0 +1,214 @@
+//go:build treesitter && cgo
+
+package treesitter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unsafe"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_bash "github.com/tree-sitter/tree-sitter-bash/bindings/go"
+	tree_sitter_c_sharp "github.com/tree-sitter/tree-sitter-c-sharp/bindings/go"
+	tree_sitter_c "github.com/tree-sitter/tree-sitter-c/bindings/go"
+	tree_sitter_cpp "github.com/tree-sitter/tree-sitter-cpp/bindings/go"
+	tree_sitter_go "github.com/tree-sitter/tree-sitter-go/bindings/go"
+	tree_sitter_java "github.com/tree-sitter/tree-sitter-java/bindings/go"
+	tree_sitter_js "github.com/tree-sitter/tree-sitter-javascript/bindings/go"
+	tree_sitter_json "github.com/tree-sitter/tree-sitter-json/bindings/go"
+	tree_sitter_php "github.com/tree-sitter/tree-sitter-php/bindings/go"
+	tree_sitter_python "github.com/tree-sitter/tree-sitter-python/bindings/go"
+	tree_sitter_ts "github.com/tree-sitter/tree-sitter-typescript/bindings/go"
+)
+
+// grammars maps language names (as in internal/core/lang) to the grammar
+// their files are parsed with.
+var grammars = map[string]func() unsafe.Pointer{
+	"go":         tree_sitter_go.Language,
+	"java":       tree_sitter_java.Language,
+	"python":     tree_sitter_python.Language,
+	"javascript": tree_sitter_js.Language,
+	"typescript": tree_sitter_ts.LanguageTypescript,
+	"tsx":        tree_sitter_ts.LanguageTSX,
+	"php":        tree_sitter_php.LanguagePHP,
+	"csharp":     tree_sitter_c_sharp.Language,
+	"json":       tree_sitter_json.Language,
+	"bash":       tree_sitter_bash.Language,
+	"c":          tree_sitter_c.Language,
+	"cpp":        tree_sitter_cpp.Language,
+}
+
+// ASTLanguages lists the languages AST queries can run on.
+func ASTLanguages() []string {
+	out := make([]string, 0, len(grammars))
+	for name := range grammars {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ASTQuery is a compiled tree-sitter S-expression query for one language.
+type ASTQuery struct {
+	lang *tree_sitter.Language
+	q    *tree_sitter.Query
+}
+
+// CompileASTQuery compiles pattern for lang. Top-level patterns without a
+// capture of their own are captured as @match, so the whole pattern is
+// returned even when only inner nodes are captured for predicates.
+func CompileASTQuery(lang string, pattern string) (*ASTQuery, error) {
+	grammar, ok := grammars[strings.ToLower(strings.TrimSpace(lang))]
+	if !ok {
+		return nil, fmt.Errorf("%w: no grammar for %q (supported: %s)", ErrUnsupported, lang, strings.Join(ASTLanguages(), ", "))
+	}
+	l := tree_sitter.NewLanguage(grammar())
+
+	q, qerr := tree_sitter.NewQuery(l, captureRoots(pattern))
+	if qerr != nil {
+		// Report positions in the pattern as written.
+		if _, qerr = tree_sitter.NewQuery(l, pattern); qerr != nil {
+			return nil, fmt.Errorf("invalid ast query: %s", qerr.Error())
+		}
+		return nil, fmt.Errorf("invalid ast query")
+	}
+	return &ASTQuery{lang: l, q: q}, nil
+}
+
+// captureRoots appends " @match" to every top-level pattern of src that is
+// not followed by a capture.
+func captureRoots(src string) string {
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		b.WriteByte(c)
+		closed := false
+		switch c {
+		case ';':
+			j := strings.IndexByte(src[i:], '\n')
+			if j < 0 {
+				j = len(src) - i
+			}
+			b.WriteString(src[i+1 : i+j])
+			i += j - 1
+		case '"':
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(src) {
+				j = len(src) - 1
+			}
+			b.WriteString(src[i+1 : j+1])
+			i = j
+			closed = depth == 0
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+			closed = depth == 0
+		}
+		if !closed {
+			continue
+		}
+		j := i + 1
+		for j < len(src) && strings.IndexByte(" \t\r\n*+?", src[j]) >= 0 {
+			j++
+		}
+		if j < len(src) && src[j] == '@' {
+			continue
+		}
+		// Keep quantifiers attached to the pattern.
+		k := i + 1
+		for k < len(src) && strings.IndexByte("*+?", src[k]) >= 0 {
+			k++
+		}
+		b.WriteString(src[i+1 : k])
+		b.WriteString(" @match")
+		i = k - 1
+	}
+	return b.String()
+}
+
+func (a *ASTQuery) Close() {
+	if a != nil && a.q != nil {
+		a.q.Close()
+	}
+}
+
+// Match runs the query on src. Each query match yields its @match node, or
+// else its widest captured node; a node matched more than once is returned once, in source order.
+func (a *ASTQuery) Match(src []byte) ([]ASTMatch, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(a.lang); err != nil {
+		return nil, err
+	}
+
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	root := tree.RootNode()
+	if root == nil {
+		return nil, nil
+	}
+
+	qc := tree_sitter.NewQueryCursor()
+	defer qc.Close()
+
+	names := a.q.CaptureNames()
+	seen := map[[2]uint]bool{}
+	var out []ASTMatch
+	matches := qc.Matches(a.q, root, src)
+	for m := matches.Next(); m != nil; m = matches.Next() {
+		best := -1
+		for i, c := range m.Captures {
+			if names[c.Index] == "match" {
+				best = i
+				break
+			}
+			if best < 0 || wider(&c.Node, &m.Captures[best].Node) {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		c := m.Captures[best]
+		key := [2]uint{c.Node.StartByte(), c.Node.EndByte()}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		sl, sc, el, ec := nodeRange1Based(&c.Node)
+		out = append(out, ASTMatch{
+			Kind:    c.Node.Kind(),
+			Capture: names[c.Index],
+			SL:      sl,
+			SC:      sc,
+			EL:      el,
+			EC:      ec,
+			Text:    c.Node.Utf8Text(src),
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].SL != out[j].SL {
+			return out[i].SL < out[j].SL
+		}
+		return out[i].SC < out[j].SC
+	})
+	return out, nil
+}
+
+func wider(a, b *tree_sitter.Node) bool {
+	if a.StartByte() != b.StartByte() {
+		return a.StartByte() < b.StartByte()
+	}
+	return a.EndByte() > b.EndByte()
+}
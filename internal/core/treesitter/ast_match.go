@@ -0,0 +1,15 @@
+package treesitter
+
+// ASTMatch is one node returned by an AST query. Lines and columns are
+// 1-based; columns count bytes.
+type ASTMatch struct {
+	// Kind is the node type, e.g. "if_statement".
+	Kind string
+	// Capture is the name of the capture that selected the node.
+	Capture string
+	SL      int
+	SC      int
+	EL      int
+	EC      int
+	Text    string
+}
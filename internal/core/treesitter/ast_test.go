@@ -0,0 +1,75 @@
+//go:build treesitter && cgo
+
+package treesitter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCaptureRoots(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{`(nil)`, `(nil) @match`},
+		{`(nil) @n`, `(nil) @n`},
+		{`(identifier)* (nil)`, `(identifier)* @match (nil) @match`},
+		{`((identifier) @i (#eq? @i ")"))`, `((identifier) @i (#eq? @i ")")) @match`},
+		{"; (x)\n[(nil) (true)]", "; (x)\n[(nil) (true)] @match"},
+	}
+	for _, tc := range cases {
+		if got := captureRoots(tc.in); got != tc.want {
+			t.Fatalf("captureRoots(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestASTQueryMatch(t *testing.T) {
+	src := []byte(`package p
+
+func (s *Store) Open() error {
+	if err := s.init(); err != nil {
+		return nil
+	}
+	return nil
+}
+
+func Open() {}
+`)
+	aq, err := CompileASTQuery("go", `(if_statement condition: (binary_expression left: (identifier) @e (#eq? @e "err") right: (nil)))`)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	defer aq.Close()
+	ms, err := aq.Match(src)
+	if err != nil {
+		t.Fatalf("match: %v", err)
+	}
+	if len(ms) != 1 {
+		t.Fatalf("expected 1 match, got %+v", ms)
+	}
+	if m := ms[0]; m.Kind != "if_statement" || m.Capture != "match" || m.SL != 4 || m.SC != 2 || m.EL != 6 || m.EC != 3 {
+		t.Fatalf("unexpected match: %+v", m)
+	}
+
+	recv, err := CompileASTQuery("go", `(method_declaration receiver: (parameter_list (parameter_declaration type: (pointer_type (type_identifier) @t))) (#eq? @t "Store")) @m`)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	defer recv.Close()
+	ms, err = recv.Match(src)
+	if err != nil {
+		t.Fatalf("match: %v", err)
+	}
+	if len(ms) != 1 || ms[0].Capture != "m" || ms[0].SL != 3 || ms[0].EL != 8 {
+		t.Fatalf("unexpected matches: %+v", ms)
+	}
+
+	if _, err := CompileASTQuery("go", `(no_such_node)`); err == nil {
+		t.Fatalf("expected invalid query error")
+	}
+	if _, err := CompileASTQuery("ruby", `(x)`); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
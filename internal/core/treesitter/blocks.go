@@ -0,0 +1,118 @@
+package treesitter
+
+import (
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+// blockWord is a keyword that opens or closes an "end"-terminated block,
+// ending at byte offset end of its line.
+type blockWord struct {
+	end  int
+	open bool
+}
+
+// blockLang describes a language whose blocks close with a keyword (Ruby,
+// Lua) to extractBlocks. words lists a code line's block keywords in order;
+// decl matches a declaration on a line given the enclosing symbols.
+type blockLang struct {
+	words func(code string) []blockWord
+	decl  func(code string, outer []store.SymbolInput) (store.SymbolInput, bool)
+}
+
+// extractBlocks pairs block keywords outside comments and strings. A
+// declaration owns the first block its line opens and ends with it; one
+// that opens none ("def x = 1") is a single line.
+func extractBlocks(l blockLang, lx lexed) []store.SymbolInput {
+	type open struct {
+		sym int // -1 for blocks that are not declarations
+	}
+	var syms []store.SymbolInput
+	var closed []bool
+	var stack []open
+
+	for li, line := range lx.code {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var outer []store.SymbolInput
+		for _, o := range stack {
+			if o.sym >= 0 {
+				outer = append(outer, syms[o.sym])
+			}
+		}
+		owner := -1
+		if sym, ok := l.decl(line, outer); ok {
+			sym.SL = li + 1
+			sym.SC = len(line) - len(strings.TrimLeft(line, " \t")) + 1
+			sym.EL, sym.EC = li+1, len(strings.TrimRight(line, " \t\r"))+1
+			syms = append(syms, sym)
+			closed = append(closed, true)
+			owner = len(syms) - 1
+		}
+
+		for _, w := range l.words(line) {
+			if w.open {
+				stack = append(stack, open{sym: owner})
+				if owner >= 0 {
+					closed[owner] = false
+				}
+				owner = -1
+				continue
+			}
+			if n := len(stack); n > 0 {
+				if s := stack[n-1].sym; s >= 0 {
+					syms[s].EL, syms[s].EC = li+1, w.end+1
+					closed[s] = true
+				}
+				stack = stack[:n-1]
+			}
+		}
+	}
+
+	out := syms[:0]
+	for i, s := range syms {
+		if closed[i] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// identAt is an identifier at byte offset pos of a line.
+type identAt struct {
+	pos  int
+	word string
+}
+
+// identifiers lists the identifiers of a code line, skipping ones reached
+// through '.' or ':' (obj.end, :end, A::B) and hash keys (end: 1).
+func identifiers(code string) []identAt {
+	var out []identAt
+	for i := 0; i < len(code); {
+		c := code[i]
+		if c != '_' && !isAlnum(c) {
+			i++
+			continue
+		}
+		end := i
+		for end < len(code) && (code[end] == '_' || isAlnum(code[end])) {
+			end++
+		}
+		for end < len(code) && (code[end] == '?' || code[end] == '!') && (end+1 == len(code) || code[end+1] != '=') {
+			end++
+		}
+		member := i > 0 && (code[i-1] == '.' || code[i-1] == ':' || code[i-1] == '$' || code[i-1] == '@')
+		key := end < len(code) && code[end] == ':' && (end+1 == len(code) || code[end+1] != ':')
+		if !member && !key && (c < '0' || c > '9') {
+			out = append(out, identAt{pos: i, word: code[i:end]})
+		}
+		i = end
+	}
+	return out
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,218 @@
+package treesitter
+
+import (
+	"regexp"
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+// braceRule matches the first line of a declaration whose body is a {...}
+// block, with the name in the "name" group. An empty kind takes the first
+// word of the "kind" group; ctor rules only match the enclosing type's name.
+type braceRule struct {
+	re   *regexp.Regexp
+	kind string
+	ctor bool
+}
+
+// braceLang describes a language with {...} bodies to extractBraces:
+// top rules apply at the top level and inside namespaces, member rules in
+// type bodies. Function bodies are not searched.
+type braceLang struct {
+	lang   string
+	syn    lexSyntax
+	top    []braceRule
+	member []braceRule
+	// funcSig prefixes the name in the signature of a top-level function;
+	// sep joins a member to its type ("Outer.run", "Outer::run").
+	funcSig string
+	sep     string
+}
+
+// braceTypeKinds have members; braceScopeKinds hold top-level declarations.
+var braceTypeKinds = map[string]bool{
+	"class": true, "interface": true, "struct": true, "enum": true,
+	"record": true, "trait": true, "union": true, "impl": true,
+	"object": true, "protocol": true, "extension": true, "actor": true,
+}
+
+var braceScopeKinds = map[string]bool{"namespace": true, "module": true}
+
+// braceKeywords are never symbol names.
+var braceKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"return": true, "else": true, "do": true, "try": true, "new": true,
+	"delete": true, "throw": true, "case": true, "sizeof": true, "with": true,
+	"typeof": true, "function": true, "super": true, "this": true, "goto": true,
+	"using": true, "lock": true, "foreach": true, "fixed": true, "synchronized": true,
+	"func": true,
+}
+
+// braceStatements start statements, not declarations: "return foo(x) {".
+var braceStatements = map[string]bool{
+	"return": true, "else": true, "new": true, "delete": true, "throw": true,
+	"case": true, "goto": true, "if": true, "while": true, "for": true,
+	"switch": true, "do": true, "}": true,
+}
+
+func cLikeSyntax() lexSyntax {
+	return lexSyntax{line: []string{"//"}, block: [2]string{"/*", "*/"}, quotes: `"'`}
+}
+
+// braceOpen is a declaration whose body is open.
+type braceOpen struct {
+	sym   int
+	depth int
+	kind  string
+	name  string
+}
+
+// pendingDecl is a declaration header waiting for its '{'.
+type pendingDecl struct {
+	sym   store.SymbolInput
+	depth int
+	paren int
+	line  int
+	// params is set once the header's parameter list has closed; an '='
+	// after it starts an expression body ("fun f() = 1").
+	params bool
+}
+
+// extractBraces finds declarations by matching the rules against the first
+// line of each statement and their bodies by counting braces outside
+// comments and strings. Declarations without a body ("void f();") are
+// skipped, as are bodies that never close.
+func extractBraces(l braceLang, src []byte) ([]store.SymbolInput, []store.CommentInput) {
+	lx := lex(src, l.syn, l.lang)
+
+	var syms []store.SymbolInput
+	var closed []bool
+	var stack []braceOpen
+	var pending *pendingDecl
+	depth, paren := 0, 0
+
+	for li, line := range lx.code {
+		if pending != nil && li-pending.line > 4 {
+			pending = nil
+		}
+
+		// Declarations are looked for directly in the body of the innermost
+		// open type or namespace, or at the top level.
+		var rules []braceRule
+		container := ""
+		switch {
+		case len(stack) == 0:
+			if depth == 0 {
+				rules = l.top
+			}
+		case depth == stack[len(stack)-1].depth+1:
+			top := stack[len(stack)-1]
+			switch {
+			case braceScopeKinds[top.kind]:
+				rules = l.top
+			case braceTypeKinds[top.kind]:
+				rules = l.member
+			}
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			if braceTypeKinds[stack[i].kind] {
+				container = stack[i].name
+				break
+			}
+		}
+		// A new header replaces one that never got a body ("data class
+		// P(val x: Int)" has none and no ';' either).
+		if paren == 0 && strings.TrimSpace(line) != "" {
+			if sym, ok := matchBraceRule(l, rules, line, container); ok {
+				sym.SL = li + 1
+				sym.SC = len(line) - len(strings.TrimLeft(line, " \t")) + 1
+				pending = &pendingDecl{sym: sym, depth: depth, paren: paren, line: li}
+			}
+		}
+
+		for ci := 0; ci < len(line); ci++ {
+			switch line[ci] {
+			case '(', '[':
+				paren++
+			case ')', ']':
+				paren = max(paren-1, 0)
+				if pending != nil && depth == pending.depth && paren == pending.paren {
+					pending.params = true
+				}
+			case '=':
+				if pending != nil && pending.params && depth == pending.depth && paren == pending.paren &&
+					!strings.HasPrefix(line[ci:], "=>") && !strings.HasPrefix(line[ci:], "==") &&
+					(ci == 0 || strings.IndexByte("=!<>", line[ci-1]) < 0) {
+					pending = nil
+				}
+			case ';':
+				if pending != nil && depth == pending.depth && paren == pending.paren {
+					pending = nil
+				}
+			case '{':
+				if pending != nil && depth == pending.depth && paren == pending.paren {
+					syms = append(syms, pending.sym)
+					closed = append(closed, false)
+					stack = append(stack, braceOpen{sym: len(syms) - 1, depth: depth, kind: pending.sym.Kind, name: pending.sym.Name})
+					pending = nil
+				}
+				depth++
+			case '}':
+				depth = max(depth-1, 0)
+				if n := len(stack); n > 0 && stack[n-1].depth == depth {
+					s := &syms[stack[n-1].sym]
+					s.EL, s.EC = li+1, ci+2
+					closed[stack[n-1].sym] = true
+					stack = stack[:n-1]
+				}
+			}
+		}
+	}
+
+	out := syms[:0]
+	for i, s := range syms {
+		if closed[i] {
+			out = append(out, s)
+		}
+	}
+	return out, lx.comms
+}
+
+func matchBraceRule(l braceLang, rules []braceRule, code string, container string) (store.SymbolInput, bool) {
+	fields := strings.Fields(code)
+	if len(fields) == 0 || braceStatements[fields[0]] || strings.HasPrefix(fields[0], "#") {
+		return store.SymbolInput{}, false
+	}
+	for _, r := range rules {
+		m := r.re.FindStringSubmatch(code)
+		if m == nil {
+			continue
+		}
+		name := m[r.re.SubexpIndex("name")]
+		if name == "" || braceKeywords[name] || (r.ctor && name != container) {
+			continue
+		}
+		kind := r.kind
+		if kind == "" {
+			kind = strings.Fields(m[r.re.SubexpIndex("kind")])[0]
+		}
+
+		sym := store.SymbolInput{Kind: kind, Name: name, Lang: l.lang}
+		switch {
+		case braceScopeKinds[kind] || braceTypeKinds[kind]:
+			sym.Signature = kind + " " + name
+		case container != "":
+			sym.Signature = container + l.sep + name
+		case kind == "function":
+			sym.Signature = l.funcSig + name
+		default:
+			sym.Signature = kind + " " + name
+		}
+		if !braceScopeKinds[kind] {
+			sym.Container = container
+		}
+		return sym, true
+	}
+	return store.SymbolInput{}, false
+}
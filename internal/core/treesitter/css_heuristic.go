@@ -0,0 +1,61 @@
+package treesitter
+
+import (
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+// extractCSS reports style rules as "rule" symbols named by their selector
+// and blocks like @media as "at-rule" symbols; nested rules (@media bodies,
+// CSS nesting) are contained by the enclosing one.
+func extractCSS(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	_ = path
+	lx := lex(src, lexSyntax{block: [2]string{"/*", "*/"}, quotes: `"'`}, "css")
+	code := strings.Join(lx.code, "\n")
+
+	pos := positions(code)
+
+	var syms []store.SymbolInput
+	var stack []int // open blocks: symbol index, or -1
+	prelude := 0
+	for i := 0; i < len(code); i++ {
+		switch code[i] {
+		case ';':
+			prelude = i + 1
+		case '{':
+			raw := code[prelude:i]
+			name := strings.Join(strings.Fields(raw), " ")
+			if name == "" {
+				stack = append(stack, -1)
+				prelude = i + 1
+				continue
+			}
+			from := prelude + len(raw) - len(strings.TrimLeft(raw, " \t\r\n"))
+			kind := "rule"
+			if strings.HasPrefix(name, "@") {
+				kind = "at-rule"
+			}
+			container := ""
+			for j := len(stack) - 1; j >= 0; j-- {
+				if stack[j] >= 0 {
+					container = syms[stack[j]].Name
+					break
+				}
+			}
+			sl, sc := pos(from)
+			syms = append(syms, store.SymbolInput{Kind: kind, Name: name, SL: sl, SC: sc, EL: sl, EC: sc, Container: container, Lang: "css", Signature: name})
+			stack = append(stack, len(syms)-1)
+			prelude = i + 1
+		case '}':
+			if n := len(stack); n > 0 {
+				if s := stack[n-1]; s >= 0 {
+					syms[s].EL, syms[s].EC = pos(i + 1)
+				}
+				stack = stack[:n-1]
+			}
+			prelude = i + 1
+		}
+	}
+	return syms, lx.comms, nil
+}
@@ -0,0 +1,109 @@
+//go:build !treesitter || !cgo
+
+package treesitter
+
+import "regexp"
+
+// Languages the tree-sitter build parses with grammars; without it they go
+// through extractBraces.
+var (
+	jsLike = braceLang{
+		syn: lexSyntax{line: []string{"//"}, block: [2]string{"/*", "*/"}, quotes: "\"'`", multiline: "`"},
+		top: []braceRule{
+			{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?P<kind>class|interface|enum)\s+(?P<name>[A-Za-z_$][\w$]*)`)},
+			{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?(?:namespace|module)\s+(?P<name>[A-Za-z_$][\w$.]*)`), kind: "namespace"},
+			{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\b\s*\*?\s*(?P<name>[A-Za-z_$][\w$]*)`), kind: "function"},
+			{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+(?P<name>[A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*(?::[^=]+)?=>)`), kind: "function"},
+		},
+		member: []braceRule{
+			{re: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|async|readonly|override|abstract|declare|get|set)\s+)*\*?(?P<name>#?[A-Za-z_$][\w$]*)\s*(?:<[^>]*>)?\s*\(`), kind: "method"},
+			{re: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|readonly)\s+)*(?P<name>#?[A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*(?::[^=]+)?=>`), kind: "method"},
+		},
+		funcSig: "function ",
+		sep:     ".",
+	}
+
+	javaLang = braceLang{
+		lang: "java",
+		syn:  cLikeSyntax(),
+		top: []braceRule{
+			{re: regexp.MustCompile(`^\s*(?:@\w+(?:\([^)]*\))?\s+)*(?:(?:public|protected|private|static|final|abstract|sealed|non-sealed|strictfp)\s+)*(?P<kind>class|interface|enum|record)\s+(?P<name>\w+)`)},
+		},
+		member: []braceRule{
+			{re: regexp.MustCompile(`^\s*(?:@\w+(?:\([^)]*\))?\s+)*(?:(?:public|protected|private|static|final|abstract|sealed|non-sealed|strictfp)\s+)*(?P<kind>class|interface|enum|record)\s+(?P<name>\w+)`)},
+			{re: regexp.MustCompile(`^\s*(?:@\w+(?:\([^)]*\))?\s+)*(?:(?:public|protected|private)\s+)?(?P<name>\w+)\s*\(`), kind: "constructor", ctor: true},
+			{re: regexp.MustCompile(`^\s*(?:@\w+(?:\([^)]*\))?\s+)*(?:(?:public|protected|private|static|final|abstract|synchronized|native|default|strictfp)\s+)*(?:<[^>]+>\s+)?[\w.$]+(?:<[^()]*>)?(?:\[\])*\s+(?P<name>\w+)\s*\(`), kind: "method"},
+		},
+		sep: ".",
+	}
+
+	csharpLang = braceLang{
+		lang: "csharp",
+		syn:  cLikeSyntax(),
+		top: []braceRule{
+			{re: regexp.MustCompile(`^\s*namespace\s+(?P<name>[\w.]+)`), kind: "namespace"},
+			{re: regexp.MustCompile(`^\s*(?:\[[^\]]*\]\s*)*(?:(?:public|protected|private|internal|static|sealed|abstract|partial|readonly|unsafe|file|new)\s+)*(?:ref\s+)?(?P<kind>class|interface|struct|enum|record)\s+(?:struct\s+|class\s+)?(?P<name>\w+)`)},
+		},
+		member: []braceRule{
+			{re: regexp.MustCompile(`^\s*(?:\[[^\]]*\]\s*)*(?:(?:public|protected|private|internal|static|sealed|abstract|partial|readonly|unsafe|file|new)\s+)*(?:ref\s+)?(?P<kind>class|interface|struct|enum|record)\s+(?:struct\s+|class\s+)?(?P<name>\w+)`)},
+			{re: regexp.MustCompile(`^\s*(?:\[[^\]]*\]\s*)*(?:(?:public|protected|private|internal|static)\s+)*(?P<name>\w+)\s*\(`), kind: "constructor", ctor: true},
+			{re: regexp.MustCompile(`^\s*(?:\[[^\]]*\]\s*)*(?:(?:public|protected|private|internal|static|sealed|abstract|virtual|override|async|extern|unsafe|readonly|new|partial)\s+)*[\w.<>\[\],?]+\s+(?P<name>\w+)\s*(?:<[^>]*>)?\s*\(`), kind: "method"},
+		},
+		sep: ".",
+	}
+
+	phpLang = braceLang{
+		lang: "php",
+		syn:  lexSyntax{line: []string{"//", "#"}, block: [2]string{"/*", "*/"}, quotes: `"'`, multiline: `"'`},
+		top: []braceRule{
+			{re: regexp.MustCompile(`^\s*namespace\s+(?P<name>[\w\\]+)`), kind: "namespace"},
+			{re: regexp.MustCompile(`^\s*(?:(?:abstract|final|readonly)\s+)*(?P<kind>class|interface|trait|enum)\s+(?P<name>\w+)`)},
+			{re: regexp.MustCompile(`^\s*function\s+&?(?P<name>\w+)\s*\(`), kind: "function"},
+		},
+		member: []braceRule{
+			{re: regexp.MustCompile(`^\s*(?:(?:public|protected|private|static|abstract|final|readonly)\s+)*function\s+&?(?P<name>\w+)\s*\(`), kind: "method"},
+		},
+		funcSig: "function ",
+		sep:     ".",
+	}
+
+	cLang = braceLang{
+		lang: "c",
+		syn:  cLikeSyntax(),
+		top: []braceRule{
+			{re: regexp.MustCompile(`^\s*(?:typedef\s+)?(?P<kind>struct|enum|union)\s+(?P<name>\w+)\s*(?:\{.*)?$`)},
+			// Function definitions start in column 0, with the return type on
+			// the same line or the one above.
+			{re: regexp.MustCompile(`^(?:[A-Za-z_][\w\s\*]*?[\s\*]+)?(?P<name>[A-Za-z_]\w*)\s*\(`), kind: "function"},
+		},
+	}
+
+	cppLang = braceLang{
+		lang: "cpp",
+		syn:  cLikeSyntax(),
+		top: []braceRule{
+			{re: regexp.MustCompile(`^\s*(?:inline\s+)?namespace\s+(?P<name>[\w:]+)`), kind: "namespace"},
+			{re: regexp.MustCompile(`^\s*(?:template\s*<[^>]*>\s*)?(?:typedef\s+)?(?P<kind>class|struct|enum|union)\s+(?:class\s+|struct\s+)?(?:[A-Z_][A-Z0-9_]*\s+)?(?P<name>\w+)\s*(?:final\s*)?(?::[^;{]*)?(?:\{.*)?$`)},
+			{re: regexp.MustCompile(`^\s*(?:template\s*<[^>]*>\s*)?(?:[\w:<>,\*&]+\s+)*?[\*&]*(?P<name>(?:\w+::)*~?\w+)\s*\(`), kind: "function"},
+		},
+		member: []braceRule{
+			{re: regexp.MustCompile(`^\s*(?:template\s*<[^>]*>\s*)?(?P<kind>class|struct|enum|union)\s+(?:class\s+|struct\s+)?(?P<name>\w+)\s*(?:final\s*)?(?::[^;{]*)?(?:\{.*)?$`)},
+			{re: regexp.MustCompile(`^\s*(?:template\s*<[^>]*>\s*)?(?:[\w:<>,\*&]+\s+)*?[\*&]*(?P<name>~?\w+)\s*\(`), kind: "function"},
+		},
+		sep: "::",
+	}
+
+	bashLang = braceLang{
+		lang: "bash",
+		syn:  lexSyntax{line: []string{"#"}, quotes: `"'`, multiline: `"'`, hashWord: true},
+		top: []braceRule{
+			{re: regexp.MustCompile(`^\s*function\s+(?P<name>[\w.:-]+)`), kind: "function"},
+			{re: regexp.MustCompile(`^\s*(?P<name>[A-Za-z_][\w.:-]*)\s*\(\s*\)`), kind: "function"},
+		},
+	}
+)
+
+func withLang(l braceLang, name string) braceLang {
+	l.lang = name
+	return l
+}
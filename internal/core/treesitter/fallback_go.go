@@ -0,0 +1,94 @@
+//go:build !treesitter || !cgo
+
+package treesitter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+// extractGoFallback uses go/parser, so it sees the same declarations as the
+// tree-sitter extractor; files that do not parse keep what was parsed before
+// the first error.
+func extractGoFallback(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if f == nil {
+		return nil, nil, err
+	}
+
+	rng := func(n ast.Node) (int, int, int, int) {
+		s, e := fset.Position(n.Pos()), fset.Position(n.End())
+		return s.Line, s.Column, e.Line, e.Column
+	}
+
+	var syms []store.SymbolInput
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			sl, sc, el, ec := rng(d)
+			sym := store.SymbolInput{Kind: "function", Name: d.Name.Name, SL: sl, SC: sc, EL: el, EC: ec, Lang: "go", Signature: "func " + d.Name.Name}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				sym.Kind = "method"
+				sym.Container = goRecvName(d.Recv.List[0].Type)
+				if sym.Container != "" {
+					sym.Signature = "func (" + sym.Container + ") " + d.Name.Name
+				}
+			}
+			syms = append(syms, sym)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				kind := "type"
+				switch ts.Type.(type) {
+				case *ast.StructType:
+					kind = "struct"
+				case *ast.InterfaceType:
+					kind = "interface"
+				}
+				sl, sc, el, ec := rng(ts)
+				syms = append(syms, store.SymbolInput{Kind: kind, Name: ts.Name.Name, SL: sl, SC: sc, EL: el, EC: ec, Lang: "go", Signature: kind + " " + ts.Name.Name})
+			}
+		}
+	}
+
+	var comms []store.CommentInput
+	for _, g := range f.Comments {
+		for _, c := range g.List {
+			kind := "line"
+			if strings.HasPrefix(c.Text, "/*") {
+				kind = "block"
+			}
+			sl, sc, el, ec := rng(c)
+			comms = append(comms, store.CommentInput{Kind: kind, Text: c.Text, SL: sl, SC: sc, EL: el, EC: ec, Lang: "go"})
+		}
+	}
+	return syms, comms, nil
+}
+
+// goRecvName is the base type name of a receiver: "*Server[T]" -> "Server".
+func goRecvName(t ast.Expr) string {
+	for {
+		switch x := t.(type) {
+		case *ast.StarExpr:
+			t = x.X
+		case *ast.ParenExpr:
+			t = x.X
+		case *ast.IndexExpr:
+			t = x.X
+		case *ast.IndexListExpr:
+			t = x.X
+		case *ast.Ident:
+			return x.Name
+		default:
+			return ""
+		}
+	}
+}
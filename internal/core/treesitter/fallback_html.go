@@ -0,0 +1,99 @@
+//go:build !treesitter || !cgo
+
+package treesitter
+
+import (
+	"regexp"
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+var (
+	htmlTagRe = regexp.MustCompile(`<(/?)([A-Za-z][\w:-]*)((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	htmlIDRe  = regexp.MustCompile(`(?i)(?:^|\s)id\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// htmlVoid elements have no end tag.
+var htmlVoid = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// extractHTMLFallback pairs start and end tags outside comments; an element
+// left open ends where its parent does. Script and style bodies are skipped.
+func extractHTMLFallback(src []byte) ([]store.SymbolInput, []store.CommentInput) {
+	lx := lex(src, lexSyntax{block: [2]string{"<!--", "-->"}}, "html")
+	for i := range lx.comms {
+		lx.comms[i].Kind = "comment"
+	}
+	code := strings.Join(lx.code, "\n")
+	pos := positions(code)
+
+	type open struct {
+		tag string
+		sym int
+	}
+	var syms []store.SymbolInput
+	var stack []open
+	closeAt := func(n int, off int) {
+		for len(stack) > n {
+			if s := stack[len(stack)-1].sym; s >= 0 {
+				syms[s].EL, syms[s].EC = pos(off)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	for off := 0; off < len(code); {
+		m := htmlTagRe.FindStringSubmatchIndex(code[off:])
+		if m == nil {
+			break
+		}
+		base := off
+		start, end := base+m[0], base+m[1]
+		off = end
+		tag := strings.ToLower(code[base+m[4] : base+m[5]])
+
+		if m[3] > m[2] {
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].tag == tag {
+					closeAt(i+1, start)
+					closeAt(i, end)
+					break
+				}
+			}
+			continue
+		}
+
+		attrs := code[base+m[6] : base+m[7]]
+		sym := -1
+		if id := htmlIDRe.FindStringSubmatch(attrs); id != nil {
+			name := strings.TrimSpace(id[1] + id[2] + id[3])
+			if name != "" {
+				container := ""
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i].sym >= 0 {
+						container = syms[stack[i].sym].Name
+						break
+					}
+				}
+				sl, sc := pos(start)
+				el, ec := pos(end)
+				syms = append(syms, store.SymbolInput{Kind: "element", Name: name, SL: sl, SC: sc, EL: el, EC: ec, Container: container, Lang: "html", Signature: tag + "#" + name})
+				sym = len(syms) - 1
+			}
+		}
+		if htmlVoid[tag] || strings.HasSuffix(attrs, "/") {
+			continue
+		}
+		if tag == "script" || tag == "style" {
+			if i := strings.Index(strings.ToLower(code[off:]), "</"+tag); i >= 0 {
+				off += i
+			}
+		}
+		stack = append(stack, open{tag: tag, sym: sym})
+	}
+	closeAt(0, len(code))
+	return syms, lx.comms
+}
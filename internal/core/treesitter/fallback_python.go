@@ -0,0 +1,133 @@
+//go:build !treesitter || !cgo
+
+package treesitter
+
+import (
+	"regexp"
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+var pythonDefRe = regexp.MustCompile(`^([ \t]*)(?:async[ \t]+)?(def|class)[ \t]+([A-Za-z_]\w*)`)
+
+// extractPythonFallback finds def and class statements; a body ends before
+// the next code line indented no deeper than the statement. Docstrings are
+// the triple-quoted strings that open a module, class or function body.
+func extractPythonFallback(src []byte) ([]store.SymbolInput, []store.CommentInput) {
+	lx := lex(src, lexSyntax{line: []string{"#"}, quotes: `"'`, triple: true}, "python")
+
+	// inString marks the lines a multi-line string continues onto; their
+	// indentation means nothing.
+	inString := make([]bool, len(lx.code))
+	for _, t := range lx.triples {
+		for l := t.sl + 1; l <= t.el && l <= len(inString); l++ {
+			inString[l-1] = true
+		}
+	}
+	indent := func(l string) int {
+		return len(l) - len(strings.TrimLeft(l, " \t"))
+	}
+	isCode := func(i int) bool {
+		return !inString[i] && strings.TrimSpace(lx.code[i]) != ""
+	}
+
+	type open struct {
+		sym    int
+		indent int
+	}
+	var syms []store.SymbolInput
+	var bodyStart []int // first line after each symbol's header, 1-based
+	var stack []open
+	closeTo := func(ind int, last int) {
+		for len(stack) > 0 && stack[len(stack)-1].indent >= ind {
+			s := &syms[stack[len(stack)-1].sym]
+			s.EL, s.EC = last+1, len(lx.lines[last])+1
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	lastCode, brackets := 0, 0
+	for i, line := range lx.code {
+		if !isCode(i) {
+			continue
+		}
+		// Lines inside brackets continue the statement above.
+		cont := brackets > 0
+		brackets = max(brackets+strings.Count(line, "(")+strings.Count(line, "[")+strings.Count(line, "{")-
+			strings.Count(line, ")")-strings.Count(line, "]")-strings.Count(line, "}"), 0)
+		if cont {
+			lastCode = i
+			continue
+		}
+		ind := indent(line)
+		closeTo(ind, lastCode)
+		lastCode = i
+
+		m := pythonDefRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		container := ""
+		for j := len(stack) - 1; j >= 0; j-- {
+			if syms[stack[j].sym].Kind == "class" {
+				container = syms[stack[j].sym].Name
+				break
+			}
+		}
+		sym := store.SymbolInput{
+			Kind:      "function",
+			Name:      m[3],
+			SL:        i + 1,
+			SC:        ind + 1,
+			Container: container,
+			Lang:      "python",
+			Signature: "def " + m[3],
+		}
+		if m[2] == "class" {
+			sym.Kind, sym.Signature = "class", "class "+m[3]
+		} else if container != "" {
+			sym.Signature = container + "." + m[3]
+		}
+		syms = append(syms, sym)
+		stack = append(stack, open{sym: len(syms) - 1, indent: ind})
+
+		// The header ends at the line whose code ends with ':' outside brackets.
+		end := i
+		for end < len(lx.code)-1 && !strings.HasSuffix(strings.TrimSpace(lx.code[end]), ":") {
+			end++
+		}
+		bodyStart = append(bodyStart, end+2)
+	}
+	closeTo(0, lastCode)
+
+	comms := lx.comms
+	for _, t := range lx.triples {
+		if strings.TrimSpace(strings.TrimRight(lx.code[t.sl-1][:t.sc-1], "rRuUbBfF")) != "" {
+			continue
+		}
+		doc := false
+		prev := t.sl - 2
+		for prev >= 0 && !isCode(prev) {
+			prev--
+		}
+		if prev < 0 {
+			doc = true
+		}
+		for k, s := range syms {
+			if bodyStart[k] == prev+2 && s.SL <= prev+1 {
+				doc = true
+			}
+		}
+		if !doc {
+			continue
+		}
+		text := strings.Join(lx.lines[t.sl-1:t.el], "\n")
+		text = text[t.sc-1:]
+		if cut := len(lx.lines[t.el-1]) - (t.ec - 1); cut > 0 && cut <= len(text) {
+			text = text[:len(text)-cut]
+		}
+		comms = append(comms, store.CommentInput{Kind: "docstring", Text: text, SL: t.sl, SC: t.sc, EL: t.el, EC: t.ec, Lang: "python"})
+	}
+	return syms, comms
+}
@@ -0,0 +1,120 @@
+//go:build !treesitter || !cgo
+
+package treesitter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFallbackExtract(t *testing.T) {
+	cases := []struct {
+		path string
+		src  string
+		want []string
+	}{
+		{
+			path: "a.go",
+			src:  "package a\n\n// Server serves.\ntype Server struct {\n\taddr string\n}\n\nfunc (s *Server) Start() {\n}\n\nfunc New() *Server { return nil }\n",
+			want: []string{
+				"4-6 struct Server (struct Server)",
+				"8-9 method Server.Start (func (Server) Start)",
+				"11-11 function New (func New)",
+			},
+		},
+		{
+			path: "a.py",
+			src:  "import os\n\nclass A:\n    \"\"\"Doc.\n\n    More.\n\"\"\"\n\n    def run(self):\n        s = '''\nnot: def fake():\n'''\n        return s\n\n\nasync def main(\n    x,\n):\n    pass\n",
+			want: []string{
+				"3-13 class A (class A)",
+				"9-13 function A.run (A.run)",
+				"16-19 function main (def main)",
+			},
+		},
+		{
+			path: "a.ts",
+			src:  "// { not a brace\nexport class Repo<T> {\n  private items: T[] = [];\n  constructor() {}\n  async find(id: string): Promise<T> {\n    if (id) { return this.items[0]; }\n    const s = \"}\";\n  }\n  abstract save(x: T): void;\n}\n\nexport const handler = async (req) => {\n  return `${req}}`;\n};\n\nfunction helper(a = {}) {\n}\n",
+			want: []string{
+				"2-10 class Repo (class Repo)",
+				"4-4 method Repo.constructor (Repo.constructor)",
+				"5-8 method Repo.find (Repo.find)",
+				"12-14 function handler (function handler)",
+				"16-17 function helper (function helper)",
+			},
+		},
+		{
+			path: "A.java",
+			src:  "package p;\n\n@Entity\npublic class A extends B {\n  private int x = f(1);\n\n  public A() {\n  }\n\n  @Override\n  public List<String> names(int n)\n      throws IOException {\n    return null;\n  }\n\n  interface Cb {\n    void call();\n  }\n}\n",
+			want: []string{
+				"4-19 class A (class A)",
+				"7-8 constructor A.A (A.A)",
+				"11-14 method A.names (A.names)",
+				"16-18 interface A.Cb (interface Cb)",
+			},
+		},
+		{
+			path: "a.c",
+			src:  "#include <stdio.h>\n\nstruct point {\n  int x;\n};\n\nint add(int a, int b);\n\nstatic int\nadd(int a, int b)\n{\n  if (a) {\n    return a + b;\n  }\n  return b;\n}\n",
+			want: []string{
+				"3-5 struct point (struct point)",
+				"10-16 function add (add)",
+			},
+		},
+		{
+			path: "a.cpp",
+			src:  "namespace app {\nclass Widget : public Base {\npublic:\n  Widget();\n  void draw() const {\n  }\n};\n\nvoid Widget::resize(int w) {\n}\n}\n",
+			want: []string{
+				"1-11 namespace app (namespace app)",
+				"2-7 class Widget (class Widget)",
+				"5-6 function Widget.draw (Widget::draw)",
+				"9-10 function Widget::resize (Widget::resize)",
+			},
+		},
+		{
+			path: "run.sh",
+			src:  "#!/bin/sh\n# {\nbuild() {\n  echo \"${#1} }\"\n}\n\nfunction clean {\n  rm -rf out\n}\n",
+			want: []string{
+				"3-5 function build (build)",
+				"7-9 function clean (clean)",
+			},
+		},
+		{
+			path: "a.html",
+			src:  "<!doctype html>\n<html>\n<!-- <div id=\"no\"> -->\n<body>\n  <div id=\"app\">\n    <p>text <br> more\n    <section id='main'>\n      <img id=logo src=\"a.png\">\n    </section>\n  </div>\n</body>\n</html>\n",
+			want: []string{
+				"5-10 element app (div#app)",
+				"7-9 element app.main (section#main)",
+				"8-8 element main.logo (img#logo)",
+			},
+		},
+	}
+	for _, c := range cases {
+		if got := symbolShape(t, c.path, c.src); got != strings.Join(c.want, "\n") {
+			t.Errorf("%s:\n%s\nwant:\n%s", c.path, got, strings.Join(c.want, "\n"))
+		}
+	}
+
+	if _, _, err := NewProvider().Extract("a.md", []byte("# x\n")); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestFallbackComments(t *testing.T) {
+	_, comms, err := NewProvider().Extract("a.py", []byte("\"\"\"Module doc.\"\"\"\n\ndef f():\n    \"\"\"Doc.\"\"\"\n    x = '# no'  # yes\n"))
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	var got []string
+	for _, c := range comms {
+		got = append(got, fmt.Sprintf("%d:%d-%d:%d %s %s", c.SL, c.SC, c.EL, c.EC, c.Kind, c.Text))
+	}
+	want := []string{
+		"5:17-5:22 line # yes",
+		"1:1-1:18 docstring \"\"\"Module doc.\"\"\"",
+		"4:5-4:15 docstring \"\"\"Doc.\"\"\"",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("comments:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,108 @@
+//go:build treesitter && cgo
+
+package treesitter
+
+import (
+	"strings"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_html "github.com/tree-sitter/tree-sitter-html/bindings/go"
+
+	"otterindex/internal/index/store"
+)
+
+// extractHTML reports elements with an id as "element" symbols named by the
+// id, inside the nearest ancestor with one.
+func extractHTML(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	_ = path
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+
+	lang := tree_sitter.NewLanguage(tree_sitter_html.Language())
+	if err := parser.SetLanguage(lang); err != nil {
+		return nil, nil, err
+	}
+
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	root := tree.RootNode()
+	if root == nil {
+		return nil, nil, nil
+	}
+
+	var syms []store.SymbolInput
+	var comms []store.CommentInput
+
+	var walk func(n *tree_sitter.Node, container string)
+	walk = func(n *tree_sitter.Node, container string) {
+		if n == nil {
+			return
+		}
+
+		k := n.Kind()
+		if isCommentKind(k) {
+			comms = append(comms, makeComment(n, src, "html"))
+		}
+
+		switch k {
+		case "element", "script_element", "style_element":
+			if sym, ok := makeHTMLElement(n, src, container); ok {
+				syms = append(syms, sym)
+				container = sym.Name
+			}
+		}
+
+		for i := uint(0); i < n.NamedChildCount(); i++ {
+			walk(n.NamedChild(i), container)
+		}
+	}
+
+	walk(root, "")
+	return syms, comms, nil
+}
+
+func makeHTMLElement(n *tree_sitter.Node, src []byte, container string) (store.SymbolInput, bool) {
+	var tag *tree_sitter.Node
+	for i := uint(0); i < n.NamedChildCount(); i++ {
+		if ch := n.NamedChild(i); ch != nil && (ch.Kind() == "start_tag" || ch.Kind() == "self_closing_tag") {
+			tag = ch
+			break
+		}
+	}
+	if tag == nil {
+		return store.SymbolInput{}, false
+	}
+
+	tagName, id := "", ""
+	for i := uint(0); i < tag.NamedChildCount(); i++ {
+		ch := tag.NamedChild(i)
+		switch ch.Kind() {
+		case "tag_name":
+			tagName = strings.ToLower(trimNodeText(ch, src))
+		case "attribute":
+			if ch.NamedChildCount() < 2 || !strings.EqualFold(trimNodeText(ch.NamedChild(0), src), "id") {
+				continue
+			}
+			id = strings.Trim(trimNodeText(ch.NamedChild(1), src), `"'`)
+		}
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return store.SymbolInput{}, false
+	}
+
+	sl, sc, el, ec := nodeRange1Based(n)
+	return store.SymbolInput{
+		Kind:      "element",
+		Name:      id,
+		SL:        sl,
+		SC:        sc,
+		EL:        el,
+		EC:        ec,
+		Container: container,
+		Lang:      "html",
+		Signature: tagName + "#" + id,
+	}, true
+}
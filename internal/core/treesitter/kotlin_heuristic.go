@@ -0,0 +1,37 @@
+package treesitter
+
+import (
+	"regexp"
+
+	"otterindex/internal/index/store"
+)
+
+const (
+	kotlinAnnotations = `(?:@[\w.:]+(?:\([^)]*\))?\s+)*`
+	kotlinModifiers   = `(?:(?:public|private|protected|internal|open|abstract|sealed|data|enum|annotation|inner|inline|value|final|override|suspend|operator|infix|tailrec|external|const|expect|actual)\s+)*`
+)
+
+var kotlinTypeRules = []braceRule{
+	{re: regexp.MustCompile(`^\s*` + kotlinAnnotations + kotlinModifiers + `(?:fun\s+)?(?P<kind>class|interface|object)\s+(?P<name>\w+)`)},
+	{re: regexp.MustCompile(`^\s*` + kotlinAnnotations + kotlinModifiers + `(?P<name>companion)\s+object\b`), kind: "object"},
+}
+
+var kotlinLang = braceLang{
+	lang: "kotlin",
+	syn:  lexSyntax{line: []string{"//"}, block: [2]string{"/*", "*/"}, quotes: `"'`, triple: true},
+	top: append(kotlinTypeRules,
+		braceRule{re: regexp.MustCompile(`^\s*` + kotlinAnnotations + kotlinModifiers + `fun\s+(?:<[^>]*>\s*)?(?:[\w.<>?]+\.)?(?P<name>\w+)\s*\(`), kind: "function"},
+	),
+	member: append(kotlinTypeRules,
+		braceRule{re: regexp.MustCompile(`^\s*` + kotlinAnnotations + kotlinModifiers + `fun\s+(?:<[^>]*>\s*)?(?:[\w.<>?]+\.)?(?P<name>\w+)\s*\(`), kind: "method"},
+		braceRule{re: regexp.MustCompile(`^\s*` + kotlinAnnotations + `(?:(?:public|private|protected|internal)\s+)?(?P<name>constructor)\s*\(`), kind: "constructor"},
+	),
+	funcSig: "fun ",
+	sep:     ".",
+}
+
+func extractKotlin(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	_ = path
+	syms, comms := extractBraces(kotlinLang, src)
+	return syms, comms, nil
+}
@@ -0,0 +1,150 @@
+package treesitter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// symbolShape renders symbols as "SL-EL kind Container.Name (signature)".
+func symbolShape(t *testing.T, path string, src string) string {
+	t.Helper()
+	syms, _, err := NewProvider().Extract(path, []byte(src))
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	var out []string
+	for _, s := range syms {
+		name := s.Name
+		if s.Container != "" {
+			name = s.Container + "." + name
+		}
+		out = append(out, fmt.Sprintf("%d-%d %s %s (%s)", s.SL, s.EL, s.Kind, name, s.Signature))
+	}
+	return strings.Join(out, "\n")
+}
+
+// These languages have no vendored grammar and are parsed the same way in
+// both builds.
+func TestExtract_PureGoLanguages(t *testing.T) {
+	cases := []struct {
+		path string
+		src  string
+		want []string
+	}{
+		{
+			path: "a.rs",
+			src:  "pub struct Point<'a> {\n    x: &'a str,\n}\n\nimpl<'a> fmt::Display for Point<'a> {\n    fn fmt(&self) -> String {\n        let c = '{';\n        r#\"}\"#.to_string()\n    }\n}\n\npub(crate) mod util {\n    pub async fn helper() {}\n}\n\ntrait Shape {\n    fn area(&self) -> f64;\n}\n\nmacro_rules! square { ($x:expr) => { $x * $x }; }\n",
+			want: []string{
+				"1-3 struct Point (struct Point)",
+				"5-10 impl Point (impl Point)",
+				"6-9 method Point.fmt (Point::fmt)",
+				"12-14 module util (module util)",
+				"13-13 function helper (fn helper)",
+				"16-18 trait Shape (trait Shape)",
+				"20-20 macro square (macro square)",
+			},
+		},
+		{
+			path: "a.kt",
+			src:  "data class P(val x: Int)\n\n@Service\nclass Repo(private val db: Db) : Base() {\n    fun find(id: String): P? {\n        return null\n    }\n    fun size() = items.size\n    val items = listOf(1).map { it }\n    companion object {\n        fun create(): Repo { return Repo(Db()) }\n    }\n}\n\nfun main() {\n    println(\"}\")\n}\n",
+			want: []string{
+				"4-13 class Repo (class Repo)",
+				"5-7 method Repo.find (Repo.find)",
+				"10-12 object Repo.companion (object companion)",
+				"11-11 method companion.create (companion.create)",
+				"15-17 function main (fun main)",
+			},
+		},
+		{
+			path: "a.swift",
+			src:  "struct User {\n    init(name: String) { self.name = name }\n    func greet() -> String {\n        return \"}\"\n    }\n}\n\nextension User: Codable {\n    static func load() -> User? { nil }\n}\n\nfunc run() {\n}\n",
+			want: []string{
+				"1-6 struct User (struct User)",
+				"2-2 constructor User.init (User.init)",
+				"3-5 method User.greet (User.greet)",
+				"8-10 extension User (extension User)",
+				"9-9 method User.load (User.load)",
+				"12-13 function run (func run)",
+			},
+		},
+		{
+			path: "a.rb",
+			src:  "module Tools\n  class Runner < Base\n    def initialize(name)\n      return if name.nil?\n      items.each do |i|\n        puts i\n      end\n    end\n\n    def self.build = new(\"x\")\n\n    def ready?\n      x = if @name then 1 else 2 end\n      text = <<~EOS\n        def fake\n      EOS\n    end\n  end\nend\n\ndef top\nend\n",
+			want: []string{
+				"1-19 module Tools (module Tools)",
+				"2-18 class Tools.Runner (class Runner)",
+				"3-8 method Runner.initialize (Runner.initialize)",
+				"10-10 method Runner.build (Runner.build)",
+				"12-17 method Runner.ready? (Runner.ready?)",
+				"21-22 function top (def top)",
+			},
+		},
+		{
+			path: "a.lua",
+			src:  "local M = {}\n\nfunction M.new(x)\n  local s = [[ end ]]\n  if x then return x end\nend\n\nfunction M:run()\n  for i = 1, 3 do print(i) end\nend\n\nlocal helper = function(a)\n  repeat a = a - 1 until a < 0\nend\n",
+			want: []string{
+				"3-6 function M.new (M.new)",
+				"8-10 method M.run (M:run)",
+				"12-14 function helper (function helper)",
+			},
+		},
+		{
+			path: "a.yaml",
+			src:  "# config\nserver:\n  port: 8080\n  tls:\n    cert: \"a.pem\"\n  banner: |\n    not: a key\njobs:\n  - name: build\n    steps: [a, b]\n\"quoted key\": 1\n",
+			want: []string{
+				"2-7 key server (server)",
+				"3-3 key server.port (server.port)",
+				"4-5 key server.tls (server.tls)",
+				"5-5 key server.tls.cert (server.tls.cert)",
+				"6-7 key server.banner (server.banner)",
+				"8-10 key jobs (jobs)",
+				"9-9 key jobs.name (jobs.name)",
+				"10-10 key jobs.steps (jobs.steps)",
+				"11-11 key quoted key (quoted key)",
+			},
+		},
+		{
+			path: "a.toml",
+			src:  "title = \"x\"\n\n[server]\nport = 8080\nhosts = [\n  \"a\",\n]\n\n[server.tls]\ncert = \"\"\"\nmulti\n\"\"\"\n\n[[jobs]]\ntls.enabled = true\n",
+			want: []string{
+				"1-1 key title (title)",
+				"3-7 table server (server)",
+				"4-4 key server.port (server.port)",
+				"5-7 key server.hosts (server.hosts)",
+				"9-12 table server.tls (server.tls)",
+				"10-12 key server.tls.cert (server.tls.cert)",
+				"14-15 table jobs (jobs)",
+				"15-15 key jobs.tls.enabled (jobs.tls.enabled)",
+			},
+		},
+		{
+			path: "a.sql",
+			src:  "CREATE TABLE IF NOT EXISTS public.users (\n  id BIGSERIAL PRIMARY KEY,\n  email TEXT NOT NULL, -- unique; not a statement end\n  CONSTRAINT users_email UNIQUE (email)\n);\n\nCREATE INDEX idx_users_email ON users (email);\n\nCREATE OR REPLACE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\n\nCREATE MATERIALIZED VIEW stats AS SELECT count(*) FROM users;\n",
+			want: []string{
+				"1-5 table public.users (table public.users)",
+				"2-2 column users.id (users.id)",
+				"3-3 column users.email (users.email)",
+				"7-7 index idx_users_email (index idx_users_email)",
+				"9-13 function touch (function touch)",
+				"15-15 view stats (view stats)",
+			},
+		},
+		{
+			path: "a.css",
+			src:  "/* { */\nbody, html {\n  margin: 0;\n}\n@media (max-width: 600px) {\n  .nav a:hover { color: red; }\n}\n.card {\n  &:hover { content: \"}\"; }\n}\n",
+			want: []string{
+				"2-4 rule body, html (body, html)",
+				"5-7 at-rule @media (max-width: 600px) (@media (max-width: 600px))",
+				"6-6 rule @media (max-width: 600px)..nav a:hover (.nav a:hover)",
+				"8-10 rule .card (.card)",
+				"9-9 rule .card.&:hover (&:hover)",
+			},
+		},
+	}
+	for _, c := range cases {
+		if got := symbolShape(t, c.path, c.src); got != strings.Join(c.want, "\n") {
+			t.Errorf("%s:\n%s\nwant:\n%s", c.path, got, strings.Join(c.want, "\n"))
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package treesitter
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"otterindex/internal/index/store"
+)
+
+// lexSyntax is what the fallback extractors need to know about a language's
+// comments and string literals.
+type lexSyntax struct {
+	line  []string
+	block [2]string
+	// quotes are the string delimiters; strings end at the line end unless
+	// the quote is listed in multiline too.
+	quotes    string
+	multiline string
+	// triple enables Python's """/''' strings.
+	triple bool
+	// hashWord makes '#' start a comment only at the start of a word (shell:
+	// $# and ${#x} are not comments).
+	hashWord bool
+	// chars makes single quotes delimit one character only, so Rust's 'a
+	// lifetimes stay code.
+	chars bool
+	// dollar enables PostgreSQL's $$...$$ and $tag$...$tag$ strings.
+	dollar bool
+	// raw are multi-line strings without escapes: Lua's [[...]], Rust's
+	// r#"..."#.
+	raw [][2]string
+}
+
+// span is the range of a triple-quoted string, a possible Python docstring.
+type span struct {
+	sl, sc, el, ec int
+}
+
+// lexed is a file with its comments and the contents of its string literals
+// blanked out, so braces, colons and keywords can be matched on code only.
+// code has the same line and byte layout as lines.
+type lexed struct {
+	lines   []string
+	code    []string
+	comms   []store.CommentInput
+	triples []span
+}
+
+func lex(src []byte, syn lexSyntax, lang string) lexed {
+	text := string(src)
+	out := []byte(text)
+	pos := positions(text)
+	blank := func(from, to int) {
+		for i := from; i < to; i++ {
+			if out[i] != '\n' && out[i] != '\r' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	var res lexed
+	comment := func(kind string, from, to int) {
+		sl, sc := pos(from)
+		el, ec := pos(to)
+		res.comms = append(res.comms, store.CommentInput{
+			Kind: kind,
+			Text: strings.TrimRight(text[from:to], "\r\n"),
+			SL:   sl,
+			SC:   sc,
+			EL:   el,
+			EC:   ec,
+			Lang: lang,
+		})
+		blank(from, to)
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		if syn.block[0] != "" && strings.HasPrefix(text[i:], syn.block[0]) {
+			end := strings.Index(text[i+len(syn.block[0]):], syn.block[1])
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += i + len(syn.block[0]) + len(syn.block[1])
+			}
+			comment("block", i, end)
+			i = end
+			continue
+		}
+		if lineComment(text, i, syn) {
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += i
+			}
+			to := end
+			if to > i && text[to-1] == '\r' {
+				to--
+			}
+			comment("line", i, to)
+			i = end
+			continue
+		}
+		if syn.triple && (strings.HasPrefix(text[i:], `"""`) || strings.HasPrefix(text[i:], `'''`)) {
+			q := text[i : i+3]
+			end := i + 3
+			for end < len(text) && !strings.HasPrefix(text[end:], q) {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+3, len(text))
+			sl, sc := pos(i)
+			el, ec := pos(end)
+			res.triples = append(res.triples, span{sl: sl, sc: sc, el: el, ec: ec})
+			blank(i+3, max(end-3, i+3))
+			i = end
+			continue
+		}
+		if d, ok := rawString(text[i:], syn); ok {
+			end := strings.Index(text[i+len(d[0]):], d[1])
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += i + len(d[0])
+			}
+			blank(i+len(d[0]), end)
+			i = min(end+len(d[1]), len(text))
+			continue
+		}
+		if syn.chars && c == '\'' {
+			end := charLiteralEnd(text, i)
+			blank(i+1, max(end-1, i+1))
+			i = end
+			continue
+		}
+		if syn.dollar && c == '$' {
+			if tag := dollarTag(text[i:]); tag != "" {
+				end := strings.Index(text[i+len(tag):], tag)
+				if end < 0 {
+					end = len(text)
+				} else {
+					end += i + len(tag)
+				}
+				blank(i+len(tag), end)
+				i = min(end+len(tag), len(text))
+				continue
+			}
+		}
+		if strings.IndexByte(syn.quotes, c) >= 0 {
+			multi := strings.IndexByte(syn.multiline, c) >= 0
+			end := i + 1
+			for end < len(text) && text[end] != c && (multi || text[end] != '\n') {
+				if text[end] == '\\' && !(syn.hashWord && c == '\'') {
+					end++
+				}
+				end++
+			}
+			blank(i+1, min(end, len(text)))
+			i = min(end+1, len(text))
+			continue
+		}
+		i++
+	}
+
+	res.lines = strings.Split(text, "\n")
+	res.code = strings.Split(string(out), "\n")
+	return res
+}
+
+// positions maps byte offsets of text to 1-based lines and columns.
+func positions(text string) func(off int) (int, int) {
+	starts := []int{0} // line start offsets
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return func(off int) (int, int) {
+		l := sort.SearchInts(starts, off+1) - 1
+		return l + 1, off - starts[l] + 1
+	}
+}
+
+func lineComment(text string, i int, syn lexSyntax) bool {
+	for _, p := range syn.line {
+		if !strings.HasPrefix(text[i:], p) {
+			continue
+		}
+		if p == "#" && syn.hashWord && i > 0 && !strings.ContainsRune(" \t\n;|&(", rune(text[i-1])) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func rawString(s string, syn lexSyntax) ([2]string, bool) {
+	for _, d := range syn.raw {
+		if strings.HasPrefix(s, d[0]) {
+			return d, true
+		}
+	}
+	return [2]string{}, false
+}
+
+// charLiteralEnd is the offset after the character literal at text[i], or
+// i+1 when the quote opens none ('a in a lifetime).
+func charLiteralEnd(text string, i int) int {
+	j := i + 1
+	if j+2 <= len(text) && text[j] == '\\' {
+		if k := strings.IndexByte(text[j+2:min(j+12, len(text))], '\''); k >= 0 {
+			return j + 2 + k + 1
+		}
+		return i + 1
+	}
+	_, size := utf8.DecodeRuneInString(text[j:])
+	if size > 0 && j+size < len(text) && text[j+size] == '\'' && text[j] != '\n' {
+		return j + size + 1
+	}
+	return i + 1
+}
+
+// dollarTag is the $tag$ opening a dollar-quoted string at the start of s.
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case c == '$':
+			return s[:j+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || j > 1 && c >= '0' && c <= '9':
+		default:
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package treesitter
+
+import (
+	"regexp"
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+var (
+	luaFunctionRe = regexp.MustCompile(`^\s*(?:local\s+)?function\s+([\w.:]+)\s*\(`)
+	luaAssignRe   = regexp.MustCompile(`^\s*(?:local\s+)?([\w.]+)\s*=\s*function\s*\(`)
+)
+
+var luaLang = blockLang{
+	words: func(code string) []blockWord {
+		var out []blockWord
+		for _, id := range identifiers(code) {
+			switch id.word {
+			case "function", "do", "if", "repeat":
+				out = append(out, blockWord{end: id.pos + len(id.word), open: true})
+			case "end", "until":
+				out = append(out, blockWord{end: id.pos + len(id.word)})
+			}
+		}
+		return out
+	},
+	decl: func(code string, outer []store.SymbolInput) (store.SymbolInput, bool) {
+		_ = outer
+		m := luaFunctionRe.FindStringSubmatch(code)
+		if m == nil {
+			m = luaAssignRe.FindStringSubmatch(code)
+		}
+		if m == nil {
+			return store.SymbolInput{}, false
+		}
+		// function M.util.f() is f in M.util; function M:f() is a method.
+		full := m[1]
+		sym := store.SymbolInput{Kind: "function", Name: full, Lang: "lua", Signature: "function " + full}
+		if i := strings.LastIndexAny(full, ".:"); i > 0 {
+			sym.Name, sym.Container, sym.Signature = full[i+1:], full[:i], full
+			if full[i] == ':' {
+				sym.Kind = "method"
+			}
+		}
+		return sym, sym.Name != ""
+	},
+}
+
+func extractLua(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	_ = path
+	lx := lex(src, lexSyntax{
+		line:   []string{"--"},
+		block:  [2]string{"--[[", "]]"},
+		quotes: `"'`,
+		raw:    [][2]string{{"[[", "]]"}, {"[=[", "]=]"}, {"[==[", "]==]"}},
+	}, "lua")
+	return extractBlocks(luaLang, lx), lx.comms, nil
+}
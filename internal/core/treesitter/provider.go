@@ -3,9 +3,7 @@
 package treesitter
 
 import (
-	"path/filepath"
-	"strings"
-
+	"otterindex/internal/core/lang"
 	"otterindex/internal/index/store"
 )
 
@@ -13,36 +11,81 @@ type Provider struct{}
 
 func NewProvider() *Provider { return &Provider{} }
 
+// Enabled reports whether this is a tree-sitter build.
+func Enabled() bool { return true }
+
+// Extractor names what Extract uses for most languages; see
+// HeuristicLanguages.
+func Extractor() string { return "tree-sitter" }
+
+// heuristicLanguages have no vendored grammar, so both builds parse them in
+// pure Go (the *_heuristic.go files).
+var heuristicLanguages = []string{"rust", "ruby", "kotlin", "swift", "lua", "yaml", "toml", "css", "sql"}
+
+// HeuristicLanguages lists the languages Extract parses without tree-sitter.
+func HeuristicLanguages() []string { return heuristicLanguages }
+
+// ExtractorFor names what Extract uses for path.
+func ExtractorFor(path string) string {
+	l := lang.FromPath(path)
+	for _, h := range heuristicLanguages {
+		if l == h {
+			return "heuristic"
+		}
+	}
+	return Extractor()
+}
+
+// Extract dispatches on the language registry in internal/core/lang, so -t
+// filters and extraction agree on what a file is.
 func (p *Provider) Extract(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
-	ext := strings.ToLower(filepath.Ext(strings.TrimSpace(path)))
-	switch ext {
-	case ".go":
+	switch lang.FromPath(path) {
+	case "go":
 		return extractGo(path, src)
-	case ".java":
+	case "java":
 		return extractJava(path, src)
-	case ".py":
+	case "python":
 		return extractPython(path, src)
-	case ".js", ".jsx", ".mjs", ".cjs":
+	case "javascript":
 		return extractJavaScript(path, src)
-	case ".ts":
+	case "typescript":
 		return extractTypeScript(path, src)
-	case ".tsx":
+	case "tsx":
 		return extractTSX(path, src)
-	case ".php":
+	case "php":
 		return extractPHP(path, src)
-	case ".cs", ".csx":
+	case "csharp":
 		return extractCSharp(path, src)
-	case ".json", ".jsonc":
+	case "json":
 		return extractJSON(path, src)
-	case ".sh", ".bash":
+	case "bash":
 		return extractBash(path, src)
-	case ".c":
+	case "c":
 		return extractC(path, src)
-	case ".cc", ".cpp", ".cxx", ".hpp", ".hh", ".hxx":
-		return extractCPP(path, src)
-	case ".h":
-		// Prefer C++ for headers; it can usually parse C too.
+	case "cpp":
+		// Headers are parsed as C++; it can usually parse C too.
 		return extractCPP(path, src)
+	case "html":
+		return extractHTML(path, src)
+	// heuristicLanguages:
+	case "rust":
+		return extractRust(path, src)
+	case "ruby":
+		return extractRuby(path, src)
+	case "kotlin":
+		return extractKotlin(path, src)
+	case "swift":
+		return extractSwift(path, src)
+	case "lua":
+		return extractLua(path, src)
+	case "yaml":
+		return extractYAML(path, src)
+	case "toml":
+		return extractTOML(path, src)
+	case "css":
+		return extractCSS(path, src)
+	case "sql":
+		return extractSQL(path, src)
 	default:
 		return nil, nil, ErrUnsupported
 	}
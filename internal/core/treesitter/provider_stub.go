@@ -3,6 +3,7 @@
 package treesitter
 
 import (
+	"otterindex/internal/core/lang"
 	"otterindex/internal/index/store"
 )
 
@@ -10,6 +11,85 @@ type Provider struct{}
 
 func NewProvider() *Provider { return &Provider{} }
 
+// Enabled reports whether this is a tree-sitter build.
+func Enabled() bool { return false }
+
+// Extractor names what Extract uses: without tree-sitter, go/parser for Go
+// and line heuristics for other languages, so symbols are less precise.
+func Extractor() string { return "heuristic" }
+
+// HeuristicLanguages is empty: Extractor already says every language is
+// parsed without tree-sitter.
+func HeuristicLanguages() []string { return nil }
+
+// ExtractorFor names what Extract uses for path.
+func ExtractorFor(path string) string { return Extractor() }
+
+// Extract is the pure-Go fallback of the tree-sitter provider: same symbol
+// kinds, names and signatures, found by go/parser, indentation (Python) or
+// brace matching outside comments and strings.
 func (p *Provider) Extract(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
-	return nil, nil, ErrDisabled
+	var syms []store.SymbolInput
+	var comms []store.CommentInput
+	switch l := lang.FromPath(path); l {
+	case "go":
+		return extractGoFallback(path, src)
+	case "python":
+		syms, comms = extractPythonFallback(src)
+	case "javascript", "typescript", "tsx":
+		syms, comms = extractBraces(withLang(jsLike, l), src)
+	case "java":
+		syms, comms = extractBraces(javaLang, src)
+	case "csharp":
+		syms, comms = extractBraces(csharpLang, src)
+	case "php":
+		syms, comms = extractBraces(phpLang, src)
+	case "c":
+		syms, comms = extractBraces(cLang, src)
+	case "cpp":
+		syms, comms = extractBraces(cppLang, src)
+	case "bash":
+		syms, comms = extractBraces(bashLang, src)
+	case "html":
+		syms, comms = extractHTMLFallback(src)
+	case "rust":
+		return extractRust(path, src)
+	case "ruby":
+		return extractRuby(path, src)
+	case "kotlin":
+		return extractKotlin(path, src)
+	case "swift":
+		return extractSwift(path, src)
+	case "lua":
+		return extractLua(path, src)
+	case "yaml":
+		return extractYAML(path, src)
+	case "toml":
+		return extractTOML(path, src)
+	case "css":
+		return extractCSS(path, src)
+	case "sql":
+		return extractSQL(path, src)
+	default:
+		return nil, nil, ErrUnsupported
+	}
+	return syms, comms, nil
+}
+
+func (p *Provider) ExtractRefs(path string, src []byte) ([]store.RefInput, error) {
+	return nil, ErrDisabled
+}
+
+func ASTLanguages() []string { return nil }
+
+type ASTQuery struct{}
+
+func CompileASTQuery(lang string, pattern string) (*ASTQuery, error) {
+	return nil, ErrDisabled
+}
+
+func (a *ASTQuery) Close() {}
+
+func (a *ASTQuery) Match(src []byte) ([]ASTMatch, error) {
+	return nil, ErrDisabled
 }
@@ -2,7 +2,10 @@
 
 package treesitter
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestExtractGoSymbolsAndComments(t *testing.T) {
 	src := []byte(`package main
@@ -111,6 +114,71 @@ namespace N {
 			path: "a.sh",
 			src: `# hi
 foo() { echo hi; }
+`,
+		},
+		{
+			path: "a.rs",
+			src: `// hi
+struct Foo;
+impl Foo { fn bar(&self) {} }
+`,
+		},
+		{
+			path: "a.rb",
+			src: `# hi
+class Foo
+  def bar; end
+end
+`,
+		},
+		{
+			path: "a.kt",
+			src: `// hi
+class Foo { fun bar() {} }
+`,
+		},
+		{
+			path: "a.swift",
+			src: `// hi
+struct Foo { func bar() {} }
+`,
+		},
+		{
+			path: "a.lua",
+			src: `-- hi
+function foo() end
+`,
+		},
+		{
+			path: "a.yaml",
+			src: `# hi
+server:
+  port: 80
+`,
+		},
+		{
+			path: "a.toml",
+			src: `# hi
+[server]
+port = 80
+`,
+		},
+		{
+			path: "a.html",
+			src: `<!-- hi -->
+<div id="app"><p id="x">hi</p></div>
+`,
+		},
+		{
+			path: "a.css",
+			src: `/* hi */
+.foo { color: red; }
+`,
+		},
+		{
+			path: "a.sql",
+			src: `-- hi
+CREATE TABLE foo (id INT);
 `,
 		},
 	}
@@ -126,3 +194,125 @@ foo() { echo hi; }
 		}
 	}
 }
+
+func TestExtractorFor(t *testing.T) {
+	for path, want := range map[string]string{
+		"a.go":   "tree-sitter",
+		"a.html": "tree-sitter",
+		"a.rs":   "heuristic",
+		"a.yaml": "heuristic",
+		"a.sql":  "heuristic",
+	} {
+		if got := ExtractorFor(path); got != want {
+			t.Fatalf("%s: got %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestExtractHTMLIDs(t *testing.T) {
+	src := []byte(`<!-- <div id="no"> -->
+<body>
+  <div id="app">
+    <section id='main'><img id=logo src="a.png"></section>
+  </div>
+</body>
+`)
+	syms, comms, err := NewProvider().Extract("a.html", src)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	var got []string
+	for _, s := range syms {
+		got = append(got, s.Container+"/"+s.Signature)
+	}
+	want := []string{"/div#app", "app/section#main", "main/img#logo"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("symbols: %v, want %v", got, want)
+	}
+	if len(comms) != 1 {
+		t.Fatalf("comments: %+v", comms)
+	}
+}
+
+func TestExtractPythonDocstrings(t *testing.T) {
+	src := []byte(`"""Module doc."""
+
+class Foo:
+    """Foo doc."""
+
+    def bar(self):
+        """Bar doc."""
+        x = "not a docstring"
+`)
+	_, comms, err := NewProvider().Extract("a.py", src)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	var lines []int
+	for _, c := range comms {
+		if c.Kind == "docstring" {
+			lines = append(lines, c.SL)
+		}
+	}
+	if len(lines) != 3 || lines[0] != 1 || lines[1] != 4 || lines[2] != 7 {
+		t.Fatalf("docstrings at %v: %+v", lines, comms)
+	}
+}
+
+func TestExtractRefs(t *testing.T) {
+	src := []byte(`package main
+
+import "os/exec"
+
+func run(cmd string) error {
+	c := exec.Command(cmd)
+	return c.Run()
+}
+`)
+	refs, err := NewProvider().ExtractRefs("a.go", src)
+	if err != nil {
+		t.Fatalf("extract refs: %v", err)
+	}
+	has := func(kind, name string, line int) bool {
+		for _, r := range refs {
+			if r.Kind == kind && r.Name == name && r.SL == line {
+				return true
+			}
+		}
+		return false
+	}
+	if !has("import", "os/exec", 3) || !has("call", "Command", 6) || !has("call", "Run", 7) || !has("ref", "cmd", 6) {
+		t.Fatalf("missing refs: %+v", refs)
+	}
+	if has("ref", "run", 5) || has("ref", "cmd", 5) {
+		t.Fatalf("declared names recorded as refs: %+v", refs)
+	}
+}
+
+func TestExtractRefs_Imports(t *testing.T) {
+	cases := []struct {
+		path string
+		src  string
+		want []string
+	}{
+		{path: "a.js", src: "import x from './a';\nconst fs = require('fs');\n", want: []string{"./a", "fs"}},
+		{path: "a.c", src: "#include <stdio.h>\n#include \"util.h\"\n", want: []string{"stdio.h", "util.h"}},
+		{path: "a.php", src: "<?php\nuse Foo\\Bar;\nuse A\\{B, C as D};\n", want: []string{"Foo\\Bar", "A\\B", "A\\C"}},
+		{path: "a.py", src: "import os\nfrom pkg import mod\n", want: []string{"os", "pkg", "pkg.mod"}},
+	}
+	for _, c := range cases {
+		refs, err := NewProvider().ExtractRefs(c.path, []byte(c.src))
+		if err != nil {
+			t.Fatalf("%s: extract refs: %v", c.path, err)
+		}
+		var got []string
+		for _, r := range refs {
+			if r.Kind == "import" {
+				got = append(got, r.Name)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(c.want, ",") {
+			t.Fatalf("%s: imports=%v want %v", c.path, got, c.want)
+		}
+	}
+}
@@ -46,6 +46,10 @@ func extractPython(path string, src []byte) ([]store.SymbolInput, []store.Commen
 			comms = append(comms, makeComment(n, src, "python"))
 		}
 
+		if doc, ok := pythonDocstring(n, src); ok {
+			comms = append(comms, doc)
+		}
+
 		switch k {
 		case "class_definition":
 			if sym, ok := makePythonClass(n, src); ok {
@@ -109,3 +113,30 @@ func makePythonFunction(n *tree_sitter.Node, src []byte) (store.SymbolInput, boo
 		Signature: sig,
 	}, true
 }
+
+// pythonDocstring returns the docstring of a module, class or function: a
+// string literal that is the first statement of its body.
+func pythonDocstring(n *tree_sitter.Node, src []byte) (store.CommentInput, bool) {
+	body := n
+	switch n.Kind() {
+	case "module":
+	case "class_definition", "function_definition":
+		body = n.ChildByFieldName("body")
+	default:
+		return store.CommentInput{}, false
+	}
+	if body == nil || body.NamedChildCount() == 0 {
+		return store.CommentInput{}, false
+	}
+	stmt := body.NamedChild(0)
+	if stmt == nil || stmt.Kind() != "expression_statement" || stmt.NamedChildCount() != 1 {
+		return store.CommentInput{}, false
+	}
+	str := stmt.NamedChild(0)
+	if str == nil || str.Kind() != "string" {
+		return store.CommentInput{}, false
+	}
+	doc := makeComment(str, src, "python")
+	doc.Kind = "docstring"
+	return doc, true
+}
@@ -0,0 +1,382 @@
+//go:build treesitter && cgo
+
+package treesitter
+
+import (
+	"strings"
+	"unsafe"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_c "github.com/tree-sitter/tree-sitter-c/bindings/go"
+	tree_sitter_cpp "github.com/tree-sitter/tree-sitter-cpp/bindings/go"
+	tree_sitter_go "github.com/tree-sitter/tree-sitter-go/bindings/go"
+	tree_sitter_java "github.com/tree-sitter/tree-sitter-java/bindings/go"
+	tree_sitter_js "github.com/tree-sitter/tree-sitter-javascript/bindings/go"
+	tree_sitter_php "github.com/tree-sitter/tree-sitter-php/bindings/go"
+	tree_sitter_python "github.com/tree-sitter/tree-sitter-python/bindings/go"
+	tree_sitter_ts "github.com/tree-sitter/tree-sitter-typescript/bindings/go"
+
+	"otterindex/internal/core/lang"
+	"otterindex/internal/index/store"
+)
+
+// refSpec describes how to find references in one grammar.
+type refSpec struct {
+	lang     string
+	language func() unsafe.Pointer
+	// idents are the identifier node kinds recorded as "ref".
+	idents map[string]struct{}
+	// calls maps call node kinds to the field holding the callee ("" for
+	// the first named child).
+	calls map[string]string
+	// imports returns the imported names of an import node, or ok=false
+	// when n is not an import.
+	imports func(n *tree_sitter.Node, src []byte) (names []string, ok bool)
+}
+
+// calleeFields lead from a member/selector expression to the called name.
+var calleeFields = []string{"field", "property", "attribute", "name", "type"}
+
+var goRefSpec = refSpec{
+	lang:     "go",
+	language: tree_sitter_go.Language,
+	idents: map[string]struct{}{
+		"identifier":       {},
+		"type_identifier":  {},
+		"field_identifier": {},
+	},
+	calls: map[string]string{
+		"call_expression": "function",
+	},
+	imports: func(n *tree_sitter.Node, src []byte) ([]string, bool) {
+		switch n.Kind() {
+		case "import_spec":
+			return []string{unquote(trimNodeText(n.ChildByFieldName("path"), src))}, true
+		}
+		return nil, false
+	},
+}
+
+var javaRefSpec = refSpec{
+	lang:     "java",
+	language: tree_sitter_java.Language,
+	idents: map[string]struct{}{
+		"identifier":      {},
+		"type_identifier": {},
+	},
+	calls: map[string]string{
+		"method_invocation":          "name",
+		"object_creation_expression": "type",
+	},
+	imports: func(n *tree_sitter.Node, src []byte) ([]string, bool) {
+		if n.Kind() != "import_declaration" {
+			return nil, false
+		}
+		for i := uint(0); i < n.NamedChildCount(); i++ {
+			ch := n.NamedChild(i)
+			if k := ch.Kind(); k == "scoped_identifier" || k == "identifier" {
+				return []string{trimNodeText(ch, src)}, true
+			}
+		}
+		return nil, true
+	},
+}
+
+var pythonRefSpec = refSpec{
+	lang:     "python",
+	language: tree_sitter_python.Language,
+	idents: map[string]struct{}{
+		"identifier": {},
+	},
+	calls: map[string]string{
+		"call": "function",
+	},
+	imports: func(n *tree_sitter.Node, src []byte) ([]string, bool) {
+		switch n.Kind() {
+		case "import_statement":
+			var names []string
+			for i := uint(0); i < n.NamedChildCount(); i++ {
+				names = append(names, pythonImportName(n.NamedChild(i), src))
+			}
+			return names, true
+		case "import_from_statement":
+			module := trimNodeText(n.ChildByFieldName("module_name"), src)
+			names := []string{module}
+			for i := uint(0); i < n.NamedChildCount(); i++ {
+				if n.FieldNameForNamedChild(uint32(i)) != "name" {
+					continue
+				}
+				if name := pythonImportName(n.NamedChild(i), src); name != "" {
+					names = append(names, strings.TrimSuffix(module, ".")+"."+name)
+				}
+			}
+			return names, true
+		}
+		return nil, false
+	},
+}
+
+func tsRefSpec(lang string, language func() unsafe.Pointer) refSpec {
+	return refSpec{
+		lang:     lang,
+		language: language,
+		idents: map[string]struct{}{
+			"identifier":          {},
+			"type_identifier":     {},
+			"property_identifier": {},
+		},
+		calls: map[string]string{
+			"call_expression": "function",
+			"new_expression":  "constructor",
+		},
+		imports: jsImports,
+	}
+}
+
+var jsRefSpec = refSpec{
+	lang:     "javascript",
+	language: tree_sitter_js.Language,
+	idents: map[string]struct{}{
+		"identifier":          {},
+		"property_identifier": {},
+	},
+	calls: map[string]string{
+		"call_expression": "function",
+		"new_expression":  "constructor",
+	},
+	imports: jsImports,
+}
+
+// jsImports handles both ES imports and CommonJS require("...") calls.
+func jsImports(n *tree_sitter.Node, src []byte) ([]string, bool) {
+	switch n.Kind() {
+	case "import_statement":
+		return []string{unquote(trimNodeText(n.ChildByFieldName("source"), src))}, true
+	case "call_expression":
+		fn := n.ChildByFieldName("function")
+		if fn == nil || fn.Kind() != "identifier" || trimNodeText(fn, src) != "require" {
+			return nil, false
+		}
+		args := n.ChildByFieldName("arguments")
+		if args == nil || args.NamedChildCount() == 0 || args.NamedChild(0).Kind() != "string" {
+			return nil, false
+		}
+		return []string{unquote(trimNodeText(args.NamedChild(0), src))}, true
+	}
+	return nil, false
+}
+
+func cRefSpec(lang string, language func() unsafe.Pointer) refSpec {
+	return refSpec{
+		lang:     lang,
+		language: language,
+		idents: map[string]struct{}{
+			"identifier":       {},
+			"type_identifier":  {},
+			"field_identifier": {},
+		},
+		calls: map[string]string{
+			"call_expression": "function",
+		},
+		imports: func(n *tree_sitter.Node, src []byte) ([]string, bool) {
+			if n.Kind() != "preproc_include" {
+				return nil, false
+			}
+			return []string{strings.Trim(trimNodeText(n.ChildByFieldName("path"), src), "\"<>")}, true
+		},
+	}
+}
+
+var phpRefSpec = refSpec{
+	lang:     "php",
+	language: tree_sitter_php.LanguagePHP,
+	idents: map[string]struct{}{
+		"name": {},
+	},
+	calls: map[string]string{
+		"function_call_expression":   "function",
+		"member_call_expression":     "name",
+		"scoped_call_expression":     "name",
+		"object_creation_expression": "",
+	},
+	imports: func(n *tree_sitter.Node, src []byte) ([]string, bool) {
+		if n.Kind() != "namespace_use_declaration" {
+			return nil, false
+		}
+		// "use A\{B, C}" imports A\B and A\C.
+		prefix := ""
+		var names []string
+		var clauses func(n *tree_sitter.Node)
+		clauses = func(n *tree_sitter.Node) {
+			for i := uint(0); i < n.NamedChildCount(); i++ {
+				ch := n.NamedChild(i)
+				switch ch.Kind() {
+				case "namespace_name":
+					prefix = trimNodeText(ch, src) + "\\"
+				case "namespace_use_group":
+					clauses(ch)
+				case "namespace_use_clause":
+					if ch.NamedChildCount() > 0 {
+						names = append(names, prefix+strings.TrimLeft(trimNodeText(ch.NamedChild(0), src), "\\"))
+					}
+				}
+			}
+		}
+		clauses(n)
+		return names, true
+	},
+}
+
+// ExtractRefs returns the references in a Go, Java, Python, JavaScript,
+// TypeScript, C, C++ or PHP file: identifier uses ("ref"), called names
+// ("call") and imports/includes ("import"). Names being declared are skipped.
+func (p *Provider) ExtractRefs(path string, src []byte) ([]store.RefInput, error) {
+	var spec refSpec
+	switch lang.FromPath(path) {
+	case "go":
+		spec = goRefSpec
+	case "java":
+		spec = javaRefSpec
+	case "python":
+		spec = pythonRefSpec
+	case "typescript":
+		spec = tsRefSpec("typescript", tree_sitter_ts.LanguageTypescript)
+	case "tsx":
+		spec = tsRefSpec("tsx", tree_sitter_ts.LanguageTSX)
+	case "javascript":
+		spec = jsRefSpec
+	case "php":
+		spec = phpRefSpec
+	case "c":
+		spec = cRefSpec("c", tree_sitter_c.Language)
+	case "cpp":
+		spec = cRefSpec("cpp", tree_sitter_cpp.Language)
+	default:
+		return nil, ErrUnsupported
+	}
+	return extractRefs(src, spec)
+}
+
+func extractRefs(src []byte, spec refSpec) ([]store.RefInput, error) {
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+
+	lang := tree_sitter.NewLanguage(spec.language())
+	if err := parser.SetLanguage(lang); err != nil {
+		return nil, err
+	}
+
+	tree := parser.Parse(src, nil)
+	defer tree.Close()
+
+	root := tree.RootNode()
+	if root == nil {
+		return nil, nil
+	}
+
+	var refs []store.RefInput
+	add := func(n *tree_sitter.Node, kind string, name string) {
+		if name == "" {
+			return
+		}
+		sl, sc, el, ec := nodeRange1Based(n)
+		refs = append(refs, store.RefInput{Kind: kind, Name: name, SL: sl, SC: sc, EL: el, EC: ec, Lang: spec.lang})
+	}
+	callees := map[uint]bool{}
+
+	var walk func(n *tree_sitter.Node, parent *tree_sitter.Node, field string)
+	walk = func(n *tree_sitter.Node, parent *tree_sitter.Node, field string) {
+		if n == nil {
+			return
+		}
+		k := n.Kind()
+
+		if names, ok := spec.imports(n, src); ok {
+			for _, name := range names {
+				add(n, "import", name)
+			}
+			return
+		}
+		if f, ok := spec.calls[k]; ok {
+			callee := n.ChildByFieldName(f)
+			if f == "" && n.NamedChildCount() > 0 {
+				callee = n.NamedChild(0)
+			}
+			if c := calleeName(callee, spec.idents); c != nil {
+				callees[c.StartByte()] = true
+				add(c, "call", c.Utf8Text(src))
+			}
+		}
+		if _, ok := spec.idents[k]; ok && !callees[n.StartByte()] && !isDeclaredName(parent, field) {
+			if name := n.Utf8Text(src); name != "_" {
+				add(n, "ref", name)
+			}
+		}
+
+		for i := uint(0); i < n.NamedChildCount(); i++ {
+			walk(n.NamedChild(i), n, n.FieldNameForNamedChild(uint32(i)))
+		}
+	}
+	walk(root, nil, "")
+	return refs, nil
+}
+
+// calleeName follows selector/member expressions down to the called name.
+func calleeName(n *tree_sitter.Node, idents map[string]struct{}) *tree_sitter.Node {
+	for depth := 0; n != nil && depth < 8; depth++ {
+		if _, ok := idents[n.Kind()]; ok {
+			return n
+		}
+		var next *tree_sitter.Node
+		for _, f := range calleeFields {
+			if next = n.ChildByFieldName(f); next != nil {
+				break
+			}
+		}
+		if next == nil && n.NamedChildCount() > 0 {
+			switch n.Kind() {
+			case "generic_type":
+				next = n.NamedChild(0)
+			case "qualified_name":
+				next = n.NamedChild(n.NamedChildCount() - 1)
+			}
+		}
+		n = next
+	}
+	return nil
+}
+
+// isDeclaredName reports whether a child in field of parent is the name
+// being declared rather than a use.
+func isDeclaredName(parent *tree_sitter.Node, field string) bool {
+	if parent == nil {
+		return false
+	}
+	kind := parent.Kind()
+	if kind == "parameters" || kind == "formal_parameters" || field == "declarator" {
+		return true
+	}
+	if field != "name" && field != "pattern" {
+		return false
+	}
+	for _, s := range []string{"declaration", "definition", "declarator", "_spec", "parameter"} {
+		if strings.Contains(kind, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func pythonImportName(n *tree_sitter.Node, src []byte) string {
+	if n == nil {
+		return ""
+	}
+	if n.Kind() == "aliased_import" {
+		return trimNodeText(n.ChildByFieldName("name"), src)
+	}
+	return trimNodeText(n, src)
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, "\"'`")
+}
@@ -0,0 +1,106 @@
+package treesitter
+
+import (
+	"regexp"
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+var (
+	rubyTypeRe    = regexp.MustCompile(`^\s*(class|module)\s+((?:::)?[A-Z]\w*(?:::\w+)*)`)
+	rubyDefRe     = regexp.MustCompile(`^\s*(?:(?:private|protected|public|module_function)\s+)?def\s+(?:self\.)?(\w+[?!=]?|\[\]=?|[-+*/%<>=!~^&|]+)`)
+	rubyEndlessRe = regexp.MustCompile(`^\s*def\s+[\w.]+[?!]?\s*(?:\([^)]*\))?\s+=\s`)
+	rubyHeredocRe = regexp.MustCompile(`<<[~-]?['"]?([A-Z_][A-Z0-9_]*)`)
+)
+
+// rubyOpeners always open a block; rubyConditionals only when they start a
+// statement ("x = if y", not "return if y").
+var (
+	rubyOpeners      = map[string]bool{"class": true, "module": true, "def": true, "case": true, "begin": true, "for": true, "do": true}
+	rubyConditionals = map[string]bool{"if": true, "unless": true, "while": true, "until": true}
+)
+
+var rubyLang = blockLang{
+	words: func(code string) []blockWord {
+		var out []blockWord
+		loop := false
+		for _, id := range identifiers(code) {
+			switch {
+			case id.word == "end":
+				out = append(out, blockWord{end: id.pos + 3})
+			case id.word == "def" && rubyEndlessRe.MatchString(code):
+				// def x = 1 has no body.
+			case id.word == "do" && loop:
+				// "while x do" is one block.
+				loop = false
+			case rubyOpeners[id.word]:
+				if id.word == "for" {
+					loop = true
+				}
+				out = append(out, blockWord{end: id.pos + len(id.word), open: true})
+			case rubyConditionals[id.word] && rubyStatementStart(code[:id.pos]):
+				loop = id.word == "while" || id.word == "until"
+				out = append(out, blockWord{end: id.pos + len(id.word), open: true})
+			}
+		}
+		return out
+	},
+	decl: func(code string, outer []store.SymbolInput) (store.SymbolInput, bool) {
+		container := ""
+		for i := len(outer) - 1; i >= 0 && container == ""; i-- {
+			if outer[i].Kind != "function" && outer[i].Kind != "method" {
+				container = outer[i].Name
+			}
+		}
+		if m := rubyTypeRe.FindStringSubmatch(code); m != nil {
+			return store.SymbolInput{Kind: m[1], Name: strings.TrimPrefix(m[2], "::"), Container: container, Lang: "ruby", Signature: m[1] + " " + strings.TrimPrefix(m[2], "::")}, true
+		}
+		m := rubyDefRe.FindStringSubmatch(code)
+		if m == nil {
+			return store.SymbolInput{}, false
+		}
+		sym := store.SymbolInput{Kind: "function", Name: m[1], Lang: "ruby", Signature: "def " + m[1]}
+		if container != "" {
+			sym.Kind, sym.Container, sym.Signature = "method", container, container+"."+m[1]
+		}
+		return sym, true
+	},
+}
+
+// rubyStatementStart reports whether a keyword after before begins a
+// statement or an expression rather than modifying one.
+func rubyStatementStart(before string) bool {
+	before = strings.TrimRight(before, " \t")
+	return before == "" || strings.ContainsRune("=(;,|&[{!", rune(before[len(before)-1]))
+}
+
+func extractRuby(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	_ = path
+	lx := lex(src, lexSyntax{line: []string{"#"}, quotes: "\"'`", multiline: "\"'`"}, "ruby")
+	blankHeredocs(lx)
+	return extractBlocks(rubyLang, lx), lx.comms, nil
+}
+
+// blankHeredocs blanks the bodies of <<~EOS strings, which the lexer keeps
+// as code.
+func blankHeredocs(lx lexed) {
+	for i := 0; i < len(lx.code); i++ {
+		var tags []string
+		if strings.Contains(lx.code[i], "<<") {
+			for _, m := range rubyHeredocRe.FindAllStringSubmatch(lx.lines[i], -1) {
+				tags = append(tags, m[1])
+			}
+		}
+		for _, tag := range tags {
+			for i+1 < len(lx.code) {
+				i++
+				done := strings.TrimSpace(lx.lines[i]) == tag
+				lx.code[i] = strings.Repeat(" ", len(lx.code[i]))
+				if done {
+					break
+				}
+			}
+		}
+	}
+}
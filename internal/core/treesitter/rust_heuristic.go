@@ -0,0 +1,34 @@
+package treesitter
+
+import (
+	"regexp"
+
+	"otterindex/internal/index/store"
+)
+
+const rustVis = `(?:pub(?:\s*\([^)]*\))?\s+)?`
+
+var rustLang = braceLang{
+	lang: "rust",
+	syn:  lexSyntax{line: []string{"//"}, block: [2]string{"/*", "*/"}, quotes: `"`, multiline: `"`, chars: true, raw: [][2]string{{`r#"`, `"#`}, {`br#"`, `"#`}}},
+	top: []braceRule{
+		{re: regexp.MustCompile(`^\s*` + rustVis + `mod\s+(?P<name>\w+)`), kind: "module"},
+		{re: regexp.MustCompile(`^\s*` + rustVis + `(?:unsafe\s+)?(?P<kind>struct|enum|trait|union)\s+(?P<name>\w+)`)},
+		// "impl<T> Display for Wrapper<T>" is named after Wrapper, so its
+		// methods read Wrapper::fmt.
+		{re: regexp.MustCompile(`^\s*(?:unsafe\s+)?impl(?:\s*<[^{]*?>)?\s+(?:[\w:<>, ]+?\s+for\s+)?&?(?:mut\s+)?(?:\w+::)*(?P<name>\w+)`), kind: "impl"},
+		{re: regexp.MustCompile(`^\s*` + rustVis + `(?:(?:default|const|async|unsafe|extern(?:\s+"[^"]*")?)\s+)*fn\s+(?P<name>\w+)`), kind: "function"},
+		{re: regexp.MustCompile(`^\s*macro_rules!\s*(?P<name>\w+)`), kind: "macro"},
+	},
+	member: []braceRule{
+		{re: regexp.MustCompile(`^\s*` + rustVis + `(?:(?:default|const|async|unsafe|extern(?:\s+"[^"]*")?)\s+)*fn\s+(?P<name>\w+)`), kind: "method"},
+	},
+	funcSig: "fn ",
+	sep:     "::",
+}
+
+func extractRust(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	_ = path
+	syms, comms := extractBraces(rustLang, src)
+	return syms, comms, nil
+}
@@ -0,0 +1,119 @@
+package treesitter
+
+import (
+	"regexp"
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+var sqlCreateRe = regexp.MustCompile(`(?is)^create\s+(?:or\s+replace\s+)?(?:(?:temp|temporary|unlogged|global|local|unique|materialized|recursive|external|virtual|definer\s*=\s*\S+|algorithm\s*=\s*\w+|sql\s+security\s+\w+)\s+)*(table|view|function|procedure|index|trigger|type|schema|sequence|domain|extension)\s+(?:concurrently\s+)?(?:if\s+not\s+exists\s+)?([\w$."` + "`" + `\[\]]+)`)
+
+// sqlConstraints start table elements that are not columns.
+var sqlConstraints = map[string]bool{
+	"constraint": true, "primary": true, "foreign": true, "unique": true, "check": true,
+	"key": true, "index": true, "exclude": true, "like": true, "fulltext": true, "spatial": true,
+	"period": true,
+}
+
+// extractSQL reports CREATE statements as symbols of their object kind
+// ("table", "view", "function", ...) spanning the whole statement, and the
+// columns of CREATE TABLE as "column" symbols in the table. Schema
+// qualifiers become the container: public.users is users in public.
+func extractSQL(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	_ = path
+	lx := lex(src, lexSyntax{line: []string{"--"}, block: [2]string{"/*", "*/"}, quotes: `'`, multiline: `'`, dollar: true}, "sql")
+	text := strings.Join(lx.lines, "\n")
+	code := strings.Join(lx.code, "\n")
+
+	pos := positions(text)
+
+	var syms []store.SymbolInput
+	stmt := 0
+	for stmt < len(code) {
+		end := strings.IndexByte(code[stmt:], ';')
+		if end < 0 {
+			end = len(code)
+		} else {
+			end += stmt + 1
+		}
+		from := stmt + len(code[stmt:end]) - len(strings.TrimLeft(code[stmt:end], " \t\r\n"))
+		stmt = end
+
+		m := sqlCreateRe.FindStringSubmatchIndex(code[from:end])
+		if m == nil {
+			continue
+		}
+		kind := strings.ToLower(code[from+m[2] : from+m[3]])
+		full := sqlUnquote(text[from+m[4] : from+m[5]])
+		if strings.EqualFold(full, "on") {
+			continue // CREATE INDEX ON t (x) has no name
+		}
+		parent, name := splitPath(full)
+		last := from + len(strings.TrimRight(code[from:end], " \t\r\n"))
+		sl, sc := pos(from)
+		el, ec := pos(last)
+		syms = append(syms, store.SymbolInput{Kind: kind, Name: name, SL: sl, SC: sc, EL: el, EC: ec, Container: parent, Lang: "sql", Signature: kind + " " + full})
+
+		if kind != "table" {
+			continue
+		}
+		// Columns are the elements of the parenthesized list right after the
+		// name that do not start with a constraint keyword.
+		open := from + m[1]
+		open += len(code[open:last]) - len(strings.TrimLeft(code[open:last], " \t\r\n"))
+		if open >= last || code[open] != '(' {
+			continue
+		}
+		depth, elem := 0, open+1
+		for i := open; i < last && depth >= 0; i++ {
+			switch code[i] {
+			case '(':
+				depth++
+				continue
+			case ')':
+				depth--
+				if depth > 0 {
+					continue
+				}
+			case ',':
+				if depth != 1 {
+					continue
+				}
+			default:
+				continue
+			}
+			if col, ok := sqlColumn(text, code, elem, i); ok {
+				col.SL, col.SC = pos(col.SL)
+				col.EL, col.EC = pos(col.EL)
+				col.Container, col.Signature = name, name+"."+col.Name
+				syms = append(syms, col)
+			}
+			elem = i + 1
+			if depth == 0 {
+				break
+			}
+		}
+	}
+	return syms, lx.comms, nil
+}
+
+// sqlColumn reads the table element in code[from:to]; its SL and EL hold
+// the byte offsets of its first and past-the-end characters.
+func sqlColumn(text, code string, from, to int) (store.SymbolInput, bool) {
+	elem := strings.TrimSpace(code[from:to])
+	if elem == "" {
+		return store.SymbolInput{}, false
+	}
+	start := from + strings.Index(code[from:to], elem)
+	word := strings.Fields(elem)[0]
+	if sqlConstraints[strings.ToLower(word)] {
+		return store.SymbolInput{}, false
+	}
+	return store.SymbolInput{Kind: "column", Name: sqlUnquote(text[start : start+len(word)]), SL: start, EL: start + len(elem), Lang: "sql"}, true
+}
+
+// sqlUnquote strips identifier quotes: "public"."Users" -> public.Users
+func sqlUnquote(name string) string {
+	return strings.NewReplacer(`"`, "", "`", "", "[", "", "]", "").Replace(name)
+}
@@ -0,0 +1,33 @@
+package treesitter
+
+import (
+	"regexp"
+
+	"otterindex/internal/index/store"
+)
+
+const swiftModifiers = `(?:(?:@\w+(?:\([^)]*\))?|public|private|fileprivate|internal|open|final|static|class|override|mutating|nonmutating|convenience|required|indirect|nonisolated|dynamic)\s+)*`
+
+var swiftTypeRule = braceRule{re: regexp.MustCompile(`^\s*` + swiftModifiers + `(?P<kind>class|struct|enum|protocol|extension|actor)\s+(?:\w+\.)*(?P<name>\w+)`)}
+
+var swiftLang = braceLang{
+	lang: "swift",
+	syn:  lexSyntax{line: []string{"//"}, block: [2]string{"/*", "*/"}, quotes: `"`, triple: true},
+	top: []braceRule{
+		swiftTypeRule,
+		{re: regexp.MustCompile(`^\s*` + swiftModifiers + `func\s+(?P<name>[^\s(<]+)`), kind: "function"},
+	},
+	member: []braceRule{
+		swiftTypeRule,
+		{re: regexp.MustCompile(`^\s*` + swiftModifiers + `func\s+(?P<name>[^\s(<]+)`), kind: "method"},
+		{re: regexp.MustCompile(`^\s*` + swiftModifiers + `(?P<name>init)[?!]?\s*[(<]`), kind: "constructor"},
+	},
+	funcSig: "func ",
+	sep:     ".",
+}
+
+func extractSwift(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	_ = path
+	syms, comms := extractBraces(swiftLang, src)
+	return syms, comms, nil
+}
@@ -0,0 +1,147 @@
+package treesitter
+
+import (
+	"regexp"
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+var (
+	tomlTableRe = regexp.MustCompile(`^\s*(\[\[?)\s*([^\[\]]+?)\s*\]\]?`)
+	tomlKeyRe   = regexp.MustCompile(`^\s*((?:[\w-]+|"[^"]*"|'[^']*')(?:\s*\.\s*(?:[\w-]+|"[^"]*"|'[^']*'))*)\s*=`)
+)
+
+// extractTOML reports [tables] and [[arrays]] as "table" symbols and their
+// keys as "key" symbols, each inside the dotted path of its table
+// ("server.tls" holds "server.tls.cert"). A table ends before the next one.
+func extractTOML(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	_ = path
+	lx := lex(src, lexSyntax{line: []string{"#"}, quotes: `"'`, triple: true}, "toml")
+
+	var syms []store.SymbolInput
+	table, tableSym := "", -1
+	lastCode := 0
+	endAt := func(sym int, line int) {
+		if sym >= 0 {
+			syms[sym].EL, syms[sym].EC = line+1, len(strings.TrimRight(lx.lines[line], " \t\r"))+1
+		}
+	}
+
+	// Multi-line arrays, inline tables and strings continue a value.
+	depth, valueSym := 0, -1
+	for i, code := range lx.code {
+		if strings.TrimSpace(code) == "" && !inTriple(lx.triples, i+1) {
+			continue
+		}
+		if depth > 0 || inTriple(lx.triples, i+1) && !startsTriple(lx.triples, i+1) {
+			depth = max(depth+strings.Count(code, "[")+strings.Count(code, "{")-strings.Count(code, "]")-strings.Count(code, "}"), 0)
+			lastCode = i
+			endAt(valueSym, i)
+			continue
+		}
+
+		if m := tomlTableRe.FindStringSubmatchIndex(code); m != nil {
+			endAt(tableSym, lastCode)
+			table = tomlPath(lx.lines[i][m[4]:m[5]])
+			parent, name := splitPath(table)
+			syms = append(syms, store.SymbolInput{
+				Kind:      "table",
+				Name:      name,
+				SL:        i + 1,
+				SC:        m[2] + 1,
+				Container: parent,
+				Lang:      "toml",
+				Signature: table,
+			})
+			tableSym, valueSym = len(syms)-1, -1
+			endAt(tableSym, i)
+			lastCode = i
+			continue
+		}
+
+		lastCode = i
+		m := tomlKeyRe.FindStringSubmatchIndex(code)
+		if m == nil {
+			continue
+		}
+		full := tomlPath(lx.lines[i][m[2]:m[3]])
+		if table != "" {
+			full = table + "." + full
+		}
+		parent, name := splitPath(full)
+		syms = append(syms, store.SymbolInput{
+			Kind:      "key",
+			Name:      name,
+			SL:        i + 1,
+			SC:        m[2] + 1,
+			Container: parent,
+			Lang:      "toml",
+			Signature: full,
+		})
+		valueSym = len(syms) - 1
+		endAt(valueSym, i)
+		if tableSym >= 0 {
+			endAt(tableSym, i)
+		}
+		value := code[m[1]:]
+		depth = max(strings.Count(value, "[")+strings.Count(value, "{")-strings.Count(value, "]")-strings.Count(value, "}"), 0)
+	}
+	if tableSym >= 0 {
+		endAt(tableSym, lastCode)
+	}
+	return syms, lx.comms, nil
+}
+
+// tomlPath normalizes a dotted key: a . "b.c" . d -> a.b.c.d
+func tomlPath(key string) string {
+	var parts []string
+	for key != "" {
+		key = strings.TrimLeft(key, " \t.")
+		if key == "" {
+			break
+		}
+		var part string
+		if q := key[0]; q == '"' || q == '\'' {
+			end := strings.IndexByte(key[1:], q)
+			if end < 0 {
+				end = len(key) - 1
+			}
+			part, key = key[1:end+1], key[min(end+2, len(key)):]
+		} else {
+			end := strings.IndexAny(key, ". \t")
+			if end < 0 {
+				end = len(key)
+			}
+			part, key = key[:end], key[end:]
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// splitPath splits "a.b.c" into "a.b" and "c".
+func splitPath(p string) (string, string) {
+	if i := strings.LastIndexByte(p, '.'); i >= 0 {
+		return p[:i], p[i+1:]
+	}
+	return "", p
+}
+
+func inTriple(ts []span, line int) bool {
+	for _, t := range ts {
+		if t.sl <= line && line <= t.el {
+			return true
+		}
+	}
+	return false
+}
+
+func startsTriple(ts []span, line int) bool {
+	for _, t := range ts {
+		if t.sl == line {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package treesitter
+
+import (
+	"regexp"
+	"strings"
+
+	"otterindex/internal/index/store"
+)
+
+// yamlKeyRe matches a mapping key on a code line, after any "- " sequence
+// markers; quoted keys are blank in code, so the text comes from the line.
+var (
+	yamlKeyRe         = regexp.MustCompile(`^(\s*(?:-\s+)*)([^\s#\-?:,\[\]{}][^#]*?|-[^\s#][^#]*?)\s*:(?:\s|$)`)
+	yamlBlockScalarRe = regexp.MustCompile(`:\s*[|>][-+0-9]*\s*$`)
+)
+
+// extractYAML reports each mapping key as a "key" symbol named by the key,
+// inside the dotted path of its parents ("server.tls.cert"); a key's range
+// covers its nested value. Sequence items do not add a path segment.
+func extractYAML(path string, src []byte) ([]store.SymbolInput, []store.CommentInput, error) {
+	_ = path
+	lx := lex(src, lexSyntax{line: []string{"#"}, quotes: `"'`, hashWord: true}, "yaml")
+
+	type open struct {
+		sym    int
+		indent int
+		path   string
+	}
+	var syms []store.SymbolInput
+	var stack []open
+	lastCode := 0
+	closeTo := func(indent int) {
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			s := &syms[stack[len(stack)-1].sym]
+			s.EL, s.EC = lastCode+1, len(strings.TrimRight(lx.lines[lastCode], " \t\r"))+1
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	scalarIndent := -1 // indentation of the key owning a block scalar
+	for i, code := range lx.code {
+		trimmed := strings.TrimSpace(code)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(code) - len(strings.TrimLeft(code, " \t"))
+		if scalarIndent >= 0 {
+			if indent > scalarIndent {
+				lastCode = i
+				continue
+			}
+			scalarIndent = -1
+		}
+		if trimmed == "---" || trimmed == "..." || strings.HasPrefix(trimmed, "%") {
+			closeTo(0)
+			lastCode = i
+			continue
+		}
+
+		m := yamlKeyRe.FindStringSubmatchIndex(code)
+		if m == nil {
+			lastCode = i
+			continue
+		}
+		col := m[4]
+		closeTo(col)
+		lastCode = i
+
+		name := strings.TrimSpace(lx.lines[i][m[4]:m[5]])
+		if len(name) >= 2 && (name[0] == '"' || name[0] == '\'') && name[len(name)-1] == name[0] {
+			name = name[1 : len(name)-1]
+		}
+		parent := ""
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1].path
+		}
+		full := name
+		if parent != "" {
+			full = parent + "." + name
+		}
+		syms = append(syms, store.SymbolInput{
+			Kind:      "key",
+			Name:      name,
+			SL:        i + 1,
+			SC:        col + 1,
+			EL:        i + 1,
+			EC:        len(strings.TrimRight(lx.lines[i], " \t\r")) + 1,
+			Container: parent,
+			Lang:      "yaml",
+			Signature: full,
+		})
+		stack = append(stack, open{sym: len(syms) - 1, indent: col, path: full})
+		if yamlBlockScalarRe.MatchString(code) {
+			scalarIndent = col
+		}
+	}
+	closeTo(0)
+	return syms, lx.comms, nil
+}
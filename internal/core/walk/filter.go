@@ -3,21 +3,35 @@ package walk
 import (
 	"path"
 	"path/filepath"
+
+	"otterindex/internal/core/lang"
 )
 
 type Filter struct {
-	opts Options
-	ig   *ignoreMatcher
+	opts      Options
+	ig        *ignoreMatcher
+	typeGlobs []string
+	typeNot   []string
 }
 
 func NewFilter(root string, opts Options) (*Filter, error) {
+	typeGlobs, err := lang.TypeGlobs(opts.Types)
+	if err != nil {
+		return nil, err
+	}
+	typeNot, err := lang.TypeGlobs(opts.TypesNot)
+	if err != nil {
+		return nil, err
+	}
 	ig, err := loadIgnoreMatcher(root, opts.ScanAll)
 	if err != nil {
 		return nil, err
 	}
 	return &Filter{
-		opts: opts,
-		ig:   ig,
+		opts:      opts,
+		ig:        ig,
+		typeGlobs: typeGlobs,
+		typeNot:   typeNot,
 	}, nil
 }
 
@@ -50,6 +64,12 @@ func (f *Filter) ShouldInclude(rel string, isDir bool) bool {
 	if anyGlobMatch(f.opts.ExcludeGlobs, rel) {
 		return false
 	}
+	if len(f.typeGlobs) > 0 && !anyGlobMatch(f.typeGlobs, rel) {
+		return false
+	}
+	if anyGlobMatch(f.typeNot, rel) {
+		return false
+	}
 	return true
 }
 
@@ -11,7 +11,10 @@ import (
 type Options struct {
 	IncludeGlobs []string
 	ExcludeGlobs []string
-	ScanAll      bool
+	// Types and TypesNot keep or drop files by type (lang.Types).
+	Types    []string
+	TypesNot []string
+	ScanAll  bool
 }
 
 func ListFiles(root string, opts Options) ([]string, error) {
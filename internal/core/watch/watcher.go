@@ -72,6 +72,8 @@ func NewWatcherWithOptions(root string, dbPath string, opts indexer.Options, wop
 	filter, err := walk.NewFilter(rootAbs, walk.Options{
 		IncludeGlobs: opts.IncludeGlobs,
 		ExcludeGlobs: opts.ExcludeGlobs,
+		Types:        opts.Types,
+		TypesNot:     opts.TypesNot,
 		ScanAll:      opts.ScanAll,
 	})
 	if err != nil {
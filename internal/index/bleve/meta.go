@@ -15,6 +15,8 @@ type workspaceMeta struct {
 	Root      string `json:"root"`
 	CreatedAt int64  `json:"created_at"`
 	Version   int64  `json:"version"`
+	// Settings holds the values of SetSetting.
+	Settings map[string]string `json:"settings,omitempty"`
 }
 
 type fileMeta struct {
@@ -24,6 +26,8 @@ type fileMeta struct {
 	ChunkCount   int    `json:"chunk_count"`
 	SymbolCount  int    `json:"symbol_count"`
 	CommentCount int    `json:"comment_count"`
+	RefCount     int    `json:"ref_count"`
+	VectorCount  int    `json:"vector_count"`
 }
 
 func encodeJSON(v any) ([]byte, error) {
@@ -0,0 +1,200 @@
+package bleve
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/regexp"
+	"github.com/blevesearch/bleve/v2/mapping"
+	bquery "github.com/blevesearch/bleve/v2/search/query"
+
+	"otterindex/internal/core/lang"
+	"otterindex/internal/core/query/parse"
+	"otterindex/internal/index/store"
+)
+
+const (
+	codeAnalyzer  = "otidx_code"
+	codeTokenizer = "otidx_code_words"
+
+	maxSymbolFilterHits = 1000
+)
+
+// addCodeAnalyzer tokenizes like SQLite's unicode61 (runs of letters and
+// digits, lower-cased, no stop words) so both stores see the same terms.
+func addCodeAnalyzer(m *mapping.IndexMappingImpl) {
+	_ = m.AddCustomTokenizer(codeTokenizer, map[string]any{
+		"type":   regexp.Name,
+		"regexp": `[\p{L}\p{N}\p{Co}]+`,
+	})
+	_ = m.AddCustomAnalyzer(codeAnalyzer, map[string]any{
+		"type":          custom.Name,
+		"tokenizer":     codeTokenizer,
+		"token_filters": []any{lowercase.Name},
+	})
+}
+
+// compileChunkQuery compiles n for chunk documents. With subword set, terms
+// match inside tokens too, via the trigram field (or a wildcard when a term is
+// too short for trigrams).
+func (s *Store) compileChunkQuery(workspaceID string, n *parse.Node, subword bool) (bquery.Query, error) {
+	switch n.Kind {
+	case parse.Term, parse.Phrase:
+		if subword {
+			v := strings.ToLower(n.Value)
+			if utf8.RuneCountInString(v) >= 3 && s.hasField("trigram") {
+				return trigramQuery([][]string{{v}}), nil
+			}
+			return wildcardQuery("text", "*"+v+"*"), nil
+		}
+		q := bleve.NewMatchPhraseQuery(n.Value)
+		q.SetField("text")
+		return q, nil
+	case parse.Field:
+		return s.compileField(workspaceID, n)
+	case parse.Near:
+		// Bleve has no span query over our fields; require every term here
+		// and let the query layer check the line distance.
+		return s.compileChunkQuery(workspaceID, &parse.Node{Kind: parse.And, Children: n.Children}, subword)
+	case parse.Not:
+		child, err := s.compileChunkQuery(workspaceID, n.Children[0], subword)
+		if err != nil {
+			return nil, err
+		}
+		return excludeQuery(child), nil
+	case parse.And:
+		bq := bleve.NewBooleanQuery()
+		positive := 0
+		for _, c := range n.Children {
+			if c.Kind == parse.Not {
+				child, err := s.compileChunkQuery(workspaceID, c.Children[0], subword)
+				if err != nil {
+					return nil, err
+				}
+				bq.AddMustNot(child)
+				continue
+			}
+			child, err := s.compileChunkQuery(workspaceID, c, subword)
+			if err != nil {
+				return nil, err
+			}
+			bq.AddMust(child)
+			positive++
+		}
+		if positive == 0 {
+			bq.AddMust(bleve.NewMatchAllQuery())
+		}
+		return bq, nil
+	case parse.Or:
+		ors := make([]bquery.Query, 0, len(n.Children))
+		for _, c := range n.Children {
+			child, err := s.compileChunkQuery(workspaceID, c, subword)
+			if err != nil {
+				return nil, err
+			}
+			ors = append(ors, child)
+		}
+		return bleve.NewDisjunctionQuery(ors...), nil
+	default:
+		return nil, fmt.Errorf("unsupported query node")
+	}
+}
+
+func (s *Store) compileField(workspaceID string, n *parse.Node) (bquery.Query, error) {
+	switch n.Field {
+	case parse.FieldPath:
+		return wildcardPaths(parse.PathGlobs(n.Value)), nil
+	case parse.FieldLang:
+		l, ok := lang.Lookup(n.Value)
+		if !ok {
+			return nil, fmt.Errorf("unknown language %q", n.Value)
+		}
+		return regexpQuery("path", lang.PathRegexp(l)), nil
+	case parse.FieldKind:
+		return termQuery("kind", n.Value), nil
+	case parse.FieldSym:
+		return s.symbolChunksQuery(workspaceID, n.Value)
+	default:
+		return nil, fmt.Errorf("unknown field %q", n.Field)
+	}
+}
+
+// symbolChunksQuery matches chunks containing the first line of a symbol named
+// name. Symbols live in separate documents, so they are resolved up front.
+func (s *Store) symbolChunksQuery(workspaceID string, name string) (bquery.Query, error) {
+	q := bleve.NewConjunctionQuery(
+		termQuery("workspace_id", workspaceID),
+		termQuery("doc_type", docTypeSymbol),
+		termQuery("name", name),
+	)
+	req := bleve.NewSearchRequestOptions(q, maxSymbolFilterHits, 0, false)
+	req.Fields = []string{"path", "sl"}
+	res, err := s.idx.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var ors []bquery.Query
+	for _, hit := range res.Hits {
+		path, _ := hit.Fields["path"].(string)
+		line, ok := toInt(hit.Fields["sl"])
+		if path == "" || !ok {
+			continue
+		}
+		l := float64(line)
+		inclusive := true
+		ors = append(ors, bleve.NewConjunctionQuery(
+			termQuery("path", path),
+			bleve.NewNumericRangeInclusiveQuery(nil, &l, nil, &inclusive),
+			bleve.NewNumericRangeInclusiveQuery(&l, nil, &inclusive, nil),
+		))
+	}
+	if len(ors) == 0 {
+		return bleve.NewMatchNoneQuery(), nil
+	}
+	return bleve.NewDisjunctionQuery(ors...), nil
+}
+
+func wildcardPaths(globs []string) bquery.Query {
+	if len(globs) == 0 {
+		return bleve.NewMatchNoneQuery()
+	}
+	ors := make([]bquery.Query, 0, len(globs))
+	for _, g := range globs {
+		ors = append(ors, wildcardQuery("path", g))
+	}
+	if len(ors) == 1 {
+		return ors[0]
+	}
+	return bleve.NewDisjunctionQuery(ors...)
+}
+
+// pathFilter returns the queries that apply f to documents. The path field
+// is a keyword, so regexps match the whole path.
+func pathFilter(f store.PathFilter) []bquery.Query {
+	out := make([]bquery.Query, 0, len(f.Include)+len(f.Exclude))
+	for _, e := range f.Include {
+		out = append(out, regexpQuery("path", e.Expr))
+	}
+	for _, e := range f.Exclude {
+		out = append(out, excludeQuery(regexpQuery("path", e.Expr)))
+	}
+	return out
+}
+
+func regexpQuery(field string, expr string) bquery.Query {
+	q := bleve.NewRegexpQuery(expr)
+	q.SetField(field)
+	return q
+}
+
+func excludeQuery(q bquery.Query) bquery.Query {
+	bq := bleve.NewBooleanQuery()
+	bq.AddMust(bleve.NewMatchAllQuery())
+	bq.AddMustNot(q)
+	return bq
+}
@@ -0,0 +1,147 @@
+package bleve
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	bquery "github.com/blevesearch/bleve/v2/search/query"
+
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+func (s *Store) ReplaceRefsBatch(workspaceID string, path string, refs []store.RefInput) error {
+	return s.replaceOne(workspaceID, path, replaceParts{refs: refs, refsSet: true})
+}
+
+func (s *Store) FindRefs(workspaceID string, name string, opts store.RefSearchOptions) ([]model.RefItem, error) {
+	if s == nil || s.idx == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	name = strings.TrimSpace(name)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	nameQ := bleve.NewDisjunctionQuery(termQuery("name", name))
+	if !strings.ContainsAny(name, "*?") {
+		nameQ.AddQuery(bleve.NewConjunctionQuery(
+			termQuery("kind", "import"),
+			bleve.NewDisjunctionQuery(
+				wildcardQuery("name", "*/"+name),
+				wildcardQuery("name", "*."+name),
+				wildcardQuery("name", "*\\"+name),
+			),
+		))
+	}
+	conj := []bquery.Query{
+		nameQ,
+		termQuery("workspace_id", workspaceID),
+		termQuery("doc_type", docTypeRef),
+	}
+	if kind := strings.TrimSpace(opts.Kind); kind != "" {
+		conj = append(conj, termQuery("kind", kind))
+	}
+	if lang := strings.TrimSpace(opts.Lang); lang != "" {
+		conj = append(conj, termQuery("lang", lang))
+	}
+
+	req := bleve.NewSearchRequestOptions(bleve.NewConjunctionQuery(conj...), limit, 0, false)
+	req.Fields = []string{"kind", "name", "lang", "path", "sl", "sc", "el", "ec"}
+	req.SortBy([]string{"path", "sl", "sc"})
+
+	res, err := s.idx.Search(req)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.RefItem, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		f := symbolFromHit(hit.Fields)
+		out = append(out, model.RefItem{Kind: f.Kind, Name: f.Name, Lang: f.Lang, Path: f.Path, Range: f.Range})
+	}
+	return out, nil
+}
+
+func (s *Store) ListRefs(workspaceID string, path string) ([]model.RefItem, error) {
+	if s == nil || s.idx == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	path = filepath.ToSlash(path)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	q := bleve.NewConjunctionQuery(
+		termQuery("path", path),
+		termQuery("workspace_id", workspaceID),
+		termQuery("doc_type", docTypeRef),
+	)
+	req := bleve.NewSearchRequestOptions(q, 10000, 0, false)
+	req.Fields = []string{"kind", "name", "lang", "sl", "sc", "el", "ec"}
+	req.SortBy([]string{"sl", "sc"})
+
+	res, err := s.idx.Search(req)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.RefItem, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		f := symbolFromHit(hit.Fields)
+		out = append(out, model.RefItem{Kind: f.Kind, Name: f.Name, Lang: f.Lang, Path: path, Range: f.Range})
+	}
+	return out, nil
+}
+
+func indexRefs(batch *bleve.Batch, workspaceID string, path string, refs []store.RefInput) {
+	for i, r := range refs {
+		kind := strings.TrimSpace(r.Kind)
+		if kind == "" {
+			kind = "ref"
+		}
+		sc := r.SC
+		if sc <= 0 {
+			sc = 1
+		}
+		ec := r.EC
+		if ec <= 0 {
+			ec = 1
+		}
+		doc := map[string]any{
+			"doc_type":     docTypeRef,
+			"workspace_id": workspaceID,
+			"path":         path,
+			"kind":         kind,
+			"name":         r.Name,
+			"lang":         r.Lang,
+			"sl":           r.SL,
+			"sc":           sc,
+			"el":           r.EL,
+			"ec":           ec,
+		}
+		batch.Index(refDocID(workspaceID, path, i), doc)
+	}
+}
+
+func deleteRefDocs(batch *bleve.Batch, workspaceID string, path string, count int) {
+	for i := 0; i < count; i++ {
+		batch.Delete(refDocID(workspaceID, path, i))
+	}
+}
+
+func refDocID(workspaceID string, path string, idx int) string {
+	return fmt.Sprintf("ref|%s|%s|%d", workspaceID, escapePath(path), idx)
+}
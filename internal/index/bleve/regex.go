@@ -0,0 +1,128 @@
+package bleve
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/token/ngram"
+	"github.com/blevesearch/bleve/v2/analysis/token/unique"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/single"
+	"github.com/blevesearch/bleve/v2/mapping"
+	bquery "github.com/blevesearch/bleve/v2/search/query"
+
+	"otterindex/internal/core/search"
+	"otterindex/internal/index/store"
+)
+
+const (
+	trigramAnalyzer = "otidx_trigram"
+	trigramFilter   = "otidx_trigram_ngram"
+)
+
+func addTrigramAnalyzer(m *mapping.IndexMappingImpl) {
+	_ = m.AddCustomTokenFilter(trigramFilter, map[string]any{
+		"type": ngram.Name,
+		"min":  3.0,
+		"max":  3.0,
+	})
+	_ = m.AddCustomAnalyzer(trigramAnalyzer, map[string]any{
+		"type":          custom.Name,
+		"tokenizer":     single.Name,
+		"token_filters": []any{lowercase.Name, trigramFilter, unique.Name},
+	})
+}
+
+func (s *Store) SearchChunksRegex(workspaceID string, pattern string, opts store.SearchOptions) (store.SearchResult, error) {
+	if s == nil || s.idx == nil {
+		return store.SearchResult{}, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return store.SearchResult{}, fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(pattern) == "" {
+		return store.SearchResult{}, fmt.Errorf("pattern is required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	re, err := search.CompileRegex(pattern, opts.CaseInsensitive)
+	if err != nil {
+		return store.SearchResult{}, err
+	}
+
+	conj := []bquery.Query{
+		termQuery("workspace_id", workspaceID),
+		termQuery("doc_type", docTypeChunk),
+	}
+	if groups := search.RegexLiterals(pattern); groups != nil && s.hasField("trigram") {
+		conj = append(conj, trigramQuery(groups))
+	}
+	conj = append(conj, pathFilter(opts.Filter)...)
+	q := bleve.NewConjunctionQuery(conj...)
+
+	root := s.workspaceRoot(workspaceID)
+	lineCache := map[string][]string{}
+
+	pageSize := limit * 4
+	if pageSize < 200 {
+		pageSize = 200
+	}
+
+	var out []store.Chunk
+	for from := 0; ; from += pageSize {
+		req := bleve.NewSearchRequestOptions(q, pageSize, from, false)
+		req.Fields = []string{"path", "sl", "el", "kind"}
+		req.SortBy([]string{"path", "sl", "el"})
+
+		res, err := s.idx.Search(req)
+		if err != nil {
+			return store.SearchResult{}, err
+		}
+		for _, hit := range res.Hits {
+			chunk := chunkFromHit(workspaceID, hit.Fields)
+			if root == "" || chunk.Path == "" || chunk.SL <= 0 || chunk.EL <= 0 {
+				continue
+			}
+			chunk.Text = readChunkText(root, chunk.Path, chunk.SL, chunk.EL, lineCache)
+			if !search.RegexMatchesText(chunk.Text, re) {
+				continue
+			}
+			out = append(out, chunk)
+			if len(out) >= limit {
+				break
+			}
+		}
+		if len(out) >= limit || len(res.Hits) < pageSize {
+			break
+		}
+	}
+
+	return store.SearchResult{
+		Chunks:               out,
+		MatchCaseInsensitive: opts.CaseInsensitive,
+		Backend:              "bleve",
+	}, nil
+}
+
+func trigramQuery(groups [][]string) bquery.Query {
+	ors := make([]bquery.Query, 0, len(groups))
+	for _, g := range groups {
+		var ands []bquery.Query
+		for _, lit := range g {
+			for _, tri := range search.Trigrams(lit) {
+				ands = append(ands, termQuery("trigram", tri))
+			}
+		}
+		ors = append(ors, bleve.NewConjunctionQuery(ands...))
+	}
+	if len(ors) == 1 {
+		return ors[0]
+	}
+	return bleve.NewDisjunctionQuery(ors...)
+}
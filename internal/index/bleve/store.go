@@ -14,6 +14,7 @@ import (
 	bquery "github.com/blevesearch/bleve/v2/search/query"
 	"go.etcd.io/bbolt"
 
+	"otterindex/internal/core/query/parse"
 	"otterindex/internal/index/store"
 	"otterindex/internal/model"
 )
@@ -22,8 +23,10 @@ const (
 	docTypeChunk   = "chunk"
 	docTypeSymbol  = "symbol"
 	docTypeComment = "comment"
+	docTypeRef     = "ref"
+	docTypeVector  = "vector"
 
-	bleveIndexComments = false
+	bleveIndexComments = true
 )
 
 type Store struct {
@@ -162,6 +165,54 @@ func (s *Store) GetVersion(workspaceID string) (int64, error) {
 	return ver, err
 }
 
+func (s *Store) GetSetting(workspaceID string, key string) (string, error) {
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return "", fmt.Errorf("workspaceID is required")
+	}
+
+	var v string
+	err := s.meta.View(func(tx *bbolt.Tx) error {
+		raw := mustBucket(tx, bucketWorkspaces).Get([]byte(workspaceID))
+		if raw == nil {
+			return nil
+		}
+		meta := workspaceMeta{}
+		if err := decode(raw, &meta); err != nil {
+			return err
+		}
+		v = meta.Settings[key]
+		return nil
+	})
+	return v, err
+}
+
+func (s *Store) SetSetting(workspaceID string, key string, value string) error {
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return fmt.Errorf("workspaceID is required")
+	}
+	if err := s.EnsureWorkspace(workspaceID, ""); err != nil {
+		return err
+	}
+	return s.meta.Update(func(tx *bbolt.Tx) error {
+		wb := mustBucket(tx, bucketWorkspaces)
+		meta := workspaceMeta{}
+		if err := decode(wb.Get([]byte(workspaceID)), &meta); err != nil {
+			return err
+		}
+		if meta.Settings == nil {
+			meta.Settings = map[string]string{}
+		}
+		meta.Settings[key] = value
+		buf, err := encode(meta)
+		if err != nil {
+			return err
+		}
+		return wb.Put([]byte(workspaceID), buf)
+	})
+}
+
 func (s *Store) BumpVersion(workspaceID string) error {
 	workspaceID = strings.TrimSpace(workspaceID)
 	if workspaceID == "" {
@@ -473,6 +524,8 @@ func (s *Store) ReplaceFilesBatch(workspaceID string, plans []store.FilePlan) er
 				ChunkCount:   len(plan.Chunks),
 				SymbolCount:  len(plan.Syms),
 				CommentCount: 0,
+				RefCount:     len(plan.Refs),
+				VectorCount:  len(plan.Vecs),
 			}
 			if bleveIndexComments {
 				meta.CommentCount = len(plan.Comms)
@@ -489,7 +542,7 @@ func (s *Store) ReplaceFilesBatch(workspaceID string, plans []store.FilePlan) er
 	})
 }
 
-func (s *Store) SearchChunks(workspaceID string, keyword string, limit int, caseInsensitive bool) (store.SearchResult, error) {
+func (s *Store) SearchChunks(workspaceID string, keyword string, opts store.SearchOptions) (store.SearchResult, error) {
 	if s == nil || s.idx == nil {
 		return store.SearchResult{}, fmt.Errorf("store is not open")
 	}
@@ -501,70 +554,100 @@ func (s *Store) SearchChunks(workspaceID string, keyword string, limit int, case
 	if keyword == "" {
 		return store.SearchResult{}, fmt.Errorf("keyword is required")
 	}
+	limit := opts.Limit
 	if limit <= 0 {
 		limit = 50
 	}
 
-	baseQ := bleve.NewMatchQuery(keyword)
-	baseQ.SetField("text")
-	wsQ := bleve.NewTermQuery(workspaceID)
-	wsQ.SetField("workspace_id")
-	typeQ := bleve.NewTermQuery(docTypeChunk)
-	typeQ.SetField("doc_type")
-	q := bleve.NewConjunctionQuery(baseQ, wsQ, typeQ)
+	node, err := parse.Parse(keyword)
+	if err != nil {
+		return store.SearchResult{}, fmt.Errorf("invalid query: %w", err)
+	}
+	baseQ, err := s.compileChunkQuery(workspaceID, node, opts.Subword)
+	if err != nil {
+		return store.SearchResult{}, err
+	}
+	q := bleve.NewConjunctionQuery(
+		baseQ,
+		termQuery("workspace_id", workspaceID),
+		termQuery("doc_type", docTypeChunk),
+	)
+	for _, f := range pathFilter(opts.Filter) {
+		q.AddQuery(f)
+	}
 
+	ranked := opts.Sort != store.SortPath
 	req := bleve.NewSearchRequestOptions(q, limit, 0, false)
 	req.Fields = []string{"path", "sl", "el", "kind"}
-	req.SortBy([]string{"path", "sl", "el"})
+	if ranked {
+		req.SortBy([]string{"-_score", "path", "sl", "el"})
+	} else {
+		req.SortBy([]string{"path", "sl", "el"})
+	}
 
 	res, err := s.idx.Search(req)
 	if err != nil {
 		return store.SearchResult{}, err
 	}
 
-	root := ""
-	if ws, err := s.GetWorkspace(workspaceID); err == nil {
-		root = strings.TrimSpace(ws.Root)
-		if root != "" && !filepath.IsAbs(root) {
-			if abs, err := filepath.Abs(root); err == nil {
-				root = abs
-			}
-		}
-	}
+	root := s.workspaceRoot(workspaceID)
 	lineCache := map[string][]string{}
 
 	out := make([]store.Chunk, 0, len(res.Hits))
 	for _, hit := range res.Hits {
-		chunk := store.Chunk{
-			WorkspaceID: workspaceID,
-		}
-		if v, ok := hit.Fields["path"].(string); ok {
-			chunk.Path = v
-		}
-		if v, ok := toInt(hit.Fields["sl"]); ok {
-			chunk.SL = v
-		}
-		if v, ok := toInt(hit.Fields["el"]); ok {
-			chunk.EL = v
-		}
-		if v, ok := hit.Fields["kind"].(string); ok {
-			chunk.Kind = v
-		}
-		if chunk.Kind == "" {
-			chunk.Kind = "chunk"
-		}
+		chunk := chunkFromHit(workspaceID, hit.Fields)
 		if root != "" && chunk.Path != "" && chunk.SL > 0 && chunk.EL > 0 {
 			chunk.Text = readChunkText(root, chunk.Path, chunk.SL, chunk.EL, lineCache)
 		}
+		if ranked {
+			chunk.Score = hit.Score
+		}
 		out = append(out, chunk)
 	}
 	return store.SearchResult{
 		Chunks:               out,
 		MatchCaseInsensitive: true,
 		Backend:              "bleve",
+		Ranked:               ranked,
 	}, nil
 }
 
+func (s *Store) workspaceRoot(workspaceID string) string {
+	ws, err := s.GetWorkspace(workspaceID)
+	if err != nil {
+		return ""
+	}
+	root := strings.TrimSpace(ws.Root)
+	if root != "" && !filepath.IsAbs(root) {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+	}
+	return root
+}
+
+func chunkFromHit(workspaceID string, fields map[string]any) store.Chunk {
+	chunk := store.Chunk{
+		WorkspaceID: workspaceID,
+	}
+	if v, ok := fields["path"].(string); ok {
+		chunk.Path = v
+	}
+	if v, ok := toInt(fields["sl"]); ok {
+		chunk.SL = v
+	}
+	if v, ok := toInt(fields["el"]); ok {
+		chunk.EL = v
+	}
+	if v, ok := fields["kind"].(string); ok {
+		chunk.Kind = v
+	}
+	if chunk.Kind == "" {
+		chunk.Kind = "chunk"
+	}
+	return chunk
+}
+
 func (s *Store) FindMinEnclosingSymbols(workspaceID string, path string, line int) ([]model.SymbolItem, error) {
 	if s == nil || s.idx == nil {
 		return nil, fmt.Errorf("store is not open")
@@ -581,6 +664,104 @@ func (s *Store) FindMinEnclosingSymbols(workspaceID string, path string, line in
 		return nil, fmt.Errorf("line must be >= 1")
 	}
 
+	syms, err := s.pathSymbols(workspaceID, path)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]model.SymbolItem, 0, len(syms))
+	for _, item := range syms {
+		if item.Range.SL > 0 && item.Range.EL > 0 && line >= item.Range.SL && line <= item.Range.EL {
+			items = append(items, item)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		ri := items[i].Range
+		rj := items[j].Range
+		deltaI := ri.EL - ri.SL
+		deltaJ := rj.EL - rj.SL
+		if deltaI == deltaJ {
+			if ri.SL == rj.SL {
+				return ri.EL < rj.EL
+			}
+			return ri.SL < rj.SL
+		}
+		return deltaI < deltaJ
+	})
+
+	return items, nil
+}
+
+func (s *Store) ListSymbols(workspaceID string, path string) ([]model.SymbolItem, error) {
+	if s == nil || s.idx == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	path = filepath.ToSlash(path)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	items, err := s.pathSymbols(workspaceID, path)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(items, func(i, j int) bool {
+		ri := items[i].Range
+		rj := items[j].Range
+		if ri.SL != rj.SL {
+			return ri.SL < rj.SL
+		}
+		if ri.SC != rj.SC {
+			return ri.SC < rj.SC
+		}
+		return ri.EL > rj.EL
+	})
+	return items, nil
+}
+
+func (s *Store) ListSymbolsByPaths(workspaceID string, paths []string) (map[string][]model.SymbolItem, error) {
+	if s == nil || s.idx == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+
+	out := map[string][]model.SymbolItem{}
+	const batch = 200
+	for start := 0; start < len(paths); start += batch {
+		pathQ := bleve.NewDisjunctionQuery()
+		for _, p := range paths[start:min(start+batch, len(paths))] {
+			pathQ.AddQuery(termQuery("path", filepath.ToSlash(p)))
+		}
+		q := bleve.NewConjunctionQuery(pathQ, termQuery("workspace_id", workspaceID), termQuery("doc_type", docTypeSymbol))
+		const page = 5000
+		for from := 0; ; from += page {
+			req := bleve.NewSearchRequestOptions(q, page, from, false)
+			req.Fields = []string{"kind", "name", "container", "lang", "signature", "path", "sl", "sc", "el", "ec"}
+			req.SortBy([]string{"path", "sl", "sc", "-el"})
+			res, err := s.idx.Search(req)
+			if err != nil {
+				return nil, err
+			}
+			for _, hit := range res.Hits {
+				sym := symbolFromHit(hit.Fields)
+				out[sym.Path] = append(out[sym.Path], sym)
+			}
+			if len(res.Hits) < page {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) pathSymbols(workspaceID string, path string) ([]model.SymbolItem, error) {
 	pathQ := bleve.NewTermQuery(path)
 	pathQ.SetField("path")
 	wsQ := bleve.NewTermQuery(workspaceID)
@@ -599,57 +780,157 @@ func (s *Store) FindMinEnclosingSymbols(workspaceID string, path string, line in
 
 	items := make([]model.SymbolItem, 0, len(res.Hits))
 	for _, hit := range res.Hits {
-		var item model.SymbolItem
-		if v, ok := hit.Fields["kind"].(string); ok {
-			item.Kind = v
-		}
-		if v, ok := hit.Fields["name"].(string); ok {
-			item.Name = v
-		}
-		if v, ok := hit.Fields["container"].(string); ok {
-			item.Container = v
-		}
-		if v, ok := hit.Fields["lang"].(string); ok {
-			item.Lang = v
-		}
-		if v, ok := hit.Fields["signature"].(string); ok {
-			item.Signature = v
-		}
-		if v, ok := hit.Fields["path"].(string); ok {
-			item.Path = v
-		}
-		if v, ok := toInt(hit.Fields["sl"]); ok {
-			item.Range.SL = v
-		}
-		if v, ok := toInt(hit.Fields["sc"]); ok {
-			item.Range.SC = v
-		}
-		if v, ok := toInt(hit.Fields["el"]); ok {
-			item.Range.EL = v
-		}
-		if v, ok := toInt(hit.Fields["ec"]); ok {
-			item.Range.EC = v
+		items = append(items, symbolFromHit(hit.Fields))
+	}
+	return items, nil
+}
+
+func symbolFromHit(fields map[string]any) model.SymbolItem {
+	var item model.SymbolItem
+	if v, ok := fields["kind"].(string); ok {
+		item.Kind = v
+	}
+	if v, ok := fields["name"].(string); ok {
+		item.Name = v
+	}
+	if v, ok := fields["container"].(string); ok {
+		item.Container = v
+	}
+	if v, ok := fields["lang"].(string); ok {
+		item.Lang = v
+	}
+	if v, ok := fields["signature"].(string); ok {
+		item.Signature = v
+	}
+	if v, ok := fields["path"].(string); ok {
+		item.Path = v
+	}
+	if v, ok := toInt(fields["sl"]); ok {
+		item.Range.SL = v
+	}
+	if v, ok := toInt(fields["sc"]); ok {
+		item.Range.SC = v
+	}
+	if v, ok := toInt(fields["el"]); ok {
+		item.Range.EL = v
+	}
+	if v, ok := toInt(fields["ec"]); ok {
+		item.Range.EC = v
+	}
+	return item
+}
+
+func (s *Store) ListComments(workspaceID string, path string) ([]model.CommentItem, error) {
+	if s == nil || s.idx == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	path = filepath.ToSlash(path)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	q := bleve.NewConjunctionQuery(
+		termQuery("path", path),
+		termQuery("workspace_id", workspaceID),
+		termQuery("doc_type", docTypeComment),
+	)
+	req := bleve.NewSearchRequestOptions(q, 10000, 0, false)
+	req.Fields = []string{"kind", "lang", "sl", "sc", "el", "ec"}
+	req.SortBy([]string{"sl", "sc"})
+
+	res, err := s.idx.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Comment text is indexed but not stored; read it back from the file.
+	root := s.workspaceRoot(workspaceID)
+	lineCache := map[string][]string{}
+
+	out := make([]model.CommentItem, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		sym := symbolFromHit(hit.Fields)
+		c := model.CommentItem{Kind: sym.Kind, Lang: sym.Lang, Path: path, Range: sym.Range}
+		if root != "" {
+			c.Text = commentText(readChunkText(root, path, c.Range.SL, c.Range.EL, lineCache), c.Range)
 		}
-		if item.Range.SL > 0 && item.Range.EL > 0 && line >= item.Range.SL && line <= item.Range.EL {
-			items = append(items, item)
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (s *Store) SearchComments(workspaceID string, terms []string, opts store.CommentSearchOptions) ([]model.CommentItem, error) {
+	if s == nil || s.idx == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("terms are required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	conj := []bquery.Query{
+		termQuery("workspace_id", workspaceID),
+		termQuery("doc_type", docTypeComment),
+	}
+	for _, t := range terms {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if strings.ContainsAny(t, " \t") {
+			q := bleve.NewMatchPhraseQuery(t)
+			q.SetField("text")
+			conj = append(conj, q)
+			continue
 		}
+		conj = append(conj, wildcardQuery("text", "*"+t+"*"))
 	}
+	req := bleve.NewSearchRequestOptions(bleve.NewConjunctionQuery(conj...), limit, 0, false)
+	req.Fields = []string{"path", "kind", "lang", "sl", "sc", "el", "ec"}
+	req.SortBy([]string{"path", "sl", "sc"})
 
-	sort.Slice(items, func(i, j int) bool {
-		ri := items[i].Range
-		rj := items[j].Range
-		deltaI := ri.EL - ri.SL
-		deltaJ := rj.EL - rj.SL
-		if deltaI == deltaJ {
-			if ri.SL == rj.SL {
-				return ri.EL < rj.EL
-			}
-			return ri.SL < rj.SL
+	res, err := s.idx.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	root := s.workspaceRoot(workspaceID)
+	lineCache := map[string][]string{}
+
+	out := make([]model.CommentItem, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		sym := symbolFromHit(hit.Fields)
+		c := model.CommentItem{Kind: sym.Kind, Lang: sym.Lang, Path: sym.Path, Range: sym.Range}
+		if root != "" {
+			c.Text = commentText(readChunkText(root, c.Path, c.Range.SL, c.Range.EL, lineCache), c.Range)
 		}
-		return deltaI < deltaJ
-	})
+		out = append(out, c)
+	}
+	return out, nil
+}
 
-	return items, nil
+// commentText cuts the comment's columns out of its full lines.
+func commentText(lines string, r model.Range) string {
+	if lines == "" {
+		return ""
+	}
+	parts := strings.Split(lines, "\n")
+	last := len(parts) - 1
+	if r.EC > 1 && r.EC-1 <= len(parts[last]) {
+		parts[last] = parts[last][:r.EC-1]
+	}
+	if r.SC > 1 && r.SC-1 <= len(parts[0]) {
+		parts[0] = parts[0][r.SC-1:]
+	}
+	return strings.Join(parts, "\n")
 }
 
 func (s *Store) CountChunks(workspaceID string) (int, error) {
@@ -731,6 +1012,16 @@ func (s *Store) replaceOne(workspaceID string, path string, parts replaceParts)
 			meta.CommentCount = 0
 		}
 	}
+	if len(parts.refs) > 0 || parts.refsSet {
+		deleteRefDocs(batch, workspaceID, path, old.RefCount)
+		meta.RefCount = len(parts.refs)
+		indexRefs(batch, workspaceID, path, parts.refs)
+	}
+	if len(parts.vecs) > 0 || parts.vecsSet {
+		deleteVectorDocs(batch, workspaceID, path, old.VectorCount)
+		meta.VectorCount = len(parts.vecs)
+		indexVectors(batch, workspaceID, path, parts.vecs)
+	}
 	if err := s.idx.Batch(batch); err != nil {
 		return err
 	}
@@ -749,14 +1040,20 @@ type replaceParts struct {
 	chunks    []store.ChunkInput
 	syms      []store.SymbolInput
 	comms     []store.CommentInput
+	refs      []store.RefInput
+	vecs      []store.VectorInput
 	chunksSet bool
 	symsSet   bool
 	commsSet  bool
+	refsSet   bool
+	vecsSet   bool
 }
 
 func buildMapping() mapping.IndexMapping {
 	idxMapping := bleve.NewIndexMapping()
 	idxMapping.DefaultAnalyzer = "standard"
+	addTrigramAnalyzer(idxMapping)
+	addCodeAnalyzer(idxMapping)
 
 	doc := bleve.NewDocumentMapping()
 	doc.Dynamic = false
@@ -768,7 +1065,7 @@ func buildMapping() mapping.IndexMapping {
 	keyword.DocValues = true
 
 	text := bleve.NewTextFieldMapping()
-	text.Analyzer = "standard"
+	text.Analyzer = codeAnalyzer
 	text.Store = false
 	text.Index = true
 
@@ -776,6 +1073,20 @@ func buildMapping() mapping.IndexMapping {
 	storedText.Store = true
 	storedText.Index = false
 
+	trigram := bleve.NewTextFieldMapping()
+	trigram.Analyzer = trigramAnalyzer
+	trigram.Store = false
+	trigram.Index = true
+	trigram.IncludeTermVectors = false
+	trigram.IncludeInAll = false
+
+	lcKeyword := bleve.NewTextFieldMapping()
+	lcKeyword.Analyzer = "keyword"
+	lcKeyword.Store = false
+	lcKeyword.Index = true
+	lcKeyword.IncludeTermVectors = false
+	lcKeyword.IncludeInAll = false
+
 	num := bleve.NewNumericFieldMapping()
 	num.Store = true
 	num.Index = true
@@ -786,10 +1097,14 @@ func buildMapping() mapping.IndexMapping {
 	doc.AddFieldMappingsAt("path", keyword)
 	doc.AddFieldMappingsAt("kind", keyword)
 	doc.AddFieldMappingsAt("text", text)
-	doc.AddFieldMappingsAt("name", storedText)
+	doc.AddFieldMappingsAt("trigram", trigram)
+	doc.AddFieldMappingsAt("name", keyword)
 	doc.AddFieldMappingsAt("container", storedText)
+	doc.AddFieldMappingsAt("sym_lc", lcKeyword)
 	doc.AddFieldMappingsAt("lang", keyword)
 	doc.AddFieldMappingsAt("signature", storedText)
+	doc.AddFieldMappingsAt("model", keyword)
+	doc.AddFieldMappingsAt("vec", storedText)
 	doc.AddFieldMappingsAt("sl", num)
 	doc.AddFieldMappingsAt("sc", num)
 	doc.AddFieldMappingsAt("el", num)
@@ -805,10 +1120,23 @@ func termQuery(field string, value string) bquery.Query {
 	return q
 }
 
+// hasField reports whether the index mapping declares field; indexes built by
+// older versions may lack newer fields.
+func (s *Store) hasField(name string) bool {
+	im, ok := s.idx.Mapping().(*mapping.IndexMappingImpl)
+	if !ok || im.DefaultMapping == nil {
+		return false
+	}
+	_, ok = im.DefaultMapping.Properties[name]
+	return ok
+}
+
 func indexDocs(batch *bleve.Batch, workspaceID string, plan store.FilePlan) {
 	indexChunks(batch, workspaceID, plan.Path, plan.Chunks)
 	indexSymbols(batch, workspaceID, plan.Path, plan.Syms)
 	indexComments(batch, workspaceID, plan.Path, plan.Comms)
+	indexRefs(batch, workspaceID, plan.Path, plan.Refs)
+	indexVectors(batch, workspaceID, plan.Path, plan.Vecs)
 }
 
 func indexChunks(batch *bleve.Batch, workspaceID string, path string, chunks []store.ChunkInput) {
@@ -825,6 +1153,7 @@ func indexChunks(batch *bleve.Batch, workspaceID string, path string, chunks []s
 			"el":           c.EL,
 			"kind":         kind,
 			"text":         c.Text,
+			"trigram":      c.Text,
 		}
 		batch.Index(chunkDocID(workspaceID, path, i), doc)
 	}
@@ -853,6 +1182,7 @@ func indexSymbols(batch *bleve.Batch, workspaceID string, path string, syms []st
 			"container":    sym.Container,
 			"lang":         sym.Lang,
 			"signature":    sym.Signature,
+			"sym_lc":       symbolSearchKeys(sym),
 			"sl":           sym.SL,
 			"sc":           sc,
 			"el":           sym.EL,
@@ -887,6 +1217,8 @@ func deleteDocs(batch *bleve.Batch, workspaceID string, path string, meta fileMe
 	deleteChunkDocs(batch, workspaceID, path, meta.ChunkCount)
 	deleteSymbolDocs(batch, workspaceID, path, meta.SymbolCount)
 	deleteCommentDocs(batch, workspaceID, path, meta.CommentCount)
+	deleteRefDocs(batch, workspaceID, path, meta.RefCount)
+	deleteVectorDocs(batch, workspaceID, path, meta.VectorCount)
 }
 
 func deleteChunkDocs(batch *bleve.Batch, workspaceID string, path string, count int) {
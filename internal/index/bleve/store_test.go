@@ -58,7 +58,7 @@ func TestBleveStore_ReplaceFilesBatch(t *testing.T) {
 		t.Fatalf("unexpected meta: ok=%v meta=%+v", ok, meta)
 	}
 
-	res, err := st.SearchChunks(workspaceID, "hello", 10, false)
+	res, err := st.SearchChunks(workspaceID, "hello", store.SearchOptions{Limit: 10})
 	if err != nil {
 		t.Fatalf("search: %v", err)
 	}
@@ -73,6 +73,13 @@ func TestBleveStore_ReplaceFilesBatch(t *testing.T) {
 	if len(syms) == 0 {
 		t.Fatalf("expected symbols")
 	}
+	byPath, err := st.ListSymbolsByPaths(workspaceID, []string{"a.go", "b.go"})
+	if err != nil {
+		t.Fatalf("symbols by path: %v", err)
+	}
+	if len(byPath["a.go"]) != 1 || len(byPath["b.go"]) != 0 {
+		t.Fatalf("unexpected symbols by path: %+v", byPath)
+	}
 
 	if err := st.ReplaceFilesBatch(workspaceID, []store.FilePlan{{Path: "a.go", Delete: true}}); err != nil {
 		t.Fatalf("delete: %v", err)
@@ -0,0 +1,123 @@
+package bleve
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	bquery "github.com/blevesearch/bleve/v2/search/query"
+
+	"otterindex/internal/index/store"
+	"otterindex/internal/model"
+)
+
+func (s *Store) SearchSymbols(workspaceID string, pattern string, opts store.SymbolSearchOptions) ([]model.SymbolItem, error) {
+	if s == nil || s.idx == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	pattern = strings.TrimSpace(pattern)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	filters := []bquery.Query{
+		termQuery("workspace_id", workspaceID),
+		termQuery("doc_type", docTypeSymbol),
+	}
+	if kind := strings.TrimSpace(opts.Kind); kind != "" {
+		filters = append(filters, termQuery("kind", kind))
+	}
+	if lang := strings.TrimSpace(opts.Lang); lang != "" {
+		filters = append(filters, termQuery("lang", lang))
+	}
+
+	// Indexes without sym_lc get every symbol and leave matching to the caller.
+	var stages []bquery.Query
+	if s.hasField("sym_lc") {
+		stages = append(stages,
+			termQuery("sym_lc", strings.ToLower(pattern)),
+			wildcardQuery("sym_lc", subsequenceWildcard(pattern)),
+		)
+	} else {
+		stages = append(stages, bleve.NewMatchAllQuery())
+	}
+
+	// With opts.Score every candidate is read and ranked before the limit.
+	pageSize := limit
+	if opts.Score != nil {
+		pageSize = 5000
+	}
+	seen := map[string]bool{}
+	var out []model.SymbolItem
+	for _, stage := range stages {
+		q := bleve.NewConjunctionQuery(append([]bquery.Query{stage}, filters...)...)
+		for from := 0; ; from += pageSize {
+			req := bleve.NewSearchRequestOptions(q, pageSize, from, false)
+			req.Fields = []string{"kind", "name", "container", "lang", "signature", "path", "sl", "sc", "el", "ec"}
+			req.SortBy([]string{"path", "sl"})
+			res, err := s.idx.Search(req)
+			if err != nil {
+				return nil, err
+			}
+			for _, hit := range res.Hits {
+				if seen[hit.ID] {
+					continue
+				}
+				seen[hit.ID] = true
+				out = append(out, symbolFromHit(hit.Fields))
+				if opts.Score == nil && len(out) >= limit {
+					return out, nil
+				}
+			}
+			if opts.Score == nil || len(res.Hits) < pageSize {
+				break
+			}
+		}
+	}
+	if opts.Score != nil {
+		return store.BestSymbols(out, opts.Score, limit), nil
+	}
+	return out, nil
+}
+
+// symbolSearchKeys are the lower-cased values SearchSymbols prefilters on.
+func symbolSearchKeys(sym store.SymbolInput) []string {
+	name := strings.ToLower(sym.Name)
+	keys := []string{name}
+	if c := strings.TrimSpace(sym.Container); c != "" {
+		keys = append(keys, strings.ToLower(c)+"."+name)
+	}
+	if sig := strings.TrimSpace(sym.Signature); sig != "" {
+		keys = append(keys, strings.ToLower(sig))
+	}
+	return keys
+}
+
+// subsequenceWildcard turns "NRC" into "*n*r*c*". Bleve wildcards cannot
+// escape '*' or '?', so those are dropped.
+func subsequenceWildcard(pattern string) string {
+	var b strings.Builder
+	b.WriteByte('*')
+	for _, r := range strings.ToLower(pattern) {
+		if r == '*' || r == '?' {
+			continue
+		}
+		b.WriteRune(r)
+		b.WriteByte('*')
+	}
+	return b.String()
+}
+
+func wildcardQuery(field string, pattern string) bquery.Query {
+	q := bleve.NewWildcardQuery(pattern)
+	q.SetField(field)
+	return q
+}
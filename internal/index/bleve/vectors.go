@@ -0,0 +1,156 @@
+package bleve
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+
+	"otterindex/internal/core/embed"
+	"otterindex/internal/index/store"
+)
+
+func (s *Store) ReplaceVectorsBatch(workspaceID string, path string, vecs []store.VectorInput) error {
+	return s.replaceOne(workspaceID, path, replaceParts{vecs: vecs, vecsSet: true})
+}
+
+// SearchVectors scores every vector of the model. Vectors are stored, not
+// indexed: native bleve vector fields need faiss, which the build does not
+// link.
+func (s *Store) SearchVectors(workspaceID string, vec []float32, opts store.VectorSearchOptions) ([]store.Chunk, error) {
+	if s == nil || s.idx == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if len(vec) == 0 {
+		return nil, fmt.Errorf("vector is required")
+	}
+	if !s.hasField("vec") {
+		return nil, nil
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	q := bleve.NewConjunctionQuery(
+		termQuery("workspace_id", workspaceID),
+		termQuery("doc_type", docTypeVector),
+		termQuery("model", opts.Model),
+	)
+	for _, f := range pathFilter(opts.Filter) {
+		q.AddQuery(f)
+	}
+
+	const pageSize = 1000
+	var out []store.Chunk
+	for from := 0; ; from += pageSize {
+		req := bleve.NewSearchRequestOptions(q, pageSize, from, false)
+		req.Fields = []string{"path", "sl", "el", "kind", "name", "vec"}
+		req.SortBy([]string{"_id"})
+
+		res, err := s.idx.Search(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range res.Hits {
+			raw, _ := hit.Fields["vec"].(string)
+			b, err := base64.StdEncoding.DecodeString(raw)
+			if err != nil {
+				return nil, err
+			}
+			v, err := embed.Decode(b)
+			if err != nil {
+				return nil, err
+			}
+			chunk := chunkFromHit(workspaceID, hit.Fields)
+			if title, ok := hit.Fields["name"].(string); ok {
+				chunk.Title = title
+			}
+			chunk.Score = embed.Cosine(vec, v)
+			out = append(out, chunk)
+		}
+		if len(res.Hits) < pageSize {
+			break
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].SL < out[j].SL
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *Store) VectorModel(workspaceID string) (string, error) {
+	if s == nil || s.idx == nil {
+		return "", fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return "", fmt.Errorf("workspaceID is required")
+	}
+	if !s.hasField("model") {
+		return "", nil
+	}
+
+	q := bleve.NewConjunctionQuery(
+		termQuery("workspace_id", workspaceID),
+		termQuery("doc_type", docTypeVector),
+	)
+	req := bleve.NewSearchRequestOptions(q, 1, 0, false)
+	req.Fields = []string{"model"}
+	res, err := s.idx.Search(req)
+	if err != nil {
+		return "", err
+	}
+	if len(res.Hits) == 0 {
+		return "", nil
+	}
+	name, _ := res.Hits[0].Fields["model"].(string)
+	return name, nil
+}
+
+func indexVectors(batch *bleve.Batch, workspaceID string, path string, vecs []store.VectorInput) {
+	for i, v := range vecs {
+		kind := strings.TrimSpace(v.Kind)
+		if kind == "" {
+			kind = "chunk"
+		}
+		doc := map[string]any{
+			"doc_type":     docTypeVector,
+			"workspace_id": workspaceID,
+			"path":         path,
+			"kind":         kind,
+			"name":         v.Title,
+			"model":        v.Model,
+			"sl":           v.SL,
+			"el":           v.EL,
+			"vec":          base64.StdEncoding.EncodeToString(embed.Encode(v.Vec)),
+		}
+		batch.Index(vectorDocID(workspaceID, path, i), doc)
+	}
+}
+
+func deleteVectorDocs(batch *bleve.Batch, workspaceID string, path string, count int) {
+	for i := 0; i < count; i++ {
+		batch.Delete(vectorDocID(workspaceID, path, i))
+	}
+}
+
+func vectorDocID(workspaceID string, path string, idx int) string {
+	return fmt.Sprintf("vec|%s|%s|%d", workspaceID, escapePath(path), idx)
+}
@@ -0,0 +1,271 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"otterindex/internal/core/lang"
+	"otterindex/internal/core/query/parse"
+	"otterindex/internal/index/store"
+)
+
+// chunkQuery is a parsed query compiled against the chunks table (alias c).
+// When match is set the query joins chunks_fts and uses it as the main MATCH;
+// where holds the remaining conditions.
+// nearTokensPerLine converts NEAR/n line distances to FTS5 token distances.
+const nearTokensPerLine = 64
+
+type chunkQuery struct {
+	match string
+	where string
+	args  []any
+}
+
+// With subword set, terms match inside tokens too, so FTS5 (which matches
+// whole tokens) is bypassed for the trigram table or LIKE.
+func compileChunkQuery(n *parse.Node, hasFTS bool, hasTrigram bool, caseInsensitive bool, subword bool) (chunkQuery, error) {
+	c := sqlCompiler{hasFTS: hasFTS && !subword, hasTrigram: hasTrigram, caseInsensitive: caseInsensitive, subword: subword}
+	if !c.hasFTS {
+		where, args, err := c.compile(n)
+		return chunkQuery{where: where, args: args}, err
+	}
+	if ftsable(n) {
+		return chunkQuery{match: ftsMatch(n)}, nil
+	}
+
+	conj := []*parse.Node{n}
+	if n.Kind == parse.And {
+		conj = n.Children
+	}
+	var text []*parse.Node
+	var rest []*parse.Node
+	for _, child := range conj {
+		if ftsable(child) || (child.Kind == parse.Not && ftsable(child.Children[0])) {
+			text = append(text, child)
+			continue
+		}
+		rest = append(rest, child)
+	}
+
+	var out chunkQuery
+	textNode := &parse.Node{Kind: parse.And, Children: text}
+	if len(text) == 1 {
+		textNode = text[0]
+	}
+	if len(text) > 0 && ftsable(textNode) {
+		out.match = ftsMatch(textNode)
+	} else {
+		rest = conj
+	}
+
+	var parts []string
+	for _, child := range rest {
+		where, args, err := c.compile(child)
+		if err != nil {
+			return chunkQuery{}, err
+		}
+		parts = append(parts, where)
+		out.args = append(out.args, args...)
+	}
+	out.where = strings.Join(parts, " AND ")
+	return out, nil
+}
+
+type sqlCompiler struct {
+	hasFTS          bool
+	hasTrigram      bool
+	caseInsensitive bool
+	subword         bool
+}
+
+func (c sqlCompiler) compile(n *parse.Node) (string, []any, error) {
+	switch n.Kind {
+	case parse.Term, parse.Phrase:
+		if c.hasFTS {
+			return `c.id IN (SELECT rowid FROM chunks_fts WHERE chunks_fts MATCH ?)`, []any{ftsMatch(n)}, nil
+		}
+		if c.subword && c.hasTrigram && utf8.RuneCountInString(n.Value) >= 3 {
+			// The trigram tokenizer only indexes substrings of 3+ characters.
+			return `c.id IN (SELECT rowid FROM chunks_tri WHERE chunks_tri MATCH ?)`, []any{ftsExpr(n)}, nil
+		}
+		if c.caseInsensitive {
+			return `LOWER(c.text) LIKE LOWER(?) ESCAPE '\'`, []any{"%" + escapeLike(n.Value) + "%"}, nil
+		}
+		return `c.text LIKE ? ESCAPE '\'`, []any{"%" + escapeLike(n.Value) + "%"}, nil
+	case parse.Field:
+		return compileField(n)
+	case parse.Near:
+		if c.hasFTS {
+			return `c.id IN (SELECT rowid FROM chunks_fts WHERE chunks_fts MATCH ?)`, []any{ftsMatch(n)}, nil
+		}
+		// Line distances are checked by the query layer; here all terms must occur.
+		return c.compile(&parse.Node{Kind: parse.And, Children: n.Children})
+	case parse.Not:
+		where, args, err := c.compile(n.Children[0])
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + where + ")", args, nil
+	case parse.And, parse.Or:
+		if c.hasFTS && ftsable(n) {
+			return `c.id IN (SELECT rowid FROM chunks_fts WHERE chunks_fts MATCH ?)`, []any{ftsMatch(n)}, nil
+		}
+		sep := " AND "
+		if n.Kind == parse.Or {
+			sep = " OR "
+		}
+		parts := make([]string, 0, len(n.Children))
+		var args []any
+		for _, child := range n.Children {
+			where, a, err := c.compile(child)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, where)
+			args = append(args, a...)
+		}
+		return "(" + strings.Join(parts, sep) + ")", args, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported query node")
+	}
+}
+
+func compileField(n *parse.Node) (string, []any, error) {
+	switch n.Field {
+	case parse.FieldPath:
+		return globConditions(parse.PathGlobs(n.Value))
+	case parse.FieldLang:
+		l, ok := lang.Lookup(n.Value)
+		if !ok {
+			return "", nil, fmt.Errorf("unknown language %q", n.Value)
+		}
+		return `c.path REGEXP ?`, []any{store.AnchorPathExpr(lang.PathRegexp(l))}, nil
+	case parse.FieldKind:
+		return `c.kind = ?`, []any{n.Value}, nil
+	case parse.FieldSym:
+		return `EXISTS (SELECT 1 FROM symbols s
+		         WHERE s.workspace_id = c.workspace_id AND s.path = c.path
+		           AND s.name = ? AND s.sl BETWEEN c.sl AND c.el)`, []any{n.Value}, nil
+	default:
+		return "", nil, fmt.Errorf("unknown field %q", n.Field)
+	}
+}
+
+func globConditions(globs []string) (string, []any, error) {
+	if len(globs) == 0 {
+		return "0", nil, nil
+	}
+	parts := make([]string, 0, len(globs))
+	args := make([]any, 0, len(globs))
+	for _, g := range globs {
+		parts = append(parts, `c.path GLOB ?`)
+		args = append(args, escapeGlob(g))
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args, nil
+}
+
+// pathFilter returns the conditions (each prefixed with " AND ") that apply
+// f to the table aliased c.
+func pathFilter(f store.PathFilter) (string, []any) {
+	var b strings.Builder
+	var args []any
+	for _, e := range f.Include {
+		b.WriteString(` AND c.path REGEXP ?`)
+		args = append(args, store.AnchorPathExpr(e.Expr))
+	}
+	for _, e := range f.Exclude {
+		b.WriteString(` AND NOT c.path REGEXP ?`)
+		args = append(args, store.AnchorPathExpr(e.Expr))
+	}
+	return b.String(), args
+}
+
+// ftsable reports whether n can be expressed as a single FTS5 MATCH string.
+// FTS5's NOT is binary, so a negation needs a positive sibling.
+func ftsable(n *parse.Node) bool {
+	switch n.Kind {
+	case parse.Term, parse.Phrase, parse.Near:
+		return true
+	case parse.Or:
+		for _, c := range n.Children {
+			if !ftsable(c) {
+				return false
+			}
+		}
+		return true
+	case parse.And:
+		positive := 0
+		for _, c := range n.Children {
+			if c.Kind == parse.Not {
+				if !ftsable(c.Children[0]) {
+					return false
+				}
+				continue
+			}
+			if !ftsable(c) {
+				return false
+			}
+			positive++
+		}
+		return positive > 0
+	default:
+		return false
+	}
+}
+
+// ftsMatch is the chunks_fts MATCH string for n. It searches the text
+// column only: titles repeat a symbol's signature on every window of it.
+func ftsMatch(n *parse.Node) string {
+	return "{text} : (" + ftsExpr(n) + ")"
+}
+
+func ftsExpr(n *parse.Node) string {
+	switch n.Kind {
+	case parse.Term, parse.Phrase:
+		// Quoting makes every term a phrase of its tokens, so punctuation in
+		// code ("a.b", "foo()") never turns into FTS5 syntax.
+		return `"` + strings.ReplaceAll(n.Value, `"`, `""`) + `"`
+	case parse.Near:
+		// FTS5 measures NEAR in tokens; allow a generous budget per line and
+		// leave the exact line distance to the query layer.
+		parts := make([]string, len(n.Children))
+		for i, c := range n.Children {
+			parts[i] = ftsExpr(c)
+		}
+		return fmt.Sprintf("NEAR(%s, %d)", strings.Join(parts, " "), (n.Dist+1)*nearTokensPerLine)
+	case parse.Or:
+		parts := make([]string, len(n.Children))
+		for i, c := range n.Children {
+			parts[i] = ftsExpr(c)
+		}
+		return "(" + strings.Join(parts, " OR ") + ")"
+	case parse.And:
+		var pos, neg []string
+		for _, c := range n.Children {
+			if c.Kind == parse.Not {
+				neg = append(neg, ftsExpr(c.Children[0]))
+				continue
+			}
+			pos = append(pos, ftsExpr(c))
+		}
+		expr := "(" + strings.Join(pos, " AND ") + ")"
+		if len(neg) > 0 {
+			expr = "(" + expr + " NOT (" + strings.Join(neg, " OR ") + "))"
+		}
+		return expr
+	default:
+		return ""
+	}
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+// escapeGlob keeps * and ? as wildcards and makes '[' literal, matching the
+// wildcard syntax used by the Bleve store.
+func escapeGlob(s string) string {
+	return strings.ReplaceAll(s, "[", "[[]")
+}
@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"otterindex/internal/model"
+)
+
+const insertRefSQL = `INSERT INTO refs(workspace_id,path,kind,name,sl,sc,el,ec,lang) VALUES(?,?,?,?,?,?,?,?,?)`
+
+func insertRef(ctx context.Context, stmt *sql.Stmt, workspaceID string, path string, r RefInput) error {
+	kind := strings.TrimSpace(r.Kind)
+	if kind == "" {
+		kind = "ref"
+	}
+	sc := r.SC
+	if sc <= 0 {
+		sc = 1
+	}
+	ec := r.EC
+	if ec <= 0 {
+		ec = 1
+	}
+	_, err := stmt.ExecContext(ctx, workspaceID, path, kind, r.Name, r.SL, sc, r.EL, ec, r.Lang)
+	return err
+}
+
+func (s *Store) ReplaceRefsBatch(workspaceID string, path string, refs []RefInput) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	path = filepath.ToSlash(path)
+	if workspaceID == "" {
+		return fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(path) == "" {
+		return fmt.Errorf("path is required")
+	}
+
+	if err := s.ensureWorkspace(workspaceID, ""); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		_, _ = conn.ExecContext(ctx, "ROLLBACK")
+	}()
+
+	if _, err := conn.ExecContext(ctx, `DELETE FROM refs WHERE workspace_id = ? AND path = ?`, workspaceID, path); err != nil {
+		return err
+	}
+
+	stmt, err := conn.PrepareContext(ctx, insertRefSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range refs {
+		if err := insertRef(ctx, stmt, workspaceID, path, r); err != nil {
+			return err
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (s *Store) FindRefs(workspaceID string, name string, opts RefSearchOptions) ([]model.RefItem, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	name = strings.TrimSpace(name)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	where := []string{
+		"workspace_id = ?",
+		"(name = ? OR (kind = 'import' AND (name GLOB ? OR name GLOB ? OR name GLOB ?)))",
+	}
+	args := []any{workspaceID, name, "*/" + escapeGlob(name), "*." + escapeGlob(name), "*\\" + escapeGlob(name)}
+	if kind := strings.TrimSpace(opts.Kind); kind != "" {
+		where = append(where, "kind = ?")
+		args = append(args, kind)
+	}
+	if lang := strings.TrimSpace(opts.Lang); lang != "" {
+		where = append(where, "lang = ?")
+		args = append(args, lang)
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.Query(
+		`SELECT path, kind, name, lang, sl, sc, el, ec
+		 FROM refs
+		 WHERE `+strings.Join(where, " AND ")+`
+		 ORDER BY path, sl, sc
+		 LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.RefItem
+	for rows.Next() {
+		var r model.RefItem
+		if err := rows.Scan(&r.Path, &r.Kind, &r.Name, &r.Lang, &r.Range.SL, &r.Range.SC, &r.Range.EL, &r.Range.EC); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Store) ListRefs(workspaceID string, path string) ([]model.RefItem, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	path = filepath.ToSlash(path)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	rows, err := s.db.Query(
+		`SELECT kind, name, lang, sl, sc, el, ec
+		 FROM refs
+		 WHERE workspace_id = ? AND path = ?
+		 ORDER BY sl, sc`,
+		workspaceID,
+		path,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.RefItem
+	for rows.Next() {
+		var r model.RefItem
+		r.Path = path
+		if err := rows.Scan(&r.Kind, &r.Name, &r.Lang, &r.Range.SL, &r.Range.SC, &r.Range.EL, &r.Range.EC); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"otterindex/internal/core/search"
+	"otterindex/internal/index/store"
+)
+
+func (s *Store) SearchChunksRegex(workspaceID string, pattern string, opts store.SearchOptions) (store.SearchResult, error) {
+	if s == nil || s.db == nil {
+		return store.SearchResult{}, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return store.SearchResult{}, fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(pattern) == "" {
+		return store.SearchResult{}, fmt.Errorf("pattern is required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	re, err := search.CompileRegex(pattern, opts.CaseInsensitive)
+	if err != nil {
+		return store.SearchResult{}, err
+	}
+
+	var rows *sql.Rows
+	where, args := pathFilter(opts.Filter)
+	match := trigramMatchQuery(search.RegexLiterals(pattern))
+	if s.hasTrigram && match != "" {
+		rows, err = s.db.Query(
+			`SELECT c.path, c.sl, c.el, c.kind, c.title, c.text
+			 FROM chunks_tri
+			 JOIN chunks c ON c.id = chunks_tri.rowid
+			 WHERE chunks_tri MATCH ? AND c.workspace_id = ?`+where+`
+			 ORDER BY c.path, c.sl, c.el`,
+			append([]any{match, workspaceID}, args...)...,
+		)
+	} else {
+		rows, err = s.db.Query(
+			`SELECT c.path, c.sl, c.el, c.kind, c.title, c.text
+			 FROM chunks c
+			 WHERE c.workspace_id = ?`+where+`
+			 ORDER BY c.path, c.sl, c.el`,
+			append([]any{workspaceID}, args...)...,
+		)
+	}
+	if err != nil {
+		return store.SearchResult{}, err
+	}
+	defer rows.Close()
+
+	var out []Chunk
+	for rows.Next() {
+		var c Chunk
+		c.WorkspaceID = workspaceID
+		if err := rows.Scan(&c.Path, &c.SL, &c.EL, &c.Kind, &c.Title, &c.Text); err != nil {
+			return store.SearchResult{}, err
+		}
+		if !search.RegexMatchesText(c.Text, re) {
+			continue
+		}
+		out = append(out, c)
+		if len(out) >= limit {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return store.SearchResult{}, err
+	}
+	return store.SearchResult{
+		Chunks:               out,
+		MatchCaseInsensitive: opts.CaseInsensitive,
+		Backend:              "sqlite",
+	}, nil
+}
+
+func (s *Store) tryCreateTrigram() error {
+	var name string
+	err := s.db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'chunks_tri'`).Scan(&name)
+	if err == nil {
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	stmts := []string{
+		`DROP TRIGGER IF EXISTS chunks_tri_ai`,
+		`DROP TRIGGER IF EXISTS chunks_tri_ad`,
+		`DROP TRIGGER IF EXISTS chunks_tri_au`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS chunks_tri
+		 USING fts5(
+		   text,
+		   content='chunks',
+		   content_rowid='id',
+		   tokenize='trigram'
+		 )`,
+		`CREATE TRIGGER IF NOT EXISTS chunks_tri_ai AFTER INSERT ON chunks BEGIN
+		   INSERT INTO chunks_tri(rowid, text) VALUES (new.id, new.text);
+		 END`,
+		`CREATE TRIGGER IF NOT EXISTS chunks_tri_ad AFTER DELETE ON chunks BEGIN
+		   INSERT INTO chunks_tri(chunks_tri, rowid, text) VALUES('delete', old.id, old.text);
+		 END`,
+		`CREATE TRIGGER IF NOT EXISTS chunks_tri_au AFTER UPDATE ON chunks BEGIN
+		   INSERT INTO chunks_tri(chunks_tri, rowid, text) VALUES('delete', old.id, old.text);
+		   INSERT INTO chunks_tri(rowid, text) VALUES (new.id, new.text);
+		 END`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	_, _ = s.db.Exec(`INSERT INTO chunks_tri(chunks_tri) VALUES('rebuild')`)
+	return nil
+}
+
+// trigramMatchQuery renders required literals as an FTS5 query against the
+// trigram table, where each quoted string is matched as a substring.
+func trigramMatchQuery(groups [][]string) string {
+	if len(groups) == 0 {
+		return ""
+	}
+	ors := make([]string, 0, len(groups))
+	for _, g := range groups {
+		ands := make([]string, 0, len(g))
+		for _, lit := range g {
+			ands = append(ands, `"`+strings.ReplaceAll(lit, `"`, `""`)+`"`)
+		}
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return strings.Join(ors, " OR ")
+}
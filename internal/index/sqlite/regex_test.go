@@ -0,0 +1,48 @@
+package sqlite
+
+import "testing"
+
+func TestSearchChunksRegex_PrunesWithTrigrams(t *testing.T) {
+	dbPath := t.TempDir() + "/index.db"
+	s, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+	if !s.hasTrigram {
+		t.Skip("trigram tokenizer not available")
+	}
+
+	ws := "ws1"
+	if err := s.ReplaceChunksBatch(ws, "a.go", []ChunkInput{
+		{SL: 1, EL: 2, Text: "func fooHandler() {}\nx"},
+		{SL: 3, EL: 4, Text: "handler without func\ny"},
+	}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+
+	res, err := s.SearchChunksRegex(ws, `func \w+Handler\(`, SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(res.Chunks) != 1 || res.Chunks[0].SL != 1 {
+		t.Fatalf("unexpected chunks: %+v", res.Chunks)
+	}
+
+	// No usable literal: falls back to scanning every chunk.
+	res, err = s.SearchChunksRegex(ws, `^\w$`, SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(res.Chunks) != 2 {
+		t.Fatalf("unexpected chunks: %+v", res.Chunks)
+	}
+}
+
+func TestTrigramMatchQuery(t *testing.T) {
+	got := trigramMatchQuery([][]string{{"func ", `a"b`}, {"xyz"}})
+	want := `("func " AND "a""b") OR ("xyz")`
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
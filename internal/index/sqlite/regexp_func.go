@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+
+	"otterindex/internal/core/cache"
+
+	msqlite "modernc.org/sqlite"
+)
+
+// regexpCache holds compiled patterns of the REGEXP function; a query
+// passes the same few patterns for every row, so a few entries suffice.
+var regexpCache = cache.NewLRU(64)
+
+func init() {
+	// Backs "X REGEXP Y", which SQLite rewrites to regexp(Y, X).
+	if err := msqlite.RegisterDeterministicScalarFunction("regexp", 2, sqlRegexp); err != nil {
+		panic(err)
+	}
+}
+
+func sqlRegexp(_ *msqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	pattern, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("regexp: pattern must be text")
+	}
+	var s string
+	switch v := args[1].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	var re *regexp.Regexp
+	if cached, ok := regexpCache.Get(pattern); ok {
+		re = cached.(*regexp.Regexp)
+	} else {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		regexpCache.Put(pattern, re)
+	}
+	return re.MatchString(s), nil
+}
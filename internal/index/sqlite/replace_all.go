@@ -65,6 +65,12 @@ func (s *Store) ReplaceFileAll(workspaceID string, path string, size int64, mtim
 	if _, err := conn.ExecContext(ctx, `DELETE FROM comments WHERE workspace_id = ? AND path = ?`, workspaceID, path); err != nil {
 		return err
 	}
+	if _, err := conn.ExecContext(ctx, `DELETE FROM refs WHERE workspace_id = ? AND path = ?`, workspaceID, path); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, `DELETE FROM vectors WHERE workspace_id = ? AND path = ?`, workspaceID, path); err != nil {
+		return err
+	}
 
 	if len(chunks) > 0 {
 		stmt, err := conn.PrepareContext(ctx, `INSERT INTO chunks(workspace_id,path,sl,el,kind,title,text) VALUES(?,?,?,?,?,?,?)`)
@@ -202,6 +208,12 @@ func (s *Store) DeleteFileAll(workspaceID string, path string) error {
 	if _, err := conn.ExecContext(ctx, `DELETE FROM comments WHERE workspace_id = ? AND path = ?`, workspaceID, path); err != nil {
 		return err
 	}
+	if _, err := conn.ExecContext(ctx, `DELETE FROM refs WHERE workspace_id = ? AND path = ?`, workspaceID, path); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, `DELETE FROM vectors WHERE workspace_id = ? AND path = ?`, workspaceID, path); err != nil {
+		return err
+	}
 
 	if _, err := conn.ExecContext(ctx,
 		`INSERT INTO meta(workspace_id, version, updated_at)
@@ -293,6 +305,18 @@ func (s *Store) ReplaceFilesBatch(workspaceID string, plans []FilePlan) error {
 	}
 	defer delCommentsStmt.Close()
 
+	delRefsStmt, err := conn.PrepareContext(ctx, `DELETE FROM refs WHERE workspace_id = ? AND path = ?`)
+	if err != nil {
+		return err
+	}
+	defer delRefsStmt.Close()
+
+	delVectorsStmt, err := conn.PrepareContext(ctx, `DELETE FROM vectors WHERE workspace_id = ? AND path = ?`)
+	if err != nil {
+		return err
+	}
+	defer delVectorsStmt.Close()
+
 	insertChunkStmt, err := conn.PrepareContext(ctx, `INSERT INTO chunks(workspace_id,path,sl,el,kind,title,text) VALUES(?,?,?,?,?,?,?)`)
 	if err != nil {
 		return err
@@ -311,6 +335,18 @@ func (s *Store) ReplaceFilesBatch(workspaceID string, plans []FilePlan) error {
 	}
 	defer insertCommStmt.Close()
 
+	insertRefStmt, err := conn.PrepareContext(ctx, insertRefSQL)
+	if err != nil {
+		return err
+	}
+	defer insertRefStmt.Close()
+
+	insertVectorStmt, err := conn.PrepareContext(ctx, insertVectorSQL)
+	if err != nil {
+		return err
+	}
+	defer insertVectorStmt.Close()
+
 	for _, plan := range plans {
 		path := filepath.ToSlash(strings.TrimSpace(plan.Path))
 		if path == "" {
@@ -330,6 +366,12 @@ func (s *Store) ReplaceFilesBatch(workspaceID string, plans []FilePlan) error {
 			if _, err := delCommentsStmt.ExecContext(ctx, workspaceID, path); err != nil {
 				return err
 			}
+			if _, err := delRefsStmt.ExecContext(ctx, workspaceID, path); err != nil {
+				return err
+			}
+			if _, err := delVectorsStmt.ExecContext(ctx, workspaceID, path); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -345,6 +387,12 @@ func (s *Store) ReplaceFilesBatch(workspaceID string, plans []FilePlan) error {
 		if _, err := delCommentsStmt.ExecContext(ctx, workspaceID, path); err != nil {
 			return err
 		}
+		if _, err := delRefsStmt.ExecContext(ctx, workspaceID, path); err != nil {
+			return err
+		}
+		if _, err := delVectorsStmt.ExecContext(ctx, workspaceID, path); err != nil {
+			return err
+		}
 
 		for _, c := range plan.Chunks {
 			kind := strings.TrimSpace(c.Kind)
@@ -389,6 +437,16 @@ func (s *Store) ReplaceFilesBatch(workspaceID string, plans []FilePlan) error {
 				return err
 			}
 		}
+		for _, r := range plan.Refs {
+			if err := insertRef(ctx, insertRefStmt, workspaceID, path, r); err != nil {
+				return err
+			}
+		}
+		for _, v := range plan.Vecs {
+			if err := insertVector(ctx, insertVectorStmt, workspaceID, path, v); err != nil {
+				return err
+			}
+		}
 	}
 
 	if _, err := conn.ExecContext(ctx,
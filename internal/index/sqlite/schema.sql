@@ -13,6 +13,14 @@ CREATE TABLE IF NOT EXISTS meta (
   FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 ) WITHOUT ROWID;
 
+CREATE TABLE IF NOT EXISTS settings (
+  workspace_id TEXT NOT NULL,
+  key TEXT NOT NULL,
+  value TEXT NOT NULL,
+  PRIMARY KEY (workspace_id, key),
+  FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+) WITHOUT ROWID;
+
 CREATE TABLE IF NOT EXISTS files (
   workspace_id TEXT NOT NULL,
   path TEXT NOT NULL,
@@ -71,3 +79,36 @@ CREATE TABLE IF NOT EXISTS comments (
 );
 
 CREATE INDEX IF NOT EXISTS idx_comments_path_range ON comments(workspace_id, path, sl, el);
+
+CREATE TABLE IF NOT EXISTS refs (
+  id INTEGER PRIMARY KEY,
+  workspace_id TEXT NOT NULL,
+  path TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  name TEXT NOT NULL,
+  sl INTEGER NOT NULL,
+  sc INTEGER NOT NULL DEFAULT 1,
+  el INTEGER NOT NULL,
+  ec INTEGER NOT NULL DEFAULT 1,
+  lang TEXT NOT NULL DEFAULT '',
+  FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_refs_name ON refs(workspace_id, name);
+CREATE INDEX IF NOT EXISTS idx_refs_path ON refs(workspace_id, path);
+
+CREATE TABLE IF NOT EXISTS vectors (
+  id INTEGER PRIMARY KEY,
+  workspace_id TEXT NOT NULL,
+  path TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  title TEXT NOT NULL DEFAULT '',
+  sl INTEGER NOT NULL,
+  el INTEGER NOT NULL,
+  model TEXT NOT NULL,
+  vec BLOB NOT NULL,
+  FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_vectors_path ON vectors(workspace_id, path);
+CREATE INDEX IF NOT EXISTS idx_vectors_model ON vectors(workspace_id, model);
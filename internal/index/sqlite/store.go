@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"otterindex/internal/core/query/parse"
 	"otterindex/internal/index/store"
 
 	_ "modernc.org/sqlite"
@@ -19,9 +20,10 @@ import (
 var schemaSQL string
 
 type Store struct {
-	db     *sql.DB
-	hasFTS bool
-	ftsErr error
+	db         *sql.DB
+	hasFTS     bool
+	ftsErr     error
+	hasTrigram bool
 }
 
 func Open(dbPath string) (*Store, error) {
@@ -115,6 +117,46 @@ func (s *Store) BumpVersion(workspaceID string) error {
 	return err
 }
 
+func (s *Store) GetSetting(workspaceID string, key string) (string, error) {
+	if s == nil || s.db == nil {
+		return "", fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return "", fmt.Errorf("workspaceID is required")
+	}
+
+	var v string
+	err := s.db.QueryRow(`SELECT value FROM settings WHERE workspace_id = ? AND key = ?`, workspaceID, key).Scan(&v)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return v, err
+}
+
+func (s *Store) SetSetting(workspaceID string, key string, value string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return fmt.Errorf("workspaceID is required")
+	}
+	if err := s.ensureWorkspace(workspaceID, ""); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO settings(workspace_id, key, value)
+		 VALUES(?, ?, ?)
+		 ON CONFLICT(workspace_id, key) DO UPDATE SET value = excluded.value`,
+		workspaceID,
+		key,
+		value,
+	)
+	return err
+}
+
 func (s *Store) EnsureWorkspace(id string, root string) error {
 	if s == nil || s.db == nil {
 		return fmt.Errorf("store is not open")
@@ -293,7 +335,7 @@ func (s *Store) GetWorkspace(workspaceID string) (Workspace, error) {
 	return ws, nil
 }
 
-func (s *Store) SearchChunks(workspaceID string, keyword string, limit int, caseInsensitive bool) (store.SearchResult, error) {
+func (s *Store) SearchChunks(workspaceID string, keyword string, opts store.SearchOptions) (store.SearchResult, error) {
 	if s == nil || s.db == nil {
 		return store.SearchResult{}, fmt.Errorf("store is not open")
 	}
@@ -305,73 +347,53 @@ func (s *Store) SearchChunks(workspaceID string, keyword string, limit int, case
 	if keyword == "" {
 		return store.SearchResult{}, fmt.Errorf("keyword is required")
 	}
+	limit := opts.Limit
 	if limit <= 0 {
 		limit = 50
 	}
 
-	var rows *sql.Rows
-	var err error
-	if s.hasFTS {
-		rows, err = s.db.Query(
-			`SELECT path, sl, el, kind, title, text
-			 FROM chunks_fts
-			 WHERE chunks_fts MATCH ? AND workspace_id = ?
-			 ORDER BY path, sl, el
-			 LIMIT ?`,
-			keyword,
-			workspaceID,
-			limit,
-		)
-		if err != nil {
-			rows, err = s.db.Query(
-				`SELECT c.path, c.sl, c.el, c.kind, c.title, c.text
-				 FROM chunks_fts
-				 JOIN chunks c ON c.id = chunks_fts.rowid
-				 WHERE chunks_fts MATCH ? AND c.workspace_id = ?
-				 ORDER BY c.path, c.sl, c.el
-				 LIMIT ?`,
-				keyword,
-				workspaceID,
-				limit,
-			)
-		}
-		if err != nil {
-			return store.SearchResult{}, err
-		}
-		defer rows.Close()
-
-		var out []Chunk
-		for rows.Next() {
-			var c Chunk
-			c.WorkspaceID = workspaceID
-			if err := rows.Scan(&c.Path, &c.SL, &c.EL, &c.Kind, &c.Title, &c.Text); err != nil {
-				return store.SearchResult{}, err
-			}
-			out = append(out, c)
-		}
-		if err := rows.Err(); err != nil {
-			return store.SearchResult{}, err
-		}
-		return store.SearchResult{
-			Chunks:               out,
-			MatchCaseInsensitive: true,
-			Backend:              "sqlite",
-		}, nil
+	node, err := parse.Parse(keyword)
+	if err != nil {
+		return store.SearchResult{}, fmt.Errorf("invalid query: %w", err)
+	}
+	cq, err := compileChunkQuery(node, s.hasFTS, s.hasTrigram, opts.CaseInsensitive, opts.Subword)
+	if err != nil {
+		return store.SearchResult{}, err
+	}
+
+	var b strings.Builder
+	var args []any
+	ranked := cq.match != "" && opts.Sort != store.SortPath
+	if cq.match != "" {
+		// bm25() is lower-is-better; negate it so scores grow with relevance.
+		b.WriteString(`SELECT c.path, c.sl, c.el, c.kind, c.title, c.text, -bm25(chunks_fts)
+		 FROM chunks_fts
+		 JOIN chunks c ON c.id = chunks_fts.rowid
+		 WHERE chunks_fts MATCH ? AND c.workspace_id = ?`)
+		args = append(args, cq.match, workspaceID)
 	} else {
-		query := `SELECT path, sl, el, kind, title, text
-		          FROM chunks
-		          WHERE workspace_id = ? AND text LIKE '%' || ? || '%'
-		          ORDER BY path, sl, el
-		          LIMIT ?`
-		if caseInsensitive {
-			query = `SELECT path, sl, el, kind, title, text
-			         FROM chunks
-			         WHERE workspace_id = ? AND LOWER(text) LIKE '%' || LOWER(?) || '%'
-			         ORDER BY path, sl, el
-			         LIMIT ?`
-		}
-		rows, err = s.db.Query(query, workspaceID, keyword, limit)
+		b.WriteString(`SELECT c.path, c.sl, c.el, c.kind, c.title, c.text, 0
+		 FROM chunks c
+		 WHERE c.workspace_id = ?`)
+		args = append(args, workspaceID)
+	}
+	if cq.where != "" {
+		b.WriteString(" AND ")
+		b.WriteString(cq.where)
+		args = append(args, cq.args...)
+	}
+	if where, pargs := pathFilter(opts.Filter); where != "" {
+		b.WriteString(where)
+		args = append(args, pargs...)
+	}
+	if ranked {
+		b.WriteString(" ORDER BY bm25(chunks_fts), c.path, c.sl, c.el LIMIT ?")
+	} else {
+		b.WriteString(" ORDER BY c.path, c.sl, c.el LIMIT ?")
 	}
+	args = append(args, limit)
+
+	rows, err := s.db.Query(b.String(), args...)
 	if err != nil {
 		return store.SearchResult{}, err
 	}
@@ -381,9 +403,12 @@ func (s *Store) SearchChunks(workspaceID string, keyword string, limit int, case
 	for rows.Next() {
 		var c Chunk
 		c.WorkspaceID = workspaceID
-		if err := rows.Scan(&c.Path, &c.SL, &c.EL, &c.Kind, &c.Title, &c.Text); err != nil {
+		if err := rows.Scan(&c.Path, &c.SL, &c.EL, &c.Kind, &c.Title, &c.Text, &c.Score); err != nil {
 			return store.SearchResult{}, err
 		}
+		if !ranked {
+			c.Score = 0
+		}
 		out = append(out, c)
 	}
 	if err := rows.Err(); err != nil {
@@ -391,8 +416,9 @@ func (s *Store) SearchChunks(workspaceID string, keyword string, limit int, case
 	}
 	return store.SearchResult{
 		Chunks:               out,
-		MatchCaseInsensitive: caseInsensitive,
+		MatchCaseInsensitive: s.hasFTS || opts.CaseInsensitive,
 		Backend:              "sqlite",
+		Ranked:               ranked,
 	}, nil
 }
 
@@ -464,6 +490,9 @@ func (s *Store) init() error {
 		s.hasFTS = false
 		s.ftsErr = err
 	}
+	if s.hasFTS {
+		s.hasTrigram = s.tryCreateTrigram() == nil
+	}
 
 	return nil
 }
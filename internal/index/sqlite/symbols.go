@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"otterindex/internal/index/store"
 	"otterindex/internal/model"
 )
 
@@ -195,3 +196,253 @@ func (s *Store) FindMinEnclosingSymbols(workspaceID string, path string, line in
 	}
 	return out, nil
 }
+
+func (s *Store) ListSymbols(workspaceID string, path string) ([]model.SymbolItem, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	path = filepath.ToSlash(path)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	rows, err := s.db.Query(
+		`SELECT kind, name, container, lang, signature, sl, sc, el, ec
+		 FROM symbols
+		 WHERE workspace_id = ? AND path = ?
+		 ORDER BY sl, sc, el DESC`,
+		workspaceID,
+		path,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.SymbolItem
+	for rows.Next() {
+		var sym model.SymbolItem
+		sym.Path = path
+		if err := rows.Scan(&sym.Kind, &sym.Name, &sym.Container, &sym.Lang, &sym.Signature, &sym.Range.SL, &sym.Range.SC, &sym.Range.EL, &sym.Range.EC); err != nil {
+			return nil, err
+		}
+		out = append(out, sym)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Store) ListSymbolsByPaths(workspaceID string, paths []string) (map[string][]model.SymbolItem, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+
+	out := map[string][]model.SymbolItem{}
+	// Stay well below SQLite's host parameter limit.
+	const batch = 500
+	for start := 0; start < len(paths); start += batch {
+		part := paths[start:min(start+batch, len(paths))]
+		args := []any{workspaceID}
+		for _, p := range part {
+			args = append(args, filepath.ToSlash(p))
+		}
+		rows, err := s.db.Query(
+			`SELECT path, kind, name, container, lang, signature, sl, sc, el, ec
+			 FROM symbols
+			 WHERE workspace_id = ? AND path IN (?`+strings.Repeat(", ?", len(part)-1)+`)
+			 ORDER BY path, sl, sc, el DESC`,
+			args...,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var sym model.SymbolItem
+			if err := rows.Scan(&sym.Path, &sym.Kind, &sym.Name, &sym.Container, &sym.Lang, &sym.Signature, &sym.Range.SL, &sym.Range.SC, &sym.Range.EL, &sym.Range.EC); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			out[sym.Path] = append(out[sym.Path], sym)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) ListComments(workspaceID string, path string) ([]model.CommentItem, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	path = filepath.ToSlash(path)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	rows, err := s.db.Query(
+		`SELECT kind, text, lang, sl, sc, el, ec
+		 FROM comments
+		 WHERE workspace_id = ? AND path = ?
+		 ORDER BY sl, sc`,
+		workspaceID,
+		path,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.CommentItem
+	for rows.Next() {
+		var c model.CommentItem
+		c.Path = path
+		if err := rows.Scan(&c.Kind, &c.Text, &c.Lang, &c.Range.SL, &c.Range.SC, &c.Range.EL, &c.Range.EC); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Store) SearchComments(workspaceID string, terms []string, opts CommentSearchOptions) ([]model.CommentItem, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("terms are required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	var b strings.Builder
+	b.WriteString(`SELECT path, kind, text, lang, sl, sc, el, ec
+		 FROM comments
+		 WHERE workspace_id = ?`)
+	args := []any{workspaceID}
+	for _, t := range terms {
+		b.WriteString(` AND text LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(t)+"%")
+	}
+	b.WriteString(" ORDER BY path, sl, sc LIMIT ?")
+	args = append(args, limit)
+
+	rows, err := s.db.Query(b.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.CommentItem
+	for rows.Next() {
+		var c model.CommentItem
+		if err := rows.Scan(&c.Path, &c.Kind, &c.Text, &c.Lang, &c.Range.SL, &c.Range.SC, &c.Range.EL, &c.Range.EC); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Store) SearchSymbols(workspaceID string, pattern string, opts SymbolSearchOptions) ([]model.SymbolItem, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	pattern = strings.TrimSpace(pattern)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	// LIKE is case-insensitive for ASCII, which is what identifiers mostly are.
+	sub := subsequenceLike(pattern)
+	var b strings.Builder
+	b.WriteString(`SELECT path, kind, name, container, lang, signature, sl, sc, el, ec
+		 FROM symbols
+		 WHERE workspace_id = ?
+		   AND (name LIKE ? ESCAPE '\' OR (container || '.' || name) LIKE ? ESCAPE '\' OR signature LIKE ? ESCAPE '\')`)
+	args := []any{workspaceID, sub, sub, sub}
+	if kind := strings.TrimSpace(opts.Kind); kind != "" {
+		b.WriteString(" AND kind = ?")
+		args = append(args, kind)
+	}
+	if lang := strings.TrimSpace(opts.Lang); lang != "" {
+		b.WriteString(" AND lang = ?")
+		args = append(args, lang)
+	}
+	b.WriteString(" ORDER BY LOWER(name) = LOWER(?) DESC, LENGTH(name), name, path, sl LIMIT ?")
+	if opts.Score != nil {
+		// Every candidate is scored; LIMIT -1 is no limit.
+		args = append(args, pattern, -1)
+	} else {
+		args = append(args, pattern, limit)
+	}
+
+	rows, err := s.db.Query(b.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.SymbolItem
+	for rows.Next() {
+		var sym model.SymbolItem
+		if err := rows.Scan(&sym.Path, &sym.Kind, &sym.Name, &sym.Container, &sym.Lang, &sym.Signature, &sym.Range.SL, &sym.Range.SC, &sym.Range.EL, &sym.Range.EC); err != nil {
+			return nil, err
+		}
+		out = append(out, sym)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Score != nil {
+		return store.BestSymbols(out, opts.Score, limit), nil
+	}
+	return out, nil
+}
+
+// subsequenceLike turns "NRC" into the LIKE pattern "%N%R%C%".
+func subsequenceLike(pattern string) string {
+	var b strings.Builder
+	b.WriteByte('%')
+	for _, r := range pattern {
+		b.WriteString(escapeLike(string(r)))
+		b.WriteByte('%')
+	}
+	return b.String()
+}
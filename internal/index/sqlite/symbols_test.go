@@ -30,6 +30,14 @@ func TestSymbols_ReplaceAndFindMinEnclosing(t *testing.T) {
 		t.Fatalf("syms=%v", syms)
 	}
 
+	byPath, err := s.ListSymbolsByPaths("ws1", []string{path, "b.go"})
+	if err != nil {
+		t.Fatalf("list by paths: %v", err)
+	}
+	if got := byPath[path]; len(got) != 2 || got[0].Name != "big" || got[0].Path != path || len(byPath["b.go"]) != 0 {
+		t.Fatalf("byPath=%v", byPath)
+	}
+
 	if err := s.ReplaceSymbolsBatch("ws1", path, nil); err != nil {
 		t.Fatalf("replace nil: %v", err)
 	}
@@ -7,5 +7,12 @@ type Chunk = store.Chunk
 type ChunkInput = store.ChunkInput
 type SymbolInput = store.SymbolInput
 type CommentInput = store.CommentInput
+type RefInput = store.RefInput
+type VectorInput = store.VectorInput
 type Workspace = store.Workspace
 type FilePlan = store.FilePlan
+type SearchOptions = store.SearchOptions
+type SymbolSearchOptions = store.SymbolSearchOptions
+type RefSearchOptions = store.RefSearchOptions
+type CommentSearchOptions = store.CommentSearchOptions
+type VectorSearchOptions = store.VectorSearchOptions
@@ -0,0 +1,161 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"otterindex/internal/core/embed"
+)
+
+const insertVectorSQL = `INSERT INTO vectors(workspace_id,path,kind,title,sl,el,model,vec) VALUES(?,?,?,?,?,?,?,?)`
+
+func insertVector(ctx context.Context, stmt *sql.Stmt, workspaceID string, path string, v VectorInput) error {
+	kind := strings.TrimSpace(v.Kind)
+	if kind == "" {
+		kind = "chunk"
+	}
+	_, err := stmt.ExecContext(ctx, workspaceID, path, kind, v.Title, v.SL, v.EL, v.Model, embed.Encode(v.Vec))
+	return err
+}
+
+func (s *Store) ReplaceVectorsBatch(workspaceID string, path string, vecs []VectorInput) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	path = filepath.ToSlash(path)
+	if workspaceID == "" {
+		return fmt.Errorf("workspaceID is required")
+	}
+	if strings.TrimSpace(path) == "" {
+		return fmt.Errorf("path is required")
+	}
+
+	if err := s.ensureWorkspace(workspaceID, ""); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		_, _ = conn.ExecContext(ctx, "ROLLBACK")
+	}()
+
+	if _, err := conn.ExecContext(ctx, `DELETE FROM vectors WHERE workspace_id = ? AND path = ?`, workspaceID, path); err != nil {
+		return err
+	}
+
+	stmt, err := conn.PrepareContext(ctx, insertVectorSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, v := range vecs {
+		if err := insertVector(ctx, stmt, workspaceID, path, v); err != nil {
+			return err
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// SearchVectors scores every vector of the model; there is no ANN index, which
+// is fine at the size of a source tree.
+func (s *Store) SearchVectors(workspaceID string, vec []float32, opts VectorSearchOptions) ([]Chunk, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if len(vec) == 0 {
+		return nil, fmt.Errorf("vector is required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	where, args := pathFilter(opts.Filter)
+	rows, err := s.db.Query(
+		`SELECT c.path, c.kind, c.title, c.sl, c.el, c.vec
+		 FROM vectors c
+		 WHERE c.workspace_id = ? AND c.model = ?`+where,
+		append([]any{workspaceID, opts.Model}, args...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Chunk
+	for rows.Next() {
+		var c Chunk
+		var raw []byte
+		if err := rows.Scan(&c.Path, &c.Kind, &c.Title, &c.SL, &c.EL, &raw); err != nil {
+			return nil, err
+		}
+		v, err := embed.Decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		c.WorkspaceID = workspaceID
+		c.Score = embed.Cosine(vec, v)
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].SL < out[j].SL
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *Store) VectorModel(workspaceID string) (string, error) {
+	if s == nil || s.db == nil {
+		return "", fmt.Errorf("store is not open")
+	}
+	workspaceID = strings.TrimSpace(workspaceID)
+	if workspaceID == "" {
+		return "", fmt.Errorf("workspaceID is required")
+	}
+	var name string
+	err := s.db.QueryRow(`SELECT model FROM vectors WHERE workspace_id = ? LIMIT 1`, workspaceID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return name, err
+}
@@ -0,0 +1,56 @@
+package store
+
+import "regexp"
+
+// PathFilter restricts a search to some files. Stores evaluate it inside the
+// query, so Limit counts only chunks that pass. A path passes when it
+// matches every Include expression and no Exclude one.
+type PathFilter struct {
+	Include []PathExpr
+	Exclude []PathExpr
+}
+
+// PathExpr is an RE2 expression over a whole slash-separated path.
+type PathExpr struct {
+	// Expr has no anchors: SQL callers anchor it, and Bleve matches whole
+	// path terms.
+	Expr string
+	re   *regexp.Regexp
+}
+
+// CompilePathExpr compiles expr anchored at both ends.
+func CompilePathExpr(expr string) (PathExpr, error) {
+	re, err := regexp.Compile(AnchorPathExpr(expr))
+	if err != nil {
+		return PathExpr{}, err
+	}
+	return PathExpr{Expr: expr, re: re}, nil
+}
+
+// AnchorPathExpr returns expr anchored to match the whole path.
+func AnchorPathExpr(expr string) string {
+	return "^(?:" + expr + ")$"
+}
+
+func (e PathExpr) Match(p string) bool {
+	return e.re != nil && e.re.MatchString(p)
+}
+
+func (f PathFilter) IsZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Match applies the filter to a slash-separated path.
+func (f PathFilter) Match(p string) bool {
+	for _, e := range f.Include {
+		if !e.Match(p) {
+			return false
+		}
+	}
+	for _, e := range f.Exclude {
+		if e.Match(p) {
+			return false
+		}
+	}
+	return true
+}
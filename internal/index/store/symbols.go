@@ -0,0 +1,38 @@
+package store
+
+import (
+	"sort"
+
+	"otterindex/internal/model"
+)
+
+// BestSymbols keeps the limit symbols with the highest score, ties by path
+// and line, dropping the ones score rejects.
+func BestSymbols(syms []model.SymbolItem, score func(model.SymbolItem) (int, bool), limit int) []model.SymbolItem {
+	type scored struct {
+		sym   model.SymbolItem
+		score int
+	}
+	ranked := make([]scored, 0, len(syms))
+	for _, sym := range syms {
+		if s, ok := score(sym); ok {
+			ranked = append(ranked, scored{sym: sym, score: s})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.sym.Path != b.sym.Path {
+			return a.sym.Path < b.sym.Path
+		}
+		return a.sym.Range.SL < b.sym.Range.SL
+	})
+
+	out := make([]model.SymbolItem, 0, min(len(ranked), limit))
+	for _, r := range ranked[:min(len(ranked), limit)] {
+		out = append(out, r.sym)
+	}
+	return out
+}
@@ -19,6 +19,9 @@ type Chunk struct {
 	Text        string
 	Snippet     string
 	WorkspaceID string
+	// Score is the backend's relevance score (higher is better); zero when
+	// results are not ranked.
+	Score float64
 }
 
 type ChunkInput struct {
@@ -51,6 +54,30 @@ type CommentInput struct {
 	Lang string
 }
 
+// RefInput is a use of a name: an identifier reference, a call or an import.
+// For imports, Name is the imported path as written ("os/exec",
+// "java.util.List").
+type RefInput struct {
+	Kind string
+	Name string
+	SL   int
+	SC   int
+	EL   int
+	EC   int
+	Lang string
+}
+
+// VectorInput is the embedding of one unit of a file: a chunk or a
+// function-like symbol. Model names the embedder that produced Vec.
+type VectorInput struct {
+	Kind  string
+	Title string
+	SL    int
+	EL    int
+	Model string
+	Vec   []float32
+}
+
 type Workspace struct {
 	ID        string
 	Root      string
@@ -65,13 +92,62 @@ type FilePlan struct {
 	Chunks []ChunkInput
 	Syms   []SymbolInput
 	Comms  []CommentInput
+	Refs   []RefInput
+	Vecs   []VectorInput
 	Delete bool
 }
 
+const (
+	SortScore = "score"
+	SortPath  = "path"
+)
+
+type SearchOptions struct {
+	Limit           int
+	CaseInsensitive bool
+	// Sort is SortScore (the default) or SortPath. Regex searches are always
+	// returned in path order.
+	Sort string
+	// Subword matches terms anywhere inside identifiers (so "open" finds
+	// OpenFile) instead of only as whole tokens.
+	Subword bool
+	// Filter restricts the chunks to some paths.
+	Filter PathFilter
+}
+
+type SymbolSearchOptions struct {
+	Kind  string
+	Lang  string
+	Limit int
+	// Score, when set, ranks every prefiltered candidate before Limit
+	// applies: rejected symbols are dropped and the best-scoring ones kept,
+	// instead of the first ones in the store's order.
+	Score func(model.SymbolItem) (int, bool)
+}
+
+type RefSearchOptions struct {
+	Kind  string
+	Lang  string
+	Limit int
+}
+
+type CommentSearchOptions struct {
+	Limit int
+}
+
+type VectorSearchOptions struct {
+	Model  string
+	Limit  int
+	Filter PathFilter
+}
+
 type SearchResult struct {
 	Chunks               []Chunk
 	MatchCaseInsensitive bool
 	Backend              string
+	// Ranked reports whether Chunks are the best matches by score; otherwise
+	// they are the first matches in path order and carry no score.
+	Ranked bool
 }
 
 type Store interface {
@@ -83,6 +159,9 @@ type Store interface {
 
 	GetVersion(workspaceID string) (int64, error)
 	BumpVersion(workspaceID string) error
+	// GetSetting returns a value recorded with SetSetting, or "" when unset.
+	GetSetting(workspaceID string, key string) (string, error)
+	SetSetting(workspaceID string, key string, value string) error
 	EnsureWorkspace(id string, root string) error
 
 	UpsertFile(workspaceID string, path string, size int64, mtime int64, hash string) error
@@ -93,17 +172,46 @@ type Store interface {
 	DeleteFile(workspaceID string, path string) error
 
 	GetWorkspace(workspaceID string) (Workspace, error)
-	SearchChunks(workspaceID string, keyword string, limit int, caseInsensitive bool) (SearchResult, error)
+	SearchChunks(workspaceID string, keyword string, opts SearchOptions) (SearchResult, error)
+	SearchChunksRegex(workspaceID string, pattern string, opts SearchOptions) (SearchResult, error)
 
 	ReplaceChunksBatch(workspaceID string, path string, chunks []ChunkInput) error
 	ReplaceSymbolsBatch(workspaceID string, path string, syms []SymbolInput) error
 	ReplaceCommentsBatch(workspaceID string, path string, comms []CommentInput) error
+	ReplaceRefsBatch(workspaceID string, path string, refs []RefInput) error
+	ReplaceVectorsBatch(workspaceID string, path string, vecs []VectorInput) error
 
 	ReplaceFileAll(workspaceID string, path string, size int64, mtime int64, hash string, chunks []ChunkInput, syms []SymbolInput, comms []CommentInput) error
 	DeleteFileAll(workspaceID string, path string) error
 	ReplaceFilesBatch(workspaceID string, plans []FilePlan) error
 
 	FindMinEnclosingSymbols(workspaceID string, path string, line int) ([]model.SymbolItem, error)
+	ListSymbols(workspaceID string, path string) ([]model.SymbolItem, error)
+	// ListSymbolsByPaths is ListSymbols for several files at once, keyed by
+	// path.
+	ListSymbolsByPaths(workspaceID string, paths []string) (map[string][]model.SymbolItem, error)
+	// ListComments returns the comments of one file in source order.
+	ListComments(workspaceID string, path string) ([]model.CommentItem, error)
+	// SearchComments returns comments containing every term
+	// (case-insensitive), in path order.
+	SearchComments(workspaceID string, terms []string, opts CommentSearchOptions) ([]model.CommentItem, error)
+	// SearchSymbols returns candidate symbols whose name, container-qualified
+	// name or signature contains the pattern's characters in order, ignoring
+	// case. Exact name matches come first; callers rank the rest.
+	SearchSymbols(workspaceID string, pattern string, opts SymbolSearchOptions) ([]model.SymbolItem, error)
+	// FindRefs returns the uses of name, plus imports whose last path
+	// segments are name ("exec" and "os/exec" both match "os/exec"), ordered
+	// by path and position.
+	FindRefs(workspaceID string, name string, opts RefSearchOptions) ([]model.RefItem, error)
+	// ListRefs returns the refs of one file in source order.
+	ListRefs(workspaceID string, path string) ([]model.RefItem, error)
+	// SearchVectors returns the units whose opts.Model vectors are most
+	// similar to vec, best first, with the cosine similarity as Score. Text
+	// is not filled in.
+	SearchVectors(workspaceID string, vec []float32, opts VectorSearchOptions) ([]Chunk, error)
+	// VectorModel returns the embedder name of the workspace's vectors, or
+	// "" when it has none.
+	VectorModel(workspaceID string) (string, error)
 
 	CountChunks(workspaceID string) (int, error)
 	CountFiles(workspaceID string) (int, error)
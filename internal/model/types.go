@@ -10,6 +10,7 @@ type Range struct {
 type Match struct {
 	Line int    `json:"line"`
 	Col  int    `json:"col"`
+	Len  int    `json:"len,omitempty"`
 	Text string `json:"text"`
 }
 
@@ -21,6 +22,58 @@ type ResultItem struct {
 	Snippet string  `json:"snippet,omitempty"`
 	Text    string  `json:"text,omitempty"`
 	Matches []Match `json:"matches,omitempty"`
+	Score   float64 `json:"score,omitempty"`
+	// Symbol is the symbol a comment hit documents (--in comments).
+	Symbol *SymbolItem `json:"symbol,omitempty"`
+}
+
+// ResultPage is one page of query results with totals over all of them.
+type ResultPage struct {
+	Items []ResultItem `json:"items"`
+	// Total counts every result; when TotalExact is false it is a lower
+	// bound (counting stopped early).
+	Total      int    `json:"total"`
+	TotalExact bool   `json:"total_exact"`
+	Facets     Facets `json:"facets"`
+	// Cursor continues after this page; empty on the last page.
+	Cursor string `json:"cursor,omitempty"`
+	// Truncated counts, for the files on this page, the hits the per-file
+	// cap dropped (see FileGroup).
+	Truncated map[string]int `json:"truncated,omitempty"`
+}
+
+// Facets count the counted results by parent directory, file extension and
+// language, largest first.
+type Facets struct {
+	Dirs  []FacetCount `json:"dirs,omitempty"`
+	Exts  []FacetCount `json:"exts,omitempty"`
+	Langs []FacetCount `json:"langs,omitempty"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// FileCount is the number of lines matching a query in one file.
+type FileCount struct {
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+// CountResult holds exact per-file line counts (by path) and their sum.
+type CountResult struct {
+	Files []FileCount `json:"files"`
+	Total int         `json:"total"`
+}
+
+// FileGroup holds the results of one file. Truncated counts the further hits
+// in the file that the per-file cap dropped. Only fetched candidates are
+// counted, so it is a lower bound.
+type FileGroup struct {
+	Path      string       `json:"path"`
+	Items     []ResultItem `json:"items"`
+	Truncated int          `json:"truncated,omitempty"`
 }
 
 type SymbolItem struct {
@@ -33,6 +86,55 @@ type SymbolItem struct {
 	Range     Range  `json:"range"`
 }
 
+// OutlineNode is a symbol with the symbols nested inside it.
+type OutlineNode struct {
+	SymbolItem
+	Children []OutlineNode `json:"children,omitempty"`
+}
+
+type RefItem struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Lang  string `json:"lang,omitempty"`
+	Path  string `json:"path"`
+	Range Range  `json:"range"`
+	// Text is the source line of the use.
+	Text string `json:"text,omitempty"`
+	// Symbol is the innermost symbol enclosing the use.
+	Symbol *SymbolItem `json:"symbol,omitempty"`
+}
+
+// CallEdge is one call site: Caller calls Callee at Path:Range.
+type CallEdge struct {
+	// Caller is the function or method containing the call; nil for calls
+	// outside any function.
+	Caller *SymbolItem `json:"caller,omitempty"`
+	Callee string      `json:"callee"`
+	Path   string      `json:"path"`
+	Range  Range       `json:"range"`
+	Text   string      `json:"text,omitempty"`
+	// Depth is 1 for direct callers/callees, 2 for their callers/callees, ...
+	Depth int `json:"depth"`
+}
+
+// DepEdge is one import: the file at Path imports Module (an import path,
+// module name or included header, as written).
+type DepEdge struct {
+	Path   string `json:"path"`
+	Module string `json:"module"`
+	Lang   string `json:"lang,omitempty"`
+	Range  Range  `json:"range"`
+}
+
+// FileItem is an indexed file; Score is set when it was matched by a pattern.
+type FileItem struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	MTime int64  `json:"mtime"`
+	Hash  string `json:"hash,omitempty"`
+	Score int    `json:"score,omitempty"`
+}
+
 type CommentItem struct {
 	Kind  string `json:"kind"`
 	Text  string `json:"text,omitempty"`
@@ -0,0 +1,70 @@
+package otidxcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"otterindex/internal/core/query"
+)
+
+func newASTCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ast <query> --lang <lang>",
+		Short: "Structural search with a tree-sitter S-expression query",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isTestMode(cmd) {
+				return nil
+			}
+
+			opts := optionsFrom(cmd)
+			if opts == nil {
+				return fmt.Errorf("options missing")
+			}
+			if strings.TrimSpace(opts.Lang) == "" {
+				return fmt.Errorf("--lang is required")
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			workspaceID, err := filepath.Abs(cwd)
+			if err != nil {
+				return err
+			}
+
+			items, err := query.AST(opts.DBPath, workspaceID, strings.Join(args, " "), query.ASTOptions{
+				Store:        opts.Store,
+				Lang:         opts.Lang,
+				IncludeGlobs: opts.IncludeGlobs,
+				ExcludeGlobs: opts.ExcludeGlobs,
+				Limit:        opts.Limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			var out string
+			switch {
+			case opts.Jsonl:
+				if opts.Show {
+					AttachText(workspaceID, items)
+				}
+				out = RenderJSONL(items)
+			case opts.VimLines:
+				out = RenderVim(items)
+			case opts.Compact:
+				out = RenderDefault(items)
+			default:
+				out = RenderShow(workspaceID, items)
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package otidxcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"otterindex/internal/core/query"
+	"otterindex/internal/model"
+)
+
+func newCallersCommand() *cobra.Command {
+	return newCallGraphCommand("callers <func>", "List the call sites of a function (transitively with --depth)", query.Callers)
+}
+
+func newCalleesCommand() *cobra.Command {
+	return newCallGraphCommand("callees <func>", "List the calls made by a function (transitively with --depth)", query.Callees)
+}
+
+type callGraphFunc func(dbPath string, workspaceID string, name string, opts query.CallGraphOptions) ([]model.CallEdge, error)
+
+func newCallGraphCommand(use string, short string, run callGraphFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isTestMode(cmd) {
+				return nil
+			}
+
+			opts := optionsFrom(cmd)
+			if opts == nil {
+				return fmt.Errorf("options missing")
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			workspaceID, err := filepath.Abs(cwd)
+			if err != nil {
+				return err
+			}
+
+			edges, err := run(opts.DBPath, workspaceID, args[0], query.CallGraphOptions{
+				Store: opts.Store,
+				Lang:  opts.Lang,
+				Depth: opts.Depth,
+				Limit: opts.Limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			var out string
+			switch {
+			case opts.Jsonl:
+				out = RenderCallEdgesJSONL(edges)
+			case opts.VimLines:
+				out = RenderCallEdgesVim(edges)
+			default:
+				out = RenderCallEdges(edges)
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+}
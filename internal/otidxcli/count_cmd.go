@@ -0,0 +1,51 @@
+package otidxcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"otterindex/internal/core/query"
+)
+
+func newCountCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "count <query...>",
+		Short: "Count matching lines per file, straight from the index",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isTestMode(cmd) {
+				return nil
+			}
+
+			opts := optionsFrom(cmd)
+			if opts == nil {
+				return fmt.Errorf("options missing")
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			workspaceID, err := filepath.Abs(cwd)
+			if err != nil {
+				return err
+			}
+
+			res, err := query.Count(opts.DBPath, workspaceID, strings.Join(args, " "), opts.queryOptions())
+			if err != nil {
+				return err
+			}
+
+			if opts.Jsonl {
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), RenderCountJSONL(res))
+				return nil
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), RenderCount(res))
+			return nil
+		},
+	}
+}
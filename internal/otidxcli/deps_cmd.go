@@ -0,0 +1,114 @@
+package otidxcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"otterindex/internal/core/query"
+)
+
+func newDepsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "deps [path]",
+		Short: "List what a file imports (or export the whole import graph with --graph)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isTestMode(cmd) {
+				return nil
+			}
+
+			opts := optionsFrom(cmd)
+			if opts == nil {
+				return fmt.Errorf("options missing")
+			}
+			if len(args) == 0 && opts.Graph == "" {
+				return fmt.Errorf("path is required (or use --graph dot|json for the whole workspace)")
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			workspaceID, err := filepath.Abs(cwd)
+			if err != nil {
+				return err
+			}
+
+			var edges []DepEdge
+			if len(args) == 0 {
+				edges, err = query.DepGraph(opts.DBPath, workspaceID, query.DepsOptions{Store: opts.Store})
+			} else {
+				path := args[0]
+				if filepath.IsAbs(path) {
+					if rel, err := filepath.Rel(workspaceID, path); err == nil {
+						path = rel
+					}
+				}
+				path = filepath.ToSlash(filepath.Clean(path))
+				edges, err = query.Deps(opts.DBPath, workspaceID, path, query.DepsOptions{Store: opts.Store})
+			}
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), renderDepEdges(opts, edges))
+			return nil
+		},
+	}
+}
+
+func newRDepsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rdeps <module>",
+		Short: "List the files importing a module, package or header",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isTestMode(cmd) {
+				return nil
+			}
+
+			opts := optionsFrom(cmd)
+			if opts == nil {
+				return fmt.Errorf("options missing")
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			workspaceID, err := filepath.Abs(cwd)
+			if err != nil {
+				return err
+			}
+
+			edges, err := query.RDeps(opts.DBPath, workspaceID, args[0], query.DepsOptions{
+				Store: opts.Store,
+				Limit: opts.Limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), renderDepEdges(opts, edges))
+			return nil
+		},
+	}
+}
+
+func renderDepEdges(opts *Options, edges []DepEdge) string {
+	switch {
+	case opts.Graph == "dot":
+		return RenderDepsDOT(edges)
+	case opts.Graph == "json":
+		return RenderDepsGraphJSON(edges)
+	case opts.Jsonl:
+		return RenderDepsJSONL(edges)
+	case opts.VimLines:
+		return RenderDepsVim(edges)
+	default:
+		return RenderDeps(edges)
+	}
+}
@@ -0,0 +1,56 @@
+package otidxcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"otterindex/internal/core/query"
+)
+
+func newFilesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "files [pattern]",
+		Short: "List indexed files, fuzzy-matching their paths (fzf style)",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isTestMode(cmd) {
+				return nil
+			}
+
+			opts := optionsFrom(cmd)
+			if opts == nil {
+				return fmt.Errorf("options missing")
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			workspaceID, err := filepath.Abs(cwd)
+			if err != nil {
+				return err
+			}
+
+			files, err := query.SearchFiles(opts.DBPath, workspaceID, strings.Join(args, " "), query.FilesOptions{
+				Store:        opts.Store,
+				IncludeGlobs: opts.IncludeGlobs,
+				ExcludeGlobs: opts.ExcludeGlobs,
+				Limit:        opts.Limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			if opts.Jsonl {
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), RenderFilesJSONL(files))
+				return nil
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), RenderFiles(files))
+			return nil
+		},
+	}
+}
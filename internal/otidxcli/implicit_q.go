@@ -77,7 +77,7 @@ func firstPositionalArgAfterFlags(args []string) (string, bool) {
 
 			name := strings.TrimPrefix(a, "--")
 			switch name {
-			case "database", "exclude", "glob", "context", "limit", "offset", "cache-size", "unit", "viz":
+			case "database", "store", "exclude", "glob", "context", "limit", "offset", "cache-size", "unit", "viz", "sort", "in", "mode", "kind", "lang", "depth", "graph", "type", "type-not", "type-add", "cursor", "max-per-file", "group-by":
 				skipNext = true
 			case "explain":
 				// Optional value; only consume known formats.
@@ -92,10 +92,10 @@ func firstPositionalArgAfterFlags(args []string) (string, bool) {
 		}
 
 		if strings.HasPrefix(a, "-") && a != "-" {
-			// Handle value-taking short flags: -d/-x/-g/-c
+			// Handle value-taking short flags: -d/-x/-g/-c/-t/-T
 			if len(a) == 2 {
 				switch a[1] {
-				case 'd', 'x', 'g', 'c':
+				case 'd', 'x', 'g', 'c', 't', 'T':
 					skipNext = true
 				}
 				continue
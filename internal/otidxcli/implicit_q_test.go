@@ -20,7 +20,10 @@ func TestRewriteArgsForImplicitQ(t *testing.T) {
 		{name: "implicit_query_multi", in: []string{"hello", "world"}, want: []string{"q", "hello", "world"}},
 		{name: "implicit_query_with_database_flag", in: []string{"-d", "demo", "hello"}, want: []string{"q", "-d", "demo", "hello"}},
 		{name: "explicit_index_with_database_flag", in: []string{"-d", "demo", "index", "build", "."}, want: []string{"-d", "demo", "index", "build", "."}},
+		{name: "implicit_query_with_type_flag", in: []string{"-t", "go", "hello"}, want: []string{"q", "-t", "go", "hello"}},
+		{name: "explicit_index_with_store_flag", in: []string{"--store", "bleve", "index", "build", "."}, want: []string{"--store", "bleve", "index", "build", "."}},
 		{name: "root_only_flags", in: []string{"--list-databases"}, want: []string{"--list-databases"}},
+		{name: "root_bare_l", in: []string{"-l"}, want: []string{"-l"}},
 		{name: "root_viz_flag", in: []string{"--viz", "ascii"}, want: []string{"--viz", "ascii"}},
 		{name: "help_command", in: []string{"help"}, want: []string{"help"}},
 		{name: "completion_command", in: []string{"completion", "bash"}, want: []string{"completion", "bash"}},
@@ -3,9 +3,11 @@ package otidxcli
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"otterindex/internal/core/embed"
 	"otterindex/internal/core/indexer"
 )
 
@@ -21,6 +23,9 @@ func newIndexCommand() *cobra.Command {
 
 func newIndexBuildCommand() *cobra.Command {
 	var workers int
+	var embedName string
+	var full bool
+	var chunking string
 	cmd := &cobra.Command{
 		Use:   "build [path]",
 		Short: "Build (or rebuild) the local index",
@@ -43,6 +48,15 @@ func newIndexBuildCommand() *cobra.Command {
 				return fmt.Errorf("options missing")
 			}
 
+			var embedder embed.Embedder
+			if cmd.Flags().Changed("embed") {
+				e, ok := embed.Lookup(embedName)
+				if !ok {
+					return fmt.Errorf("unknown embedder %q (available: %s)", embedName, strings.Join(embed.Names(), ", "))
+				}
+				embedder = e
+			}
+
 			var ex *ExplainCollector
 			if opts.Explain != "" {
 				ex = NewExplainCollector(ExplainOptions{Format: opts.Explain})
@@ -55,6 +69,11 @@ func newIndexBuildCommand() *cobra.Command {
 				ScanAll:      opts.ScanAll,
 				IncludeGlobs: opts.IncludeGlobs,
 				ExcludeGlobs: opts.ExcludeGlobs,
+				Types:        opts.Types,
+				TypesNot:     opts.TypesNot,
+				Embedder:     embedder,
+				Full:         full,
+				Chunking:     chunking,
 				Explain:      ex,
 			})
 			if err != nil {
@@ -70,5 +89,9 @@ func newIndexBuildCommand() *cobra.Command {
 	}
 
 	cmd.Flags().IntVarP(&workers, "workers", "j", 0, "number of parallel index workers (default: CPU/2)")
+	cmd.Flags().StringVar(&embedName, "embed", "", "also store embeddings for --semantic queries, using this embedder (default: "+embed.Default+")")
+	cmd.Flags().Lookup("embed").NoOptDefVal = embed.Default
+	cmd.Flags().StringVar(&chunking, "chunking", indexer.ChunkingAuto, "how to cut files into chunks: lines (fixed windows), symbols (one per top-level symbol; files without symbols are not chunked) or auto (symbols where available, else lines)")
+	cmd.Flags().BoolVar(&full, "full", false, "re-read and rewrite every file instead of only the changed ones")
 	return cmd
 }
@@ -5,4 +5,15 @@ import "otterindex/internal/model"
 type Match = model.Match
 type Range = model.Range
 type ResultItem = model.ResultItem
-
+type ResultPage = model.ResultPage
+type Facets = model.Facets
+type FacetCount = model.FacetCount
+type FileGroup = model.FileGroup
+type FileCount = model.FileCount
+type CountResult = model.CountResult
+type SymbolItem = model.SymbolItem
+type OutlineNode = model.OutlineNode
+type RefItem = model.RefItem
+type CallEdge = model.CallEdge
+type DepEdge = model.DepEdge
+type FileItem = model.FileItem
@@ -9,6 +9,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"otterindex/internal/core/lang"
+	"otterindex/internal/core/query"
+	"otterindex/internal/core/search"
 	"otterindex/internal/index/backend"
 )
 
@@ -18,10 +21,31 @@ type Options struct {
 	ScanAll         bool
 	IncludeGlobs    []string
 	ExcludeGlobs    []string
+	Types           []string
+	TypesNot        []string
+	TypeAdd         []string
 	CaseInsensitive bool
+	Regex           bool
+	Word            bool
+	Ident           bool
+	AllTerms        bool
+	Semantic        bool
+	Sort            string
+	In              string
+	Mode            string
+	Kind            string
+	Lang            string
+	Depth           int
+	Graph           string
 	ContextLines    int
 	Limit           int
 	Offset          int
+	Summary         bool
+	Cursor          string
+	MaxPerFile      int
+	GroupBy         string
+	FilesWith       bool
+	FilesWithout    bool
 	Cache           bool
 	CacheSize       int
 	Compact         bool
@@ -53,9 +77,15 @@ func (o *Options) Prepare() error {
 	if o.Limit <= 0 {
 		return fmt.Errorf("limit must be >= 1")
 	}
+	if o.Depth <= 0 {
+		return fmt.Errorf("depth must be >= 1")
+	}
 	if o.Offset < 0 {
 		return fmt.Errorf("offset must be >= 0")
 	}
+	if o.MaxPerFile < 0 {
+		return fmt.Errorf("max per file must be >= 0")
+	}
 	if o.CacheSize <= 0 {
 		return fmt.Errorf("cache size must be >= 1")
 	}
@@ -67,6 +97,77 @@ func (o *Options) Prepare() error {
 		return fmt.Errorf("invalid --store %q (expected: sqlite|bleve)", o.Store)
 	}
 
+	for _, def := range o.TypeAdd {
+		name, globs, err := lang.ParseTypeDef(def)
+		if err != nil {
+			return err
+		}
+		if err := lang.AddType(name, globs...); err != nil {
+			return err
+		}
+	}
+	if _, err := lang.TypeGlobs(o.Types); err != nil {
+		return err
+	}
+	if _, err := lang.TypeGlobs(o.TypesNot); err != nil {
+		return err
+	}
+
+	if o.Word && o.Ident {
+		return fmt.Errorf("--word and --ident are mutually exclusive")
+	}
+	if o.Semantic && o.Regex {
+		return fmt.Errorf("--semantic and --regex are mutually exclusive")
+	}
+	o.Cursor = strings.TrimSpace(o.Cursor)
+	if o.Cursor != "" {
+		o.Summary = true
+	}
+	if o.Summary && o.Semantic {
+		return fmt.Errorf("--summary and --cursor do not support --semantic")
+	}
+
+	if o.FilesWith && o.FilesWithout {
+		return fmt.Errorf("--files-with-matches and --files-without-match are mutually exclusive")
+	}
+	if (o.FilesWith || o.FilesWithout) && (o.Semantic || o.Summary || o.GroupBy != "") {
+		return fmt.Errorf("--files-with-matches and --files-without-match do not support --semantic, --summary or --group-by")
+	}
+
+	switch o.GroupBy {
+	case "":
+	case "file":
+		if o.VimLines {
+			return fmt.Errorf("--group-by does not support -L")
+		}
+	default:
+		return fmt.Errorf("invalid --group-by %q (expected: file)", o.GroupBy)
+	}
+
+	switch o.Sort {
+	case "score", "path":
+	default:
+		return fmt.Errorf("invalid --sort %q (expected: score|path)", o.Sort)
+	}
+
+	switch o.In {
+	case "all", "code", "comments":
+	default:
+		return fmt.Errorf("invalid --in %q (expected: all|code|comments)", o.In)
+	}
+
+	switch o.Mode {
+	case "text", "hybrid", "symbols", "docs":
+	default:
+		return fmt.Errorf("invalid --mode %q (expected: text|hybrid|symbols|docs)", o.Mode)
+	}
+
+	switch o.Graph {
+	case "", "dot", "json":
+	default:
+		return fmt.Errorf("invalid --graph %q (expected: dot|json)", o.Graph)
+	}
+
 	switch o.Unit {
 	case "line", "block", "symbol", "file":
 	default:
@@ -112,6 +213,42 @@ func (o *Options) normalize() {
 	if o.Unit == "" {
 		o.Unit = defaultUnit()
 	}
+
+	o.Sort = strings.ToLower(strings.TrimSpace(o.Sort))
+	if o.Sort == "" {
+		o.Sort = "score"
+	}
+
+	o.GroupBy = strings.ToLower(strings.TrimSpace(o.GroupBy))
+
+	o.In = strings.ToLower(strings.TrimSpace(o.In))
+	if o.In == "" {
+		o.In = "all"
+	}
+
+	o.Mode = strings.ToLower(strings.TrimSpace(o.Mode))
+	if o.Mode == "" {
+		o.Mode = "text"
+	}
+}
+
+// boundary maps --word/--ident to query.Options.Boundary.
+func (o *Options) boundary() string {
+	switch {
+	case o.Word:
+		return search.BoundaryWord
+	case o.Ident:
+		return search.BoundaryIdent
+	}
+	return ""
+}
+
+// maxPerFile maps --max-per-file (0 = unlimited) to query.Options.MaxPerFile.
+func (o *Options) maxPerFile() int {
+	if o.MaxPerFile == 0 {
+		return -1
+	}
+	return o.MaxPerFile
 }
 
 type optionsKey struct{}
@@ -149,10 +286,31 @@ func bindFlags(cmd *cobra.Command, opts *Options) {
 	cmd.PersistentFlags().BoolVarP(&opts.ScanAll, "all", "A", opts.ScanAll, "scan unwanted and difficult (ALL) files")
 	cmd.PersistentFlags().StringSliceVarP(&opts.ExcludeGlobs, "exclude", "x", nil, "exclude these files (comma separated list: -x *.js,*.sql)")
 	cmd.PersistentFlags().StringSliceVarP(&opts.IncludeGlobs, "glob", "g", nil, "only search these files (can repeat)")
+	cmd.PersistentFlags().StringSliceVarP(&opts.Types, "type", "t", nil, "only search files of these types, e.g. -t go (see otidx types; can repeat)")
+	cmd.PersistentFlags().StringSliceVarP(&opts.TypesNot, "type-not", "T", nil, "skip files of these types (can repeat)")
+	cmd.PersistentFlags().StringArrayVar(&opts.TypeAdd, "type-add", nil, "define a file type for this run: name:glob[,glob] (can repeat)")
 	cmd.PersistentFlags().BoolVarP(&opts.CaseInsensitive, "ignore-case", "i", opts.CaseInsensitive, "case in-sensitive scan")
+	cmd.PersistentFlags().BoolVar(&opts.Regex, "regex", opts.Regex, "treat the query as a Go regular expression (matched per line)")
+	cmd.PersistentFlags().BoolVarP(&opts.Word, "word", "w", opts.Word, "match whole words only")
+	cmd.PersistentFlags().BoolVar(&opts.Ident, "ident", opts.Ident, "match identifier parts: open finds OpenFile and do_open but not reopen")
+	cmd.PersistentFlags().BoolVar(&opts.AllTerms, "all-terms", opts.AllTerms, "require every query term inside each returned unit (e.g. on the same line with --unit line)")
+	cmd.PersistentFlags().BoolVar(&opts.Semantic, "semantic", opts.Semantic, "rank chunks and functions by meaning (needs an index built with --embed)")
+	cmd.PersistentFlags().StringVar(&opts.Sort, "sort", opts.Sort, "result order: score (most relevant first) or path")
+	cmd.PersistentFlags().StringVar(&opts.In, "in", opts.In, "search in: all, code (skip comments) or comments (comments and docstrings)")
+	cmd.PersistentFlags().StringVar(&opts.Mode, "mode", opts.Mode, "retrieval profile: text (chunk text), hybrid (also symbol names, file names, comments), symbols or docs")
+	cmd.PersistentFlags().StringVar(&opts.Kind, "kind", opts.Kind, "only symbols of this kind (sym) or refs of this kind: ref|call|import (refs)")
+	cmd.PersistentFlags().StringVar(&opts.Lang, "lang", opts.Lang, "only files, symbols, refs or calls of this language (q, sym, refs, callers, callees); the grammar of ast queries")
+	cmd.PersistentFlags().IntVar(&opts.Depth, "depth", opts.Depth, "call levels to follow (callers, callees)")
+	cmd.PersistentFlags().StringVar(&opts.Graph, "graph", opts.Graph, "export import edges as a graph: dot or json (deps, rdeps)")
 	cmd.PersistentFlags().IntVarP(&opts.ContextLines, "context", "c", opts.ContextLines, "number of lines of context to display before and after a match, default is 1")
 	cmd.PersistentFlags().IntVar(&opts.Limit, "limit", opts.Limit, "max results to return")
 	cmd.PersistentFlags().IntVar(&opts.Offset, "offset", opts.Offset, "skip first N results")
+	cmd.PersistentFlags().BoolVar(&opts.Summary, "summary", opts.Summary, "report the total, facet counts and next-page cursor (a trailing --jsonl record, else on stderr)")
+	cmd.PersistentFlags().StringVar(&opts.Cursor, "cursor", opts.Cursor, "continue after the page that returned this cursor (implies --summary)")
+	cmd.PersistentFlags().IntVar(&opts.MaxPerFile, "max-per-file", opts.MaxPerFile, "max results per file (0 = unlimited)")
+	cmd.PersistentFlags().StringVar(&opts.GroupBy, "group-by", opts.GroupBy, "group results: file (one header or JSON object per file)")
+	cmd.PersistentFlags().BoolVarP(&opts.FilesWith, "files-with-matches", "l", opts.FilesWith, "only print the paths of files with a match (bare -l without a query lists databases, like --list-databases)")
+	cmd.PersistentFlags().BoolVar(&opts.FilesWithout, "files-without-match", opts.FilesWithout, "only print the paths of indexed files without a match")
 	cmd.PersistentFlags().BoolVar(&opts.Cache, "cache", opts.Cache, "enable query result cache (mostly useful in daemon/interactive mode)")
 	cmd.PersistentFlags().IntVar(&opts.CacheSize, "cache-size", opts.CacheSize, "query cache size (LRU entries)")
 	cmd.PersistentFlags().BoolVar(&opts.Compact, "compact", opts.Compact, "compact one-line output (path:line: snippet)")
@@ -164,7 +322,7 @@ func bindFlags(cmd *cobra.Command, opts *Options) {
 	cmd.PersistentFlags().BoolVarP(&opts.noColor, "no-color", "z", false, "suppress colors")
 	cmd.PersistentFlags().BoolVarP(&opts.highContrast, "high-contrast", "Z", false, "high contrast colors")
 
-	cmd.PersistentFlags().BoolVarP(&opts.ListDatabases, "list-databases", "l", opts.ListDatabases, "lists databases available")
+	cmd.PersistentFlags().BoolVar(&opts.ListDatabases, "list-databases", opts.ListDatabases, "lists databases available")
 
 	cmd.PersistentFlags().StringVar(&opts.Unit, "unit", opts.Unit, "unit granularity: line|block|symbol|file")
 	cmd.PersistentFlags().BoolVar(&opts.Jsonl, "jsonl", opts.Jsonl, "output as JSONL")
@@ -199,10 +357,15 @@ func newDefaultOptions() *Options {
 		Store:        "sqlite",
 		ContextLines: 1,
 		Limit:        20,
+		Depth:        1,
 		Offset:       0,
+		MaxPerFile:   query.DefaultMaxPerFile,
 		Cache:        false,
 		CacheSize:    128,
 		Unit:         defaultUnit(),
+		Sort:         "score",
+		In:           "all",
+		Mode:         "text",
 		Theme:        "default",
 	}
 }
@@ -68,6 +68,24 @@ func TestUnitInvalidIsError(t *testing.T) {
 	}
 }
 
+func TestWordAndIdentAreExclusive(t *testing.T) {
+	cmd := NewRootCommand()
+	cmd.SetArgs([]string{"q", "k", "-w", "--ident"})
+	_, _, err := ExecuteForTest(cmd)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestSemanticAndRegexAreExclusive(t *testing.T) {
+	cmd := NewRootCommand()
+	cmd.SetArgs([]string{"q", "k", "--semantic", "--regex"})
+	_, _, err := ExecuteForTest(cmd)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 func TestExplainNoValueDefaultsToText(t *testing.T) {
 	cmd := NewRootCommand()
 	cmd.SetArgs([]string{"q", "k", "--explain"})
@@ -79,3 +97,53 @@ func TestExplainNoValueDefaultsToText(t *testing.T) {
 		t.Fatalf("Explain=%q", opts.Explain)
 	}
 }
+
+func TestInvalidModeIsRejected(t *testing.T) {
+	cmd := NewRootCommand()
+	cmd.SetArgs([]string{"q", "k", "--mode", "nope"})
+	_, _, err := ExecuteForTest(cmd)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestUnknownTypeIsRejected(t *testing.T) {
+	cmd := NewRootCommand()
+	cmd.SetArgs([]string{"q", "k", "-t", "go", "-T", "nope"})
+	_, _, err := ExecuteForTest(cmd)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestGroupByIsValidated(t *testing.T) {
+	cmd := NewRootCommand()
+	cmd.SetArgs([]string{"q", "k", "--group-by", "dir"})
+	if _, _, err := ExecuteForTest(cmd); err == nil {
+		t.Fatal("expected error")
+	}
+
+	cmd = NewRootCommand()
+	cmd.SetArgs([]string{"q", "k", "--group-by", "file", "-L"})
+	if _, _, err := ExecuteForTest(cmd); err == nil {
+		t.Fatal("expected error for -L")
+	}
+}
+
+func TestFilesWithMatchesFlags(t *testing.T) {
+	cmd := NewRootCommand()
+	cmd.SetArgs([]string{"q", "k", "-l"})
+	_, opts, err := ExecuteForTest(cmd)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if !opts.FilesWith || opts.ListDatabases {
+		t.Fatalf("-l should be --files-with-matches: %+v", opts)
+	}
+
+	cmd = NewRootCommand()
+	cmd.SetArgs([]string{"q", "k", "-l", "--files-without-match"})
+	if _, _, err := ExecuteForTest(cmd); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,63 @@
+package otidxcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"otterindex/internal/core/query"
+)
+
+func newOutlineCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "outline <path>",
+		Short: "Show the symbol tree of an indexed file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isTestMode(cmd) {
+				return nil
+			}
+
+			opts := optionsFrom(cmd)
+			if opts == nil {
+				return fmt.Errorf("options missing")
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			workspaceID, err := filepath.Abs(cwd)
+			if err != nil {
+				return err
+			}
+
+			path := args[0]
+			if filepath.IsAbs(path) {
+				if rel, err := filepath.Rel(workspaceID, path); err == nil {
+					path = rel
+				}
+			}
+			path = filepath.ToSlash(filepath.Clean(path))
+
+			nodes, err := query.Outline(opts.DBPath, workspaceID, path, query.OutlineOptions{Store: opts.Store})
+			if err != nil {
+				return err
+			}
+
+			var out string
+			switch {
+			case opts.Jsonl:
+				out = RenderOutlineJSONL(nodes)
+			case opts.VimLines:
+				out = RenderOutlineVim(nodes)
+			default:
+				out = RenderOutline(nodes)
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+}
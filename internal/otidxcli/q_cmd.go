@@ -57,20 +57,34 @@ func newQCommand() *cobra.Command {
 				ex = NewExplainCollector(ExplainOptions{Format: opts.Explain})
 			}
 
-			qopts := query.Options{
-				Store:           opts.Store,
-				Unit:            opts.Unit,
-				ContextLines:    opts.ContextLines,
-				CaseInsensitive: opts.CaseInsensitive,
-				IncludeGlobs:    opts.IncludeGlobs,
-				ExcludeGlobs:    opts.ExcludeGlobs,
-				Limit:           opts.Limit,
-				Offset:          opts.Offset,
-				Explain:         ex,
+			qopts := opts.queryOptions()
+			qopts.Explain = ex
+
+			if opts.FilesWith || opts.FilesWithout {
+				return printFileMatches(cmd, opts, workspaceID, q, qopts)
 			}
 
+			grouped := opts.GroupBy == "file"
 			var items []ResultItem
-			if opts.Cache {
+			var groups []FileGroup
+			var page *ResultPage
+			if opts.Semantic {
+				items, err = query.Semantic(opts.DBPath, workspaceID, q, query.SemanticOptions{
+					Store:        opts.Store,
+					IncludeGlobs: opts.IncludeGlobs,
+					ExcludeGlobs: opts.ExcludeGlobs,
+					Types:        opts.Types,
+					TypesNot:     opts.TypesNot,
+					Lang:         opts.Lang,
+					Limit:        opts.Limit,
+				})
+			} else if opts.Summary {
+				var p ResultPage
+				p, err = query.QueryPage(nil, opts.DBPath, workspaceID, q, qopts, opts.Cursor)
+				items, page = p.Items, &p
+			} else if grouped {
+				groups, err = query.QueryGroups(opts.DBPath, workspaceID, q, qopts)
+			} else if opts.Cache {
 				cache := query.NewQueryCache(opts.CacheSize)
 
 				st, err := backend.Open(opts.Store, opts.DBPath)
@@ -92,6 +106,13 @@ func newQCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if grouped && groups == nil {
+				var truncated map[string]int
+				if page != nil {
+					truncated = page.Truncated
+				}
+				groups = query.GroupByFile(items, truncated)
+			}
 
 			if ex != nil {
 				_ = ex.Emit(cmd.ErrOrStderr())
@@ -103,21 +124,90 @@ func newQCommand() *cobra.Command {
 			}
 
 			switch {
+			case opts.Jsonl && grouped:
+				if opts.Show {
+					for _, g := range groups {
+						AttachText(workspaceRoot, g.Items)
+					}
+				}
+				out = RenderGroupsJSONL(groups)
+				if page != nil {
+					out += RenderSummaryJSONL(*page)
+				}
 			case opts.Jsonl:
 				if opts.Show {
 					AttachText(workspaceRoot, items)
 				}
 				out = RenderJSONL(items)
+				if page != nil {
+					out += RenderSummaryJSONL(*page)
+				}
 			case opts.VimLines:
 				out = RenderVim(items)
+			case opts.Compact && grouped:
+				out = RenderGroups(groups)
 			case opts.Compact:
 				out = RenderDefault(items)
+			case grouped:
+				out = RenderShowGroups(workspaceRoot, groups)
 			default:
 				out = RenderShow(workspaceRoot, items)
 			}
 
 			_, _ = fmt.Fprint(cmd.OutOrStdout(), out)
+			if page != nil && !opts.Jsonl {
+				_, _ = fmt.Fprint(cmd.ErrOrStderr(), RenderSummary(*page))
+			}
 			return nil
 		},
 	}
 }
+
+// queryOptions maps the query flags onto query.Options.
+func (o *Options) queryOptions() query.Options {
+	return query.Options{
+		Store:           o.Store,
+		Unit:            o.Unit,
+		ContextLines:    o.ContextLines,
+		CaseInsensitive: o.CaseInsensitive,
+		IncludeGlobs:    o.IncludeGlobs,
+		ExcludeGlobs:    o.ExcludeGlobs,
+		Limit:           o.Limit,
+		Offset:          o.Offset,
+		Regex:           o.Regex,
+		Sort:            o.Sort,
+		In:              o.In,
+		Boundary:        o.boundary(),
+		AllTerms:        o.AllTerms,
+		Mode:            o.Mode,
+		Types:           o.Types,
+		TypesNot:        o.TypesNot,
+		Lang:            o.Lang,
+		MaxPerFile:      o.maxPerFile(),
+	}
+}
+
+// printFileMatches prints the files with (-l) or without a match of q.
+func printFileMatches(cmd *cobra.Command, opts *Options, workspaceID string, q string, qopts query.Options) error {
+	var paths []string
+	if opts.FilesWithout {
+		var err error
+		if paths, err = query.FilesWithoutMatch(opts.DBPath, workspaceID, q, qopts); err != nil {
+			return err
+		}
+	} else {
+		res, err := query.Count(opts.DBPath, workspaceID, q, qopts)
+		if err != nil {
+			return err
+		}
+		for _, f := range res.Files {
+			paths = append(paths, f.Path)
+		}
+	}
+	if opts.Jsonl {
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), RenderPathsJSONL(paths))
+		return nil
+	}
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), RenderPaths(paths))
+	return nil
+}
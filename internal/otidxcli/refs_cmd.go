@@ -0,0 +1,60 @@
+package otidxcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"otterindex/internal/core/query"
+)
+
+func newRefsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "refs <name>",
+		Short: "List references, calls and imports of a name",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isTestMode(cmd) {
+				return nil
+			}
+
+			opts := optionsFrom(cmd)
+			if opts == nil {
+				return fmt.Errorf("options missing")
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			workspaceID, err := filepath.Abs(cwd)
+			if err != nil {
+				return err
+			}
+
+			refs, err := query.FindRefs(opts.DBPath, workspaceID, args[0], query.RefOptions{
+				Store: opts.Store,
+				Kind:  opts.Kind,
+				Lang:  opts.Lang,
+				Limit: opts.Limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			var out string
+			switch {
+			case opts.Jsonl:
+				out = RenderRefsJSONL(refs)
+			case opts.VimLines:
+				out = RenderRefsVim(refs)
+			default:
+				out = RenderRefs(refs)
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+}
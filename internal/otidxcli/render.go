@@ -3,7 +3,10 @@ package otidxcli
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+
+	"otterindex/internal/core/lang"
 )
 
 func RenderJSONL(items []ResultItem) string {
@@ -15,6 +18,48 @@ func RenderJSONL(items []ResultItem) string {
 	return b.String()
 }
 
+// RenderSummaryJSONL writes the trailing --summary record of a page.
+func RenderSummaryJSONL(page ResultPage) string {
+	var b strings.Builder
+	_ = json.NewEncoder(&b).Encode(struct {
+		Kind       string `json:"kind"`
+		Total      int    `json:"total"`
+		TotalExact bool   `json:"total_exact"`
+		Returned   int    `json:"returned"`
+		Facets     Facets `json:"facets"`
+		Cursor     string `json:"cursor,omitempty"`
+	}{"summary", page.Total, page.TotalExact, len(page.Items), page.Facets, page.Cursor})
+	return b.String()
+}
+
+// RenderSummary describes a page for humans: the total, one line per facet
+// and how to get the next page.
+func RenderSummary(page ResultPage) string {
+	var b strings.Builder
+	total := fmt.Sprint(page.Total)
+	if !page.TotalExact {
+		total = "at least " + total
+	}
+	_, _ = fmt.Fprintf(&b, "%s results, %d shown\n", total, len(page.Items))
+	facet := func(name string, counts []FacetCount) {
+		if len(counts) == 0 {
+			return
+		}
+		parts := make([]string, 0, len(counts))
+		for _, c := range counts {
+			parts = append(parts, fmt.Sprintf("%s %d", c.Value, c.Count))
+		}
+		_, _ = fmt.Fprintf(&b, "%s: %s\n", name, strings.Join(parts, ", "))
+	}
+	facet("langs", page.Facets.Langs)
+	facet("exts", page.Facets.Exts)
+	facet("dirs", page.Facets.Dirs)
+	if page.Cursor != "" {
+		_, _ = fmt.Fprintf(&b, "next page: --cursor %s\n", page.Cursor)
+	}
+	return b.String()
+}
+
 func RenderDefault(items []ResultItem) string {
 	var b strings.Builder
 	for _, item := range items {
@@ -24,6 +69,33 @@ func RenderDefault(items []ResultItem) string {
 	return b.String()
 }
 
+// RenderGroupsJSONL writes one JSON object per file: its path, its items and
+// the truncated count.
+func RenderGroupsJSONL(groups []FileGroup) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, g := range groups {
+		_ = enc.Encode(g)
+	}
+	return b.String()
+}
+
+// RenderGroups is the --compact form of grouped results.
+func RenderGroups(groups []FileGroup) string {
+	var b strings.Builder
+	for _, g := range groups {
+		_, _ = fmt.Fprintf(&b, "%s\n", g.Path)
+		for _, item := range g.Items {
+			line, snippet := bestLocationAndSnippet(item)
+			_, _ = fmt.Fprintf(&b, "  %d: %s\n", line, snippet)
+		}
+		if g.Truncated > 0 {
+			_, _ = fmt.Fprintf(&b, "  ... %d more\n", g.Truncated)
+		}
+	}
+	return b.String()
+}
+
 func RenderVim(items []ResultItem) string {
 	var b strings.Builder
 	for _, item := range items {
@@ -70,3 +142,358 @@ func bestVimLocationAndSnippet(item ResultItem) (line int, col int, snippet stri
 	return line, col, strings.TrimSpace(item.Title)
 }
 
+
+func RenderSymbolsJSONL(syms []SymbolItem) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, sym := range syms {
+		_ = enc.Encode(sym)
+	}
+	return b.String()
+}
+
+func RenderSymbols(syms []SymbolItem) string {
+	var b strings.Builder
+	for _, sym := range syms {
+		_, _ = fmt.Fprintf(&b, "%s:%d: %s\n", sym.Path, sym.Range.SL, symbolLabel(sym))
+	}
+	return b.String()
+}
+
+func RenderSymbolsVim(syms []SymbolItem) string {
+	var b strings.Builder
+	for _, sym := range syms {
+		col := sym.Range.SC
+		if col <= 0 {
+			col = 1
+		}
+		_, _ = fmt.Fprintf(&b, "%s:%d:%d: %s\n", sym.Path, sym.Range.SL, col, symbolLabel(sym))
+	}
+	return b.String()
+}
+
+func RenderRefsJSONL(refs []RefItem) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, r := range refs {
+		_ = enc.Encode(r)
+	}
+	return b.String()
+}
+
+func RenderRefs(refs []RefItem) string {
+	var b strings.Builder
+	for _, r := range refs {
+		_, _ = fmt.Fprintf(&b, "%s:%d: %s\n", r.Path, r.Range.SL, refLabel(r))
+	}
+	return b.String()
+}
+
+func RenderRefsVim(refs []RefItem) string {
+	var b strings.Builder
+	for _, r := range refs {
+		col := r.Range.SC
+		if col <= 0 {
+			col = 1
+		}
+		_, _ = fmt.Fprintf(&b, "%s:%d:%d: %s\n", r.Path, r.Range.SL, col, refLabel(r))
+	}
+	return b.String()
+}
+
+// refLabel is the source line followed by "[kind in Container.Name]".
+func refLabel(r RefItem) string {
+	text := r.Text
+	if text == "" {
+		text = r.Name
+	}
+	where := r.Kind
+	if r.Symbol != nil {
+		name := r.Symbol.Name
+		if r.Symbol.Container != "" {
+			name = r.Symbol.Container + "." + name
+		}
+		where += " in " + name
+	}
+	return text + "  [" + where + "]"
+}
+
+func RenderCallEdgesJSONL(edges []CallEdge) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, e := range edges {
+		_ = enc.Encode(e)
+	}
+	return b.String()
+}
+
+// RenderCallEdges prints one "caller -> callee" line per call site, indented
+// by call depth.
+func RenderCallEdges(edges []CallEdge) string {
+	var b strings.Builder
+	for _, e := range edges {
+		_, _ = fmt.Fprintf(&b, "%s:%d: %s%s\n", e.Path, e.Range.SL, callIndent(e), callLabel(e))
+	}
+	return b.String()
+}
+
+func RenderCallEdgesVim(edges []CallEdge) string {
+	var b strings.Builder
+	for _, e := range edges {
+		col := e.Range.SC
+		if col <= 0 {
+			col = 1
+		}
+		_, _ = fmt.Fprintf(&b, "%s:%d:%d: %s%s\n", e.Path, e.Range.SL, col, callIndent(e), callLabel(e))
+	}
+	return b.String()
+}
+
+func callIndent(e CallEdge) string {
+	if e.Depth <= 1 {
+		return ""
+	}
+	return strings.Repeat("  ", e.Depth-1)
+}
+
+func callLabel(e CallEdge) string {
+	caller := "(top level)"
+	if e.Caller != nil {
+		caller = e.Caller.Name
+		if e.Caller.Container != "" {
+			caller = e.Caller.Container + "." + caller
+		}
+	}
+	return caller + " -> " + e.Callee
+}
+
+func RenderDepsJSONL(edges []DepEdge) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, e := range edges {
+		_ = enc.Encode(e)
+	}
+	return b.String()
+}
+
+func RenderDeps(edges []DepEdge) string {
+	var b strings.Builder
+	for _, e := range edges {
+		_, _ = fmt.Fprintf(&b, "%s:%d: %s\n", e.Path, e.Range.SL, e.Module)
+	}
+	return b.String()
+}
+
+func RenderDepsVim(edges []DepEdge) string {
+	var b strings.Builder
+	for _, e := range edges {
+		col := e.Range.SC
+		if col <= 0 {
+			col = 1
+		}
+		_, _ = fmt.Fprintf(&b, "%s:%d:%d: %s\n", e.Path, e.Range.SL, col, e.Module)
+	}
+	return b.String()
+}
+
+// RenderDepsDOT renders file -> module edges as a Graphviz digraph.
+func RenderDepsDOT(edges []DepEdge) string {
+	var b strings.Builder
+	b.WriteString("digraph deps {\n")
+	for _, e := range edges {
+		_, _ = fmt.Fprintf(&b, "  %s -> %s;\n", strconv.Quote(e.Path), strconv.Quote(e.Module))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+type depGraphNode struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+}
+
+type depGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RenderDepsGraphJSON renders edges as one {"nodes":[...],"edges":[...]}
+// document. Nodes are importing files (kind "file") and imported modules
+// (kind "module").
+func RenderDepsGraphJSON(edges []DepEdge) string {
+	graph := struct {
+		Nodes []depGraphNode `json:"nodes"`
+		Edges []depGraphEdge `json:"edges"`
+	}{Nodes: []depGraphNode{}, Edges: []depGraphEdge{}}
+
+	files := map[string]bool{}
+	for _, e := range edges {
+		files[e.Path] = true
+	}
+	seen := map[string]bool{}
+	addNode := func(id string, kind string) {
+		if !seen[id] {
+			seen[id] = true
+			graph.Nodes = append(graph.Nodes, depGraphNode{ID: id, Kind: kind})
+		}
+	}
+	for _, e := range edges {
+		addNode(e.Path, "file")
+		if files[e.Module] {
+			addNode(e.Module, "file")
+		} else {
+			addNode(e.Module, "module")
+		}
+		graph.Edges = append(graph.Edges, depGraphEdge{From: e.Path, To: e.Module})
+	}
+
+	b, _ := json.Marshal(graph)
+	return string(b) + "\n"
+}
+
+func RenderFilesJSONL(files []FileItem) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, f := range files {
+		_ = enc.Encode(f)
+	}
+	return b.String()
+}
+
+func RenderFiles(files []FileItem) string {
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString(f.Path)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func RenderPathsJSONL(paths []string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, p := range paths {
+		_ = enc.Encode(struct {
+			Path string `json:"path"`
+		}{p})
+	}
+	return b.String()
+}
+
+func RenderPaths(paths []string) string {
+	var b strings.Builder
+	for _, p := range paths {
+		b.WriteString(p)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// RenderCountJSONL writes one {"path","count"} record per file and a trailing
+// {"kind":"total"} record.
+func RenderCountJSONL(res CountResult) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, f := range res.Files {
+		_ = enc.Encode(f)
+	}
+	_ = enc.Encode(struct {
+		Kind  string `json:"kind"`
+		Total int    `json:"total"`
+		Files int    `json:"files"`
+	}{"total", res.Total, len(res.Files)})
+	return b.String()
+}
+
+// RenderCount writes path:count lines (like grep -c) and the total.
+func RenderCount(res CountResult) string {
+	var b strings.Builder
+	for _, f := range res.Files {
+		_, _ = fmt.Fprintf(&b, "%s:%d\n", f.Path, f.Count)
+	}
+	_, _ = fmt.Fprintf(&b, "%d matching lines in %d files\n", res.Total, len(res.Files))
+	return b.String()
+}
+
+func RenderTypesJSONL(types []lang.Type) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, t := range types {
+		_ = enc.Encode(struct {
+			Name    string   `json:"name"`
+			Aliases []string `json:"aliases,omitempty"`
+			Globs   []string `json:"globs"`
+		}{t.Name, t.Aliases, t.Globs})
+	}
+	return b.String()
+}
+
+// RenderTypes writes one type per line: "name (aliases): glob, glob".
+func RenderTypes(types []lang.Type) string {
+	var b strings.Builder
+	for _, t := range types {
+		b.WriteString(t.Name)
+		if len(t.Aliases) > 0 {
+			_, _ = fmt.Fprintf(&b, " (%s)", strings.Join(t.Aliases, ", "))
+		}
+		_, _ = fmt.Fprintf(&b, ": %s\n", strings.Join(t.Globs, ", "))
+	}
+	return b.String()
+}
+
+// RenderOutlineJSONL writes one top-level symbol per line, children nested.
+func RenderOutlineJSONL(nodes []OutlineNode) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, n := range nodes {
+		_ = enc.Encode(n)
+	}
+	return b.String()
+}
+
+func RenderOutline(nodes []OutlineNode) string {
+	var b strings.Builder
+	walkOutline(nodes, 0, func(n OutlineNode, depth int) {
+		_, _ = fmt.Fprintf(&b, "%s:%d-%d: %s%s\n", n.Path, n.Range.SL, n.Range.EL, strings.Repeat("  ", depth), outlineLabel(n))
+	})
+	return b.String()
+}
+
+func RenderOutlineVim(nodes []OutlineNode) string {
+	var b strings.Builder
+	walkOutline(nodes, 0, func(n OutlineNode, depth int) {
+		col := n.Range.SC
+		if col <= 0 {
+			col = 1
+		}
+		_, _ = fmt.Fprintf(&b, "%s:%d:%d: %s%s\n", n.Path, n.Range.SL, col, strings.Repeat("  ", depth), outlineLabel(n))
+	})
+	return b.String()
+}
+
+func walkOutline(nodes []OutlineNode, depth int, fn func(OutlineNode, int)) {
+	for _, n := range nodes {
+		fn(n, depth)
+		walkOutline(n.Children, depth+1, fn)
+	}
+}
+
+func outlineLabel(n OutlineNode) string {
+	label := n.Kind + " " + n.Name
+	if sig := strings.TrimSpace(n.Signature); sig != "" && sig != n.Name {
+		label += "  " + sig
+	}
+	return label
+}
+
+func symbolLabel(sym SymbolItem) string {
+	name := sym.Name
+	if sym.Container != "" {
+		name = sym.Container + "." + name
+	}
+	label := sym.Kind + " " + name
+	if sig := strings.TrimSpace(sym.Signature); sig != "" && sig != sym.Name {
+		label += "  " + sig
+	}
+	return label
+}
@@ -58,3 +58,80 @@ func TestRenderDefault_FallbackToRangeAndTitle(t *testing.T) {
 		t.Fatalf("RenderDefault=%q", s)
 	}
 }
+
+func TestRenderSymbols(t *testing.T) {
+	syms := []SymbolItem{
+		{Kind: "method", Name: "Prepare", Container: "Options", Path: "a.go", Range: Range{SL: 3, SC: 1}, Signature: "func (o *Options) Prepare() error"},
+		{Kind: "function", Name: "run", Path: "b.go", Range: Range{SL: 7, SC: 2}},
+	}
+	if s := RenderSymbols(syms); s != "a.go:3: method Options.Prepare  func (o *Options) Prepare() error\nb.go:7: function run\n" {
+		t.Fatalf("RenderSymbols=%q", s)
+	}
+	if s := RenderSymbolsVim(syms[1:]); s != "b.go:7:2: function run\n" {
+		t.Fatalf("RenderSymbolsVim=%q", s)
+	}
+}
+
+func TestRenderOutline(t *testing.T) {
+	nodes := []OutlineNode{
+		{
+			SymbolItem: SymbolItem{Kind: "type", Name: "Server", Path: "a.go", Range: Range{SL: 3, SC: 6, EL: 6}},
+			Children: []OutlineNode{
+				{SymbolItem: SymbolItem{Kind: "method", Name: "Start", Container: "Server", Path: "a.go", Range: Range{SL: 8, EL: 10}}},
+			},
+		},
+	}
+	if s := RenderOutline(nodes); s != "a.go:3-6: type Server\na.go:8-10:   method Start\n" {
+		t.Fatalf("RenderOutline=%q", s)
+	}
+	if s := RenderOutlineVim(nodes); s != "a.go:3:6: type Server\na.go:8:1:   method Start\n" {
+		t.Fatalf("RenderOutlineVim=%q", s)
+	}
+	if s := RenderOutlineJSONL(nodes); strings.Count(s, "\n") != 1 || !strings.Contains(s, `"children":[{`) {
+		t.Fatalf("RenderOutlineJSONL=%q", s)
+	}
+}
+
+func TestRenderRefs(t *testing.T) {
+	refs := []RefItem{
+		{Kind: "call", Name: "Prepare", Path: "a.go", Range: Range{SL: 9, SC: 5}, Text: "o.Prepare()", Symbol: &SymbolItem{Name: "Run", Container: "Cmd"}},
+		{Kind: "import", Name: "os/exec", Path: "b.go", Range: Range{SL: 3}},
+	}
+	if s := RenderRefs(refs); s != "a.go:9: o.Prepare()  [call in Cmd.Run]\nb.go:3: os/exec  [import]\n" {
+		t.Fatalf("RenderRefs=%q", s)
+	}
+	if s := RenderRefsVim(refs[1:]); s != "b.go:3:1: os/exec  [import]\n" {
+		t.Fatalf("RenderRefsVim=%q", s)
+	}
+}
+
+func TestRenderCallEdges(t *testing.T) {
+	edges := []CallEdge{
+		{Caller: &SymbolItem{Name: "hello"}, Callee: "greet", Path: "a.go", Range: Range{SL: 8, SC: 2}, Depth: 1},
+		{Caller: &SymbolItem{Name: "Run", Container: "*S"}, Callee: "hello", Path: "a.go", Range: Range{SL: 5, SC: 21}, Depth: 2},
+		{Callee: "main", Path: "b.go", Range: Range{SL: 1}, Depth: 1},
+	}
+	if s := RenderCallEdges(edges); s != "a.go:8: hello -> greet\na.go:5:   *S.Run -> hello\nb.go:1: (top level) -> main\n" {
+		t.Fatalf("RenderCallEdges=%q", s)
+	}
+	if s := RenderCallEdgesVim(edges[1:2]); s != "a.go:5:21:   *S.Run -> hello\n" {
+		t.Fatalf("RenderCallEdgesVim=%q", s)
+	}
+}
+
+func TestRenderDepsGraph(t *testing.T) {
+	edges := []DepEdge{
+		{Path: "a.go", Module: "fmt", Range: Range{SL: 3}},
+		{Path: "b.c", Module: "a.go", Range: Range{SL: 1}},
+	}
+	if s := RenderDeps(edges[:1]); s != "a.go:3: fmt\n" {
+		t.Fatalf("RenderDeps=%q", s)
+	}
+	if s := RenderDepsDOT(edges); s != "digraph deps {\n  \"a.go\" -> \"fmt\";\n  \"b.c\" -> \"a.go\";\n}\n" {
+		t.Fatalf("RenderDepsDOT=%q", s)
+	}
+	want := `{"nodes":[{"id":"a.go","kind":"file"},{"id":"fmt","kind":"module"},{"id":"b.c","kind":"file"}],"edges":[{"from":"a.go","to":"fmt"},{"from":"b.c","to":"a.go"}]}` + "\n"
+	if s := RenderDepsGraphJSON(edges); s != want {
+		t.Fatalf("RenderDepsGraphJSON=%q", s)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"otterindex/internal/core/config"
 	"otterindex/internal/version"
 )
 
@@ -26,7 +27,9 @@ func NewRootCommand() *cobra.Command {
 				return fmt.Errorf("options missing")
 			}
 
-			if opts.ListDatabases {
+			// -l used to be --list-databases; without a query it still is
+			// (see the --files-with-matches help).
+			if opts.ListDatabases || opts.FilesWith {
 				return listDatabases(cmd)
 			}
 			if opts.Viz != "" {
@@ -48,6 +51,11 @@ func NewRootCommand() *cobra.Command {
 	bindFlags(cmd, opts)
 
 	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if !isTestMode(cmd) {
+			if err := config.LoadAndApply(); err != nil {
+				return err
+			}
+		}
 		if opts := optionsFrom(cmd); opts != nil {
 			return opts.Prepare()
 		}
@@ -56,6 +64,17 @@ func NewRootCommand() *cobra.Command {
 
 	cmd.AddCommand(newIndexCommand())
 	cmd.AddCommand(newQCommand())
+	cmd.AddCommand(newCountCommand())
+	cmd.AddCommand(newSymCommand())
+	cmd.AddCommand(newOutlineCommand())
+	cmd.AddCommand(newRefsCommand())
+	cmd.AddCommand(newCallersCommand())
+	cmd.AddCommand(newCalleesCommand())
+	cmd.AddCommand(newDepsCommand())
+	cmd.AddCommand(newRDepsCommand())
+	cmd.AddCommand(newFilesCommand())
+	cmd.AddCommand(newASTCommand())
+	cmd.AddCommand(newTypesCommand())
 	return cmd
 }
 
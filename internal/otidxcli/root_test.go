@@ -2,6 +2,8 @@ package otidxcli
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -21,3 +23,24 @@ func TestHelpContainsSubcommand(t *testing.T) {
 	}
 }
 
+
+func TestBareLListsDatabases(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, ".otidx"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, ".otidx", "a.db"), nil, 0o644)
+	t.Chdir(dir)
+
+	for _, args := range [][]string{{"-l"}, {"--list-databases"}} {
+		cmd := NewRootCommand()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(RewriteArgsForImplicitQ(cmd, args))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		if got := out.String(); got != ".otidx/a.db\n" {
+			t.Fatalf("%v: got %q", args, got)
+		}
+	}
+}
@@ -19,44 +19,75 @@ func RenderShow(workspaceRoot string, items []ResultItem) string {
 	seen := map[string]bool{}
 
 	for _, item := range items {
-		key := fmt.Sprintf("%s:%d:%d", item.Path, item.Range.SL, item.Range.EL)
-		if seen[key] {
-			continue
-		}
-		seen[key] = true
+		writeShowUnit(&b, base, item, item.Path+":", "", fileCache, seen)
+	}
 
-		lines := loadFileLines(base, item.Path, fileCache)
-		if len(lines) == 0 {
-			line, col, _ := bestVimLocationAndSnippet(item)
-			_, _ = fmt.Fprintf(&b, "%s:%d:%d (%d-%d)\n\n", item.Path, line, col, item.Range.SL, item.Range.EL)
-			continue
-		}
+	return b.String()
+}
 
-		sl := clampInt(item.Range.SL, 1, len(lines))
-		el := clampInt(item.Range.EL, sl, len(lines))
+// RenderShowGroups renders grouped results: each file's path once, its units
+// indented below it and a note when the per-file cap dropped further hits.
+func RenderShowGroups(workspaceRoot string, groups []FileGroup) string {
+	base := strings.TrimSpace(workspaceRoot)
+	if base == "" {
+		base = "."
+	}
 
-		line, col, _ := bestVimLocationAndSnippet(item)
-		_, _ = fmt.Fprintf(&b, "%s:%d:%d (%d-%d)\n", item.Path, line, col, sl, el)
+	var b strings.Builder
+	fileCache := map[string][]string{}
+	seen := map[string]bool{}
 
-		width := len(strconv.Itoa(el))
-		matchLines := map[int]bool{}
-		for _, m := range item.Matches {
-			matchLines[m.Line] = true
+	for _, g := range groups {
+		_, _ = fmt.Fprintf(&b, "%s\n", g.Path)
+		for _, item := range g.Items {
+			writeShowUnit(&b, base, item, "", "  ", fileCache, seen)
 		}
-
-		for i := sl; i <= el; i++ {
-			prefix := " "
-			if matchLines[i] {
-				prefix = ">"
-			}
-			_, _ = fmt.Fprintf(&b, "%s %*d| %s\n", prefix, width, i, lines[i-1])
+		if g.Truncated > 0 {
+			_, _ = fmt.Fprintf(&b, "  ... %d more in this file (--max-per-file)\n\n", g.Truncated)
 		}
-		_, _ = fmt.Fprintln(&b)
 	}
 
 	return b.String()
 }
 
+// writeShowUnit writes one unit: a location line (after prefix) and the unit
+// source with match lines marked, each line indented by indent.
+func writeShowUnit(b *strings.Builder, base string, item ResultItem, prefix string, indent string, fileCache map[string][]string, seen map[string]bool) {
+	key := fmt.Sprintf("%s:%d:%d", item.Path, item.Range.SL, item.Range.EL)
+	if seen[key] {
+		return
+	}
+	seen[key] = true
+
+	lines := loadFileLines(base, item.Path, fileCache)
+	if len(lines) == 0 {
+		line, col, _ := bestVimLocationAndSnippet(item)
+		_, _ = fmt.Fprintf(b, "%s%s%d:%d (%d-%d)\n\n", indent, prefix, line, col, item.Range.SL, item.Range.EL)
+		return
+	}
+
+	sl := clampInt(item.Range.SL, 1, len(lines))
+	el := clampInt(item.Range.EL, sl, len(lines))
+
+	line, col, _ := bestVimLocationAndSnippet(item)
+	_, _ = fmt.Fprintf(b, "%s%s%d:%d (%d-%d)\n", indent, prefix, line, col, sl, el)
+
+	width := len(strconv.Itoa(el))
+	matchLines := map[int]bool{}
+	for _, m := range item.Matches {
+		matchLines[m.Line] = true
+	}
+
+	for i := sl; i <= el; i++ {
+		mark := " "
+		if matchLines[i] {
+			mark = ">"
+		}
+		_, _ = fmt.Fprintf(b, "%s%s %*d| %s\n", indent, mark, width, i, lines[i-1])
+	}
+	_, _ = fmt.Fprintln(b)
+}
+
 func AttachText(workspaceRoot string, items []ResultItem) {
 	base := strings.TrimSpace(workspaceRoot)
 	if base == "" {
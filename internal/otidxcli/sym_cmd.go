@@ -0,0 +1,61 @@
+package otidxcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"otterindex/internal/core/query"
+)
+
+func newSymCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sym <name>",
+		Short: "Search symbols by name (exact, prefix, camelCase, fuzzy)",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isTestMode(cmd) {
+				return nil
+			}
+
+			opts := optionsFrom(cmd)
+			if opts == nil {
+				return fmt.Errorf("options missing")
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			workspaceID, err := filepath.Abs(cwd)
+			if err != nil {
+				return err
+			}
+
+			syms, err := query.SearchSymbols(opts.DBPath, workspaceID, strings.Join(args, " "), query.SymbolOptions{
+				Store: opts.Store,
+				Kind:  opts.Kind,
+				Lang:  opts.Lang,
+				Limit: opts.Limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			var out string
+			switch {
+			case opts.Jsonl:
+				out = RenderSymbolsJSONL(syms)
+			case opts.VimLines:
+				out = RenderSymbolsVim(syms)
+			default:
+				out = RenderSymbols(syms)
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+}
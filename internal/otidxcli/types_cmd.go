@@ -0,0 +1,35 @@
+package otidxcli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"otterindex/internal/core/lang"
+)
+
+func newTypesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "types",
+		Short: "List the file types usable with -t/--type and -T/--type-not",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isTestMode(cmd) {
+				return nil
+			}
+
+			opts := optionsFrom(cmd)
+			if opts == nil {
+				return fmt.Errorf("options missing")
+			}
+
+			types := lang.Types()
+			if opts.Jsonl {
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), RenderTypesJSONL(types))
+				return nil
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), RenderTypes(types))
+			return nil
+		},
+	}
+}
@@ -131,3 +131,35 @@ func (c *Client) Query(p QueryParams) ([]model.ResultItem, error) {
 	}
 	return out, nil
 }
+
+func (c *Client) QueryPage(p QueryPageParams) (model.ResultPage, error) {
+	var out model.ResultPage
+	if err := c.call("query.page", p, &out); err != nil {
+		return model.ResultPage{}, err
+	}
+	return out, nil
+}
+
+func (c *Client) QueryCount(p QueryParams) (model.CountResult, error) {
+	var out model.CountResult
+	if err := c.call("query.count", p, &out); err != nil {
+		return model.CountResult{}, err
+	}
+	return out, nil
+}
+
+func (c *Client) QueryFiles(p QueryFilesParams) ([]string, error) {
+	var out []string
+	if err := c.call("query.files", p, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) QueryGroups(p QueryParams) ([]model.FileGroup, error) {
+	var out []model.FileGroup
+	if err := c.call("query.groups", p, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
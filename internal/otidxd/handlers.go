@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"otterindex/internal/core/embed"
 	"otterindex/internal/core/indexer"
 	"otterindex/internal/core/query"
 	"otterindex/internal/core/walk"
@@ -32,6 +33,7 @@ type Handlers struct {
 	mu         sync.RWMutex
 	workspaces map[string]workspaceInfo
 	cache      *query.QueryCache
+	pages      *query.PageCache
 	session    *query.SessionStore
 	watchers   map[string]*watcherEntry
 }
@@ -40,6 +42,7 @@ func NewHandlers() *Handlers {
 	return &Handlers{
 		workspaces: map[string]workspaceInfo{},
 		cache:      query.NewQueryCache(128),
+		pages:      query.NewPageCache(32),
 		session:    query.NewSessionStore(query.SessionOptions{TTL: 30 * time.Second}),
 		watchers:   map[string]*watcherEntry{},
 	}
@@ -103,12 +106,26 @@ func (h *Handlers) IndexBuild(p IndexBuildParams) (any, error) {
 		return nil, fmt.Errorf("workspace not found")
 	}
 
+	var embedder embed.Embedder
+	if strings.TrimSpace(p.Embed) != "" {
+		e, ok := embed.Lookup(p.Embed)
+		if !ok {
+			return nil, fmt.Errorf("unknown embedder %q", p.Embed)
+		}
+		embedder = e
+	}
+
 	err := indexer.Build(ws.root, ws.dbPath, indexer.Options{
 		Store:        ws.store,
 		WorkspaceID:  p.WorkspaceID,
 		ScanAll:      p.ScanAll,
 		IncludeGlobs: p.IncludeGlobs,
 		ExcludeGlobs: p.ExcludeGlobs,
+		Types:        p.Types,
+		TypesNot:     p.TypesNot,
+		Embedder:     embedder,
+		Full:         p.Full,
+		Chunking:     p.Chunking,
 	})
 	if err != nil {
 		return nil, err
@@ -136,28 +153,7 @@ func (h *Handlers) Query(p QueryParams) ([]model.ResultItem, error) {
 		return nil, fmt.Errorf("workspace not found")
 	}
 
-	opts := query.Options{
-		Store:           ws.store,
-		Unit:            p.Unit,
-		ContextLines:    p.ContextLines,
-		CaseInsensitive: p.CaseInsensitive,
-		IncludeGlobs:    p.IncludeGlobs,
-		ExcludeGlobs:    p.ExcludeGlobs,
-		Limit:           p.Limit,
-		Offset:          p.Offset,
-	}
-
-	// Normalize to match query.Query defaults so the cache key matches actual behavior.
-	opts.Unit = strings.TrimSpace(opts.Unit)
-	if opts.Unit == "" {
-		opts.Unit = "block"
-	}
-	if opts.Limit <= 0 {
-		opts.Limit = 20
-	}
-	if opts.ContextLines < 0 {
-		opts.ContextLines = 0
-	}
+	opts := queryOptions(ws, p)
 
 	if h.cache == nil && h.session == nil {
 		return query.Query(ws.dbPath, p.WorkspaceID, p.Q, opts)
@@ -200,6 +196,211 @@ func (h *Handlers) Query(p QueryParams) ([]model.ResultItem, error) {
 	return items, nil
 }
 
+// queryOptions maps query params onto query.Options, normalized to match
+// query.Query defaults so cache keys match actual behavior.
+func queryOptions(ws workspaceInfo, p QueryParams) query.Options {
+	opts := query.Options{
+		Store:           ws.store,
+		Unit:            p.Unit,
+		ContextLines:    p.ContextLines,
+		CaseInsensitive: p.CaseInsensitive,
+		IncludeGlobs:    p.IncludeGlobs,
+		ExcludeGlobs:    p.ExcludeGlobs,
+		Limit:           p.Limit,
+		Offset:          p.Offset,
+		Regex:           p.Regex,
+		Sort:            p.Sort,
+		In:              p.In,
+		Boundary:        p.Boundary,
+		AllTerms:        p.AllTerms,
+		Mode:            p.Mode,
+		Weights:         p.Weights,
+		Types:           p.Types,
+		TypesNot:        p.TypesNot,
+		Paths:           p.Paths,
+		Lang:            p.Lang,
+	}
+
+	opts.Unit = strings.TrimSpace(opts.Unit)
+	if opts.Unit == "" {
+		opts.Unit = "block"
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+	if opts.ContextLines < 0 {
+		opts.ContextLines = 0
+	}
+	opts.Boundary = strings.ToLower(strings.TrimSpace(opts.Boundary))
+	if p.MaxPerFile != nil {
+		opts.MaxPerFile = *p.MaxPerFile
+		if opts.MaxPerFile == 0 {
+			opts.MaxPerFile = -1
+		}
+	}
+	return opts
+}
+
+func (h *Handlers) QueryPage(p QueryPageParams) (model.ResultPage, error) {
+	if h == nil {
+		return model.ResultPage{}, fmt.Errorf("handlers is nil")
+	}
+
+	ws, ok := h.getWorkspace(p.WorkspaceID)
+	if !ok {
+		return model.ResultPage{}, fmt.Errorf("workspace not found")
+	}
+	page, err := query.QueryPage(h.pages, ws.dbPath, p.WorkspaceID, p.Q, queryOptions(ws, p.QueryParams), p.Cursor)
+	if err != nil {
+		return model.ResultPage{}, err
+	}
+	if p.Show {
+		attachText(ws.root, page.Items)
+	}
+	return page, nil
+}
+
+func (h *Handlers) QueryGroups(p QueryParams) ([]model.FileGroup, error) {
+	if h == nil {
+		return nil, fmt.Errorf("handlers is nil")
+	}
+
+	ws, ok := h.getWorkspace(p.WorkspaceID)
+	if !ok {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	groups, err := query.QueryGroups(ws.dbPath, p.WorkspaceID, p.Q, queryOptions(ws, p))
+	if err != nil {
+		return nil, err
+	}
+	if p.Show {
+		for _, g := range groups {
+			attachText(ws.root, g.Items)
+		}
+	}
+	return groups, nil
+}
+
+func (h *Handlers) QueryCount(p QueryParams) (model.CountResult, error) {
+	if h == nil {
+		return model.CountResult{}, fmt.Errorf("handlers is nil")
+	}
+
+	ws, ok := h.getWorkspace(p.WorkspaceID)
+	if !ok {
+		return model.CountResult{}, fmt.Errorf("workspace not found")
+	}
+	return query.Count(ws.dbPath, p.WorkspaceID, p.Q, queryOptions(ws, p))
+}
+
+func (h *Handlers) QueryFiles(p QueryFilesParams) ([]string, error) {
+	if h == nil {
+		return nil, fmt.Errorf("handlers is nil")
+	}
+
+	ws, ok := h.getWorkspace(p.WorkspaceID)
+	if !ok {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	opts := queryOptions(ws, p.QueryParams)
+	if p.WithoutMatch {
+		return query.FilesWithoutMatch(ws.dbPath, p.WorkspaceID, p.Q, opts)
+	}
+	res, err := query.Count(ws.dbPath, p.WorkspaceID, p.Q, opts)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(res.Files))
+	for _, f := range res.Files {
+		paths = append(paths, f.Path)
+	}
+	return paths, nil
+}
+
+func (h *Handlers) SymbolSearch(p SymbolSearchParams) ([]model.SymbolItem, error) {
+	if h == nil {
+		return nil, fmt.Errorf("handlers is nil")
+	}
+
+	ws, ok := h.getWorkspace(p.WorkspaceID)
+	if !ok {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	return query.SearchSymbols(ws.dbPath, p.WorkspaceID, p.Q, query.SymbolOptions{
+		Store: ws.store,
+		Kind:  p.Kind,
+		Lang:  p.Lang,
+		Limit: p.Limit,
+	})
+}
+
+func (h *Handlers) FilesSearch(p FilesSearchParams) ([]model.FileItem, error) {
+	if h == nil {
+		return nil, fmt.Errorf("handlers is nil")
+	}
+
+	ws, ok := h.getWorkspace(p.WorkspaceID)
+	if !ok {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	return query.SearchFiles(ws.dbPath, p.WorkspaceID, p.Q, query.FilesOptions{
+		Store:        ws.store,
+		IncludeGlobs: p.IncludeGlobs,
+		ExcludeGlobs: p.ExcludeGlobs,
+		Limit:        p.Limit,
+	})
+}
+
+func (h *Handlers) QuerySemantic(p SemanticParams) ([]model.ResultItem, error) {
+	if h == nil {
+		return nil, fmt.Errorf("handlers is nil")
+	}
+
+	ws, ok := h.getWorkspace(p.WorkspaceID)
+	if !ok {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	return query.Semantic(ws.dbPath, p.WorkspaceID, p.Q, query.SemanticOptions{
+		Store:        ws.store,
+		IncludeGlobs: p.IncludeGlobs,
+		ExcludeGlobs: p.ExcludeGlobs,
+		Types:        p.Types,
+		TypesNot:     p.TypesNot,
+		Paths:        p.Paths,
+		Lang:         p.Lang,
+		Limit:        p.Limit,
+	})
+}
+
+func (h *Handlers) Refs(p RefsParams) ([]model.RefItem, error) {
+	if h == nil {
+		return nil, fmt.Errorf("handlers is nil")
+	}
+
+	ws, ok := h.getWorkspace(p.WorkspaceID)
+	if !ok {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	return query.FindRefs(ws.dbPath, p.WorkspaceID, p.Name, query.RefOptions{
+		Store: ws.store,
+		Kind:  p.Kind,
+		Lang:  p.Lang,
+		Limit: p.Limit,
+	})
+}
+
+func (h *Handlers) Outline(p OutlineParams) ([]model.OutlineNode, error) {
+	if h == nil {
+		return nil, fmt.Errorf("handlers is nil")
+	}
+
+	ws, ok := h.getWorkspace(p.WorkspaceID)
+	if !ok {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	return query.Outline(ws.dbPath, p.WorkspaceID, p.Path, query.OutlineOptions{Store: ws.store})
+}
+
 type watcherEntry struct {
 	w      *watch.Watcher
 	cancel context.CancelFunc
@@ -265,28 +466,27 @@ func (h *Handlers) WatchStart(p WatchStartParams) (WatchStatusResult, error) {
 
 	autoParams = applyAutoParams(p, autoParams)
 
+	opts := indexer.Options{
+		Store:        ws.store,
+		WorkspaceID:  wsid,
+		ScanAll:      p.ScanAll,
+		IncludeGlobs: p.IncludeGlobs,
+		ExcludeGlobs: p.ExcludeGlobs,
+		Types:        p.Types,
+		TypesNot:     p.TypesNot,
+		Chunking:     p.Chunking,
+	}
+
 	var uq *updateQueue
 	var du *directUpdater
 	updateFunc := func(paths []string) {
 		for _, rel := range paths {
-			_ = indexer.UpdateFile(rootAbs, ws.dbPath, rel, indexer.Options{
-				Store:        ws.store,
-				WorkspaceID:  wsid,
-				ScanAll:      p.ScanAll,
-				IncludeGlobs: p.IncludeGlobs,
-				ExcludeGlobs: p.ExcludeGlobs,
-			})
+			_ = indexer.UpdateFile(rootAbs, ws.dbPath, rel, opts)
 		}
 	}
 
 	if autoParams.QueueMode == "direct" {
-		du = newDirectUpdater(rootAbs, ws.store, ws.dbPath, indexer.Options{
-			Store:        ws.store,
-			WorkspaceID:  wsid,
-			ScanAll:      p.ScanAll,
-			IncludeGlobs: p.IncludeGlobs,
-			ExcludeGlobs: p.ExcludeGlobs,
-		})
+		du = newDirectUpdater(rootAbs, ws.store, ws.dbPath, opts)
 		updateFunc = func(paths []string) {
 			if du == nil {
 				return
@@ -296,13 +496,7 @@ func (h *Handlers) WatchStart(p WatchStartParams) (WatchStatusResult, error) {
 			}
 		}
 	} else {
-		uq = newUpdateQueue(rootAbs, ws.store, ws.dbPath, wsid, indexer.Options{
-			Store:        ws.store,
-			WorkspaceID:  wsid,
-			ScanAll:      p.ScanAll,
-			IncludeGlobs: p.IncludeGlobs,
-			ExcludeGlobs: p.ExcludeGlobs,
-		}, tuning, autoParams.QueueMode)
+		uq = newUpdateQueue(rootAbs, ws.store, ws.dbPath, wsid, opts, tuning, autoParams.QueueMode)
 		updateFunc = func(paths []string) {
 			if uq != nil {
 				uq.Enqueue(paths)
@@ -310,13 +504,7 @@ func (h *Handlers) WatchStart(p WatchStartParams) (WatchStatusResult, error) {
 		}
 	}
 
-	w, err := watch.NewWatcherWithOptions(ws.root, ws.dbPath, indexer.Options{
-		Store:        ws.store,
-		WorkspaceID:  wsid,
-		ScanAll:      p.ScanAll,
-		IncludeGlobs: p.IncludeGlobs,
-		ExcludeGlobs: p.ExcludeGlobs,
-	}, watch.Options{
+	w, err := watch.NewWatcherWithOptions(ws.root, ws.dbPath, opts, watch.Options{
 		Debounce:         debounceFromParams(autoParams.DebounceMS),
 		AdaptiveDebounce: autoParams.AdaptiveDebounce,
 		DebounceMin:      debounceFromParams(autoParams.DebounceMinMS),
@@ -339,13 +527,7 @@ func (h *Handlers) WatchStart(p WatchStartParams) (WatchStatusResult, error) {
 	h.mu.Unlock()
 
 	if autoParams.SyncOnStart {
-		if err := syncChangedFiles(ws.root, ws.dbPath, indexer.Options{
-			Store:        ws.store,
-			WorkspaceID:  wsid,
-			ScanAll:      p.ScanAll,
-			IncludeGlobs: p.IncludeGlobs,
-			ExcludeGlobs: p.ExcludeGlobs,
-		}, autoParams.SyncWorkers); err != nil {
+		if err := syncChangedFiles(ws.root, ws.dbPath, opts, autoParams.SyncWorkers); err != nil {
 			return WatchStatusResult{}, err
 		}
 	}
@@ -558,6 +740,8 @@ func syncChangedFiles(root string, dbPath string, opts indexer.Options, workers
 	files, err := walk.ListFiles(rootAbs, walk.Options{
 		IncludeGlobs: opts.IncludeGlobs,
 		ExcludeGlobs: opts.ExcludeGlobs,
+		Types:        opts.Types,
+		TypesNot:     opts.TypesNot,
 		ScanAll:      opts.ScanAll,
 	})
 	if err != nil {
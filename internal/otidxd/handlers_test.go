@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
 )
 
 func TestHandlers_MinLoop_WorkspaceBuildQuery(t *testing.T) {
@@ -48,3 +51,194 @@ func TestWorkspaceAdd_StoreBleve_DefaultPath(t *testing.T) {
 	}
 }
 
+
+func TestHandlers_SymbolSearch(t *testing.T) {
+	root := t.TempDir()
+	_ = os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n"), 0o644)
+
+	h := NewHandlers()
+	wsid, err := h.WorkspaceAdd(WorkspaceAddParams{Root: root})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := h.IndexBuild(IndexBuildParams{WorkspaceID: wsid}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	ws, _ := h.getWorkspace(wsid)
+	st, err := backend.Open(ws.store, ws.dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := st.ReplaceSymbolsBatch(wsid, "a.go", []store.SymbolInput{
+		{Kind: "function", Name: "NewRootCommand", SL: 3, EL: 5, Lang: "go"},
+	}); err != nil {
+		t.Fatalf("replace symbols: %v", err)
+	}
+	_ = st.Close()
+
+	syms, err := h.SymbolSearch(SymbolSearchParams{WorkspaceID: wsid, Q: "NRC"})
+	if err != nil {
+		t.Fatalf("symbol.search: %v", err)
+	}
+	if len(syms) != 1 || syms[0].Name != "NewRootCommand" || syms[0].Path != "a.go" {
+		t.Fatalf("bad result: %+v", syms)
+	}
+	if _, err := h.SymbolSearch(SymbolSearchParams{WorkspaceID: "missing", Q: "x"}); err == nil {
+		t.Fatalf("expected workspace not found")
+	}
+}
+
+func TestHandlers_Outline(t *testing.T) {
+	root := t.TempDir()
+	_ = os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n"), 0o644)
+
+	h := NewHandlers()
+	wsid, err := h.WorkspaceAdd(WorkspaceAddParams{Root: root})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := h.IndexBuild(IndexBuildParams{WorkspaceID: wsid}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	ws, _ := h.getWorkspace(wsid)
+	st, err := backend.Open(ws.store, ws.dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := st.ReplaceSymbolsBatch(wsid, "a.go", []store.SymbolInput{
+		{Kind: "type", Name: "T", SL: 3, EL: 5, Lang: "go"},
+		{Kind: "method", Name: "Run", Container: "*T", SL: 7, EL: 9, Lang: "go"},
+	}); err != nil {
+		t.Fatalf("replace symbols: %v", err)
+	}
+	_ = st.Close()
+
+	nodes, err := h.Outline(OutlineParams{WorkspaceID: wsid, Path: "a.go"})
+	if err != nil {
+		t.Fatalf("outline: %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].Name != "T" || len(nodes[0].Children) != 1 || nodes[0].Children[0].Name != "Run" {
+		t.Fatalf("bad result: %+v", nodes)
+	}
+	if _, err := h.Outline(OutlineParams{WorkspaceID: "missing", Path: "a.go"}); err == nil {
+		t.Fatalf("expected workspace not found")
+	}
+}
+
+func TestHandlers_Refs(t *testing.T) {
+	root := t.TempDir()
+	_ = os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n"), 0o644)
+
+	h := NewHandlers()
+	wsid, err := h.WorkspaceAdd(WorkspaceAddParams{Root: root})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := h.IndexBuild(IndexBuildParams{WorkspaceID: wsid}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	ws, _ := h.getWorkspace(wsid)
+	st, err := backend.Open(ws.store, ws.dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := st.ReplaceSymbolsBatch(wsid, "a.go", []store.SymbolInput{
+		{Kind: "function", Name: "main", SL: 3, EL: 5, Lang: "go"},
+	}); err != nil {
+		t.Fatalf("replace symbols: %v", err)
+	}
+	if err := st.ReplaceRefsBatch(wsid, "a.go", []store.RefInput{
+		{Kind: "call", Name: "Run", SL: 4, SC: 2, EL: 4, EC: 5, Lang: "go"},
+	}); err != nil {
+		t.Fatalf("replace refs: %v", err)
+	}
+	_ = st.Close()
+
+	refs, err := h.Refs(RefsParams{WorkspaceID: wsid, Name: "Run"})
+	if err != nil {
+		t.Fatalf("refs: %v", err)
+	}
+	if len(refs) != 1 || refs[0].Symbol == nil || refs[0].Symbol.Name != "main" {
+		t.Fatalf("bad result: %+v", refs)
+	}
+	if _, err := h.Refs(RefsParams{WorkspaceID: "missing", Name: "Run"}); err == nil {
+		t.Fatalf("expected workspace not found")
+	}
+}
+
+func TestHandlers_FilesSearch(t *testing.T) {
+	root := t.TempDir()
+	_ = os.WriteFile(filepath.Join(root, "alpha.go"), []byte("package a\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(root, "beta.go"), []byte("package a\n"), 0o644)
+
+	h := NewHandlers()
+	wsid, err := h.WorkspaceAdd(WorkspaceAddParams{Root: root})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := h.IndexBuild(IndexBuildParams{WorkspaceID: wsid}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	files, err := h.FilesSearch(FilesSearchParams{WorkspaceID: wsid, Q: "alp"})
+	if err != nil {
+		t.Fatalf("files: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "alpha.go" || files[0].Hash == "" {
+		t.Fatalf("bad result: %+v", files)
+	}
+	if _, err := h.FilesSearch(FilesSearchParams{WorkspaceID: "missing"}); err == nil {
+		t.Fatalf("expected workspace not found")
+	}
+}
+
+func TestHandlers_QuerySemantic(t *testing.T) {
+	root := t.TempDir()
+	_ = os.WriteFile(filepath.Join(root, "retry.go"), []byte("package a\n\nfunc retryWrite() error { return writeAgain() }\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(root, "parse.go"), []byte("package a\n\nfunc parseConfig() {}\n"), 0o644)
+
+	h := NewHandlers()
+	wsid, err := h.WorkspaceAdd(WorkspaceAddParams{Root: root})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := h.IndexBuild(IndexBuildParams{WorkspaceID: wsid, Embed: "nope"}); err == nil {
+		t.Fatalf("expected unknown embedder error")
+	}
+	if _, err := h.IndexBuild(IndexBuildParams{WorkspaceID: wsid, Embed: "hash"}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	items, err := h.QuerySemantic(SemanticParams{WorkspaceID: wsid, Q: "retry failed writes", Limit: 1})
+	if err != nil {
+		t.Fatalf("semantic: %v", err)
+	}
+	if len(items) != 1 || items[0].Path != "retry.go" {
+		t.Fatalf("bad result: %+v", items)
+	}
+	for _, p := range []SemanticParams{
+		{WorkspaceID: wsid, Q: "retry failed writes", Paths: []string{"parse.go"}},
+		{WorkspaceID: wsid, Q: "retry failed writes", Lang: "python"},
+		{WorkspaceID: wsid, Q: "retry failed writes", Types: []string{"py"}},
+		{WorkspaceID: wsid, Q: "retry failed writes", TypesNot: []string{"go"}},
+	} {
+		items, err := h.QuerySemantic(p)
+		if err != nil {
+			t.Fatalf("semantic %+v: %v", p, err)
+		}
+		for _, it := range items {
+			if it.Path == "retry.go" {
+				t.Fatalf("filter %+v kept retry.go: %+v", p, items)
+			}
+		}
+	}
+	if _, err := h.QuerySemantic(SemanticParams{WorkspaceID: wsid, Q: "x", Lang: "nope"}); err == nil {
+		t.Fatalf("expected unknown language error")
+	}
+	if _, err := h.QuerySemantic(SemanticParams{WorkspaceID: "missing", Q: "x"}); err == nil {
+		t.Fatalf("expected workspace not found")
+	}
+}
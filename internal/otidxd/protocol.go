@@ -32,6 +32,15 @@ type IndexBuildParams struct {
 	ScanAll      bool     `json:"scan_all,omitempty"`
 	IncludeGlobs []string `json:"include_globs,omitempty"`
 	ExcludeGlobs []string `json:"exclude_globs,omitempty"`
+	Types        []string `json:"types,omitempty"`
+	TypesNot     []string `json:"types_not,omitempty"`
+	// Embed names the embedder to store vectors with ("hash"); empty keeps
+	// the index's current embeddings, if any.
+	Embed string `json:"embed,omitempty"`
+	// Full rewrites every file instead of only the changed ones.
+	Full bool `json:"full,omitempty"`
+	// Chunking is lines, symbols or auto (default), as in indexer.Options.
+	Chunking string `json:"chunking,omitempty"`
 }
 
 type QueryParams struct {
@@ -42,9 +51,77 @@ type QueryParams struct {
 	Offset          int      `json:"offset,omitempty"`
 	ContextLines    int      `json:"context_lines,omitempty"`
 	CaseInsensitive bool     `json:"case_insensitive,omitempty"`
+	Regex           bool     `json:"regex,omitempty"`
+	Sort            string   `json:"sort,omitempty"`
+	In              string   `json:"in,omitempty"`
+	Boundary        string   `json:"boundary,omitempty"`
+	AllTerms        bool     `json:"all_terms,omitempty"`
 	IncludeGlobs    []string `json:"include_globs,omitempty"`
 	ExcludeGlobs    []string `json:"exclude_globs,omitempty"`
+	Types           []string `json:"types,omitempty"`
+	TypesNot        []string `json:"types_not,omitempty"`
+	Paths           []string `json:"paths,omitempty"`
+	Lang            string   `json:"lang,omitempty"`
 	Show            bool     `json:"show,omitempty"`
+	// Mode and Weights pick and tune result fusion (query.Options).
+	Mode    string             `json:"mode,omitempty"`
+	Weights map[string]float64 `json:"weights,omitempty"`
+	// MaxPerFile caps the hits per file (default 3); 0 means no cap.
+	MaxPerFile *int `json:"max_per_file,omitempty"`
+}
+
+// QueryPageParams are the query params plus the cursor of the previous page.
+type QueryPageParams struct {
+	QueryParams
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// QueryFilesParams are the query params plus which files to list: with a
+// match (default) or, with WithoutMatch, indexed files without one.
+type QueryFilesParams struct {
+	QueryParams
+	WithoutMatch bool `json:"without_match,omitempty"`
+}
+
+type SymbolSearchParams struct {
+	WorkspaceID string `json:"workspace_id"`
+	Q           string `json:"q"`
+	Kind        string `json:"kind,omitempty"`
+	Lang        string `json:"lang,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+}
+
+type FilesSearchParams struct {
+	WorkspaceID  string   `json:"workspace_id"`
+	Q            string   `json:"q,omitempty"`
+	IncludeGlobs []string `json:"include_globs,omitempty"`
+	ExcludeGlobs []string `json:"exclude_globs,omitempty"`
+	Limit        int      `json:"limit,omitempty"`
+}
+
+type SemanticParams struct {
+	WorkspaceID  string   `json:"workspace_id"`
+	Q            string   `json:"q"`
+	IncludeGlobs []string `json:"include_globs,omitempty"`
+	ExcludeGlobs []string `json:"exclude_globs,omitempty"`
+	Types        []string `json:"types,omitempty"`
+	TypesNot     []string `json:"types_not,omitempty"`
+	Paths        []string `json:"paths,omitempty"`
+	Lang         string   `json:"lang,omitempty"`
+	Limit        int      `json:"limit,omitempty"`
+}
+
+type RefsParams struct {
+	WorkspaceID string `json:"workspace_id"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind,omitempty"`
+	Lang        string `json:"lang,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+}
+
+type OutlineParams struct {
+	WorkspaceID string `json:"workspace_id"`
+	Path        string `json:"path"`
 }
 
 type WatchStartParams struct {
@@ -52,6 +129,9 @@ type WatchStartParams struct {
 	ScanAll          bool     `json:"scan_all,omitempty"`
 	IncludeGlobs     []string `json:"include_globs,omitempty"`
 	ExcludeGlobs     []string `json:"exclude_globs,omitempty"`
+	Types            []string `json:"types,omitempty"`
+	TypesNot         []string `json:"types_not,omitempty"`
+	Chunking         string   `json:"chunking,omitempty"`
 	SyncOnStart      bool     `json:"sync_on_start,omitempty"`
 	DebounceMS       int      `json:"debounce_ms,omitempty"`
 	SyncWorkers      int      `json:"sync_workers,omitempty"`
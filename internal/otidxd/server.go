@@ -214,6 +214,200 @@ func (s *Server) dispatch(req Request) Response {
 			return resp
 		}
 		resp.Result = items
+	case "query.page":
+		var p QueryPageParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				resp.Error = &ErrorObject{Code: -32602, Message: "invalid params"}
+				return resp
+			}
+		}
+		if strings.TrimSpace(p.WorkspaceID) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "workspace_id is required"}
+			return resp
+		}
+		if strings.TrimSpace(p.Q) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "q is required"}
+			return resp
+		}
+		page, err := s.h.QueryPage(p)
+		if err != nil {
+			resp.Error = &ErrorObject{Code: -32000, Message: err.Error()}
+			return resp
+		}
+		resp.Result = page
+	case "query.groups":
+		var p QueryParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				resp.Error = &ErrorObject{Code: -32602, Message: "invalid params"}
+				return resp
+			}
+		}
+		if strings.TrimSpace(p.WorkspaceID) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "workspace_id is required"}
+			return resp
+		}
+		if strings.TrimSpace(p.Q) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "q is required"}
+			return resp
+		}
+		groups, err := s.h.QueryGroups(p)
+		if err != nil {
+			resp.Error = &ErrorObject{Code: -32000, Message: err.Error()}
+			return resp
+		}
+		resp.Result = groups
+	case "query.count":
+		var p QueryParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				resp.Error = &ErrorObject{Code: -32602, Message: "invalid params"}
+				return resp
+			}
+		}
+		if strings.TrimSpace(p.WorkspaceID) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "workspace_id is required"}
+			return resp
+		}
+		if strings.TrimSpace(p.Q) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "q is required"}
+			return resp
+		}
+		res, err := s.h.QueryCount(p)
+		if err != nil {
+			resp.Error = &ErrorObject{Code: -32000, Message: err.Error()}
+			return resp
+		}
+		resp.Result = res
+	case "query.files":
+		var p QueryFilesParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				resp.Error = &ErrorObject{Code: -32602, Message: "invalid params"}
+				return resp
+			}
+		}
+		if strings.TrimSpace(p.WorkspaceID) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "workspace_id is required"}
+			return resp
+		}
+		if strings.TrimSpace(p.Q) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "q is required"}
+			return resp
+		}
+		paths, err := s.h.QueryFiles(p)
+		if err != nil {
+			resp.Error = &ErrorObject{Code: -32000, Message: err.Error()}
+			return resp
+		}
+		resp.Result = paths
+	case "symbol.search":
+		var p SymbolSearchParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				resp.Error = &ErrorObject{Code: -32602, Message: "invalid params"}
+				return resp
+			}
+		}
+		if strings.TrimSpace(p.WorkspaceID) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "workspace_id is required"}
+			return resp
+		}
+		if strings.TrimSpace(p.Q) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "q is required"}
+			return resp
+		}
+		syms, err := s.h.SymbolSearch(p)
+		if err != nil {
+			resp.Error = &ErrorObject{Code: -32000, Message: err.Error()}
+			return resp
+		}
+		resp.Result = syms
+	case "query.semantic":
+		var p SemanticParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				resp.Error = &ErrorObject{Code: -32602, Message: "invalid params"}
+				return resp
+			}
+		}
+		if strings.TrimSpace(p.WorkspaceID) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "workspace_id is required"}
+			return resp
+		}
+		if strings.TrimSpace(p.Q) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "q is required"}
+			return resp
+		}
+		items, err := s.h.QuerySemantic(p)
+		if err != nil {
+			resp.Error = &ErrorObject{Code: -32000, Message: err.Error()}
+			return resp
+		}
+		resp.Result = items
+	case "files.search":
+		var p FilesSearchParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				resp.Error = &ErrorObject{Code: -32602, Message: "invalid params"}
+				return resp
+			}
+		}
+		if strings.TrimSpace(p.WorkspaceID) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "workspace_id is required"}
+			return resp
+		}
+		files, err := s.h.FilesSearch(p)
+		if err != nil {
+			resp.Error = &ErrorObject{Code: -32000, Message: err.Error()}
+			return resp
+		}
+		resp.Result = files
+	case "refs":
+		var p RefsParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				resp.Error = &ErrorObject{Code: -32602, Message: "invalid params"}
+				return resp
+			}
+		}
+		if strings.TrimSpace(p.WorkspaceID) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "workspace_id is required"}
+			return resp
+		}
+		if strings.TrimSpace(p.Name) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "name is required"}
+			return resp
+		}
+		refs, err := s.h.Refs(p)
+		if err != nil {
+			resp.Error = &ErrorObject{Code: -32000, Message: err.Error()}
+			return resp
+		}
+		resp.Result = refs
+	case "outline":
+		var p OutlineParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				resp.Error = &ErrorObject{Code: -32602, Message: "invalid params"}
+				return resp
+			}
+		}
+		if strings.TrimSpace(p.WorkspaceID) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "workspace_id is required"}
+			return resp
+		}
+		if strings.TrimSpace(p.Path) == "" {
+			resp.Error = &ErrorObject{Code: -32602, Message: "path is required"}
+			return resp
+		}
+		nodes, err := s.h.Outline(p)
+		if err != nil {
+			resp.Error = &ErrorObject{Code: -32000, Message: err.Error()}
+			return resp
+		}
+		resp.Result = nodes
 	case "watch.start":
 		var p WatchStartParams
 		if len(req.Params) > 0 {
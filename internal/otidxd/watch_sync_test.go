@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"otterindex/internal/index/backend"
+	"otterindex/internal/index/store"
 )
 
 func TestWatch_SyncOnStartUpdatesIndex(t *testing.T) {
@@ -35,7 +38,7 @@ func TestWatch_SyncOnStartUpdatesIndex(t *testing.T) {
 	_ = os.WriteFile(path, []byte("hello\n"+needle+"\n"), 0o644)
 
 	var st WatchStatusResult
-	if err := c.call("watch.start", WatchStartParams{WorkspaceID: wsid, SyncOnStart: true, SyncWorkers: 1, DebounceMS: 50}, &st); err != nil {
+	if err := c.call("watch.start", WatchStartParams{WorkspaceID: wsid, Chunking: "lines", SyncOnStart: true, SyncWorkers: 1, DebounceMS: 50}, &st); err != nil {
 		t.Fatalf("watch.start: %v", err)
 	}
 	if !st.Running {
@@ -52,3 +55,74 @@ func TestWatch_SyncOnStartUpdatesIndex(t *testing.T) {
 	}
 	t.Fatalf("timeout: did not observe synced index for %q", needle)
 }
+
+func TestWatch_SyncOnStartHonorsTypes(t *testing.T) {
+	root := t.TempDir()
+	_ = os.WriteFile(filepath.Join(root, "a.go"), []byte("hello\n"), 0o644)
+
+	h := NewHandlers()
+	t.Cleanup(func() { _ = h.Close() })
+	wsid, err := h.WorkspaceAdd(WorkspaceAddParams{Root: root})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := h.IndexBuild(IndexBuildParams{WorkspaceID: wsid, Types: []string{"go"}}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	needle := "TYPES_TOKEN_123"
+	_ = os.WriteFile(filepath.Join(root, "a.go"), []byte("hello\n"+needle+"\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(root, "b.py"), []byte(needle+"\n"), 0o644)
+
+	if _, err := h.WatchStart(WatchStartParams{WorkspaceID: wsid, Types: []string{"go"}, SyncOnStart: true, SyncWorkers: 1}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	items, err := h.Query(QueryParams{WorkspaceID: wsid, Q: needle, Unit: "block", Limit: 10})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(items) != 1 || items[0].Path != "a.go" {
+		t.Fatalf("expected only a.go, got=%+v", items)
+	}
+}
+
+func TestWatch_SyncOnStartHonorsChunking(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "a.go")
+	_ = os.WriteFile(path, []byte("package a\n\nfunc Hello() {}\n"), 0o644)
+
+	h := NewHandlers()
+	t.Cleanup(func() { _ = h.Close() })
+	wsid, err := h.WorkspaceAdd(WorkspaceAddParams{Root: root})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := h.IndexBuild(IndexBuildParams{WorkspaceID: wsid, Chunking: "lines"}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	needle := "CHUNKING_TOKEN_123"
+	_ = os.WriteFile(path, []byte("package a\n\nfunc Hello() {\n\t_ = \""+needle+"\"\n}\n"), 0o644)
+
+	if _, err := h.WatchStart(WatchStartParams{WorkspaceID: wsid, Chunking: "lines", SyncOnStart: true, SyncWorkers: 1}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := h.WatchStop(WatchStopParams{WorkspaceID: wsid}); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	ws, _ := h.getWorkspace(wsid)
+	s, err := backend.Open(ws.store, ws.dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+	res, err := s.SearchChunks(wsid, needle, store.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	// Symbol chunking would have cut Hello into a "function" chunk.
+	if len(res.Chunks) != 1 || res.Chunks[0].Kind != "chunk" {
+		t.Fatalf("expected a line-window chunk, got=%+v", res.Chunks)
+	}
+}